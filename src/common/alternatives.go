@@ -0,0 +1,59 @@
+package common
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// AlternativesDir holds the go and gofmt links managed by update-alternatives.
+// golang-go packages own /usr/bin/go, /usr/local/bin comes first on PATH
+// and leaves them alone.
+const AlternativesDir = BinLinkDir
+
+// HasAlternatives reports whether the host manages links with Debian's
+// update-alternatives.
+func HasAlternatives() bool {
+	_, err := exec.LookPath("update-alternatives")
+	return err == nil && GetOS() == "linux"
+}
+
+// alternativesPriority ranks toolchains by version, so in automatic mode
+// the newest registered one wins: go1.22.1 gets 122.
+func alternativesPriority(version string) int {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return 1
+	}
+	return v.Major*100 + v.Minor
+}
+
+// RegisterAlternatives registers the go command of GoRoot, with gofmt as
+// its slave, and records it so uninstall removes it again.
+func RegisterAlternatives(version string) error {
+	link := filepath.Join(AlternativesDir, "go")
+	target := filepath.Join(GoRoot, "bin", "go")
+	out, err := Command("update-alternatives", "--install", link, "go", target, fmt.Sprint(alternativesPriority(version)),
+		"--slave", filepath.Join(AlternativesDir, "gofmt"), "gofmt", filepath.Join(GoRoot, "bin", "gofmt")).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("update-alternatives: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+	s.AddFile(link, KindAlternatives, "created")
+	s.Files[len(s.Files)-1].Added = []string{target}
+	return s.Save()
+}
+
+// RemoveAlternatives unregisters target, the go command RegisterAlternatives
+// registered.
+func RemoveAlternatives(target string) error {
+	out, err := Command("update-alternatives", "--remove", "go", target).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("update-alternatives: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package common
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DownloadFile saves the release archive name from go.dev to dst.
+func DownloadFile(ctx context.Context, name, dst string, progress io.Writer) error {
+	return DownloadURL(ctx, DownloadBase+name, dst, progress)
+}
+
+// DownloadURL saves url to dst. A failed or cancelled download removes the
+// partial file.
+func DownloadURL(ctx context.Context, url, dst string, progress io.Writer) (err error) {
+	Log.Info("download", "url", url, "file", dst)
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return DiagnoseNetwork(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DiagnoseNetwork(url, &StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	var w io.Writer = out
+	if progress != nil {
+		w = io.MultiWriter(out, progress)
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// VerifyChecksum checks the SHA-256 of the file name against want.
+func VerifyChecksum(name, want string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return VerifyReader(f, want)
+}
+
+// VerifyReader checks the SHA-256 of what r yields against want.
+func VerifyReader(r io.Reader, want string) error {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return err
+	}
+
+	got := hex.EncodeToString(h.Sum(nil))
+	if got != want {
+		return fmt.Errorf("%w: want=%s got=%s", ErrChecksumMismatch, want, got)
+	}
+
+	return nil
+}
+
+// safeJoin joins an archive entry name to dst and rejects names that would
+// land outside of it, such as absolute paths or ../ components.
+func safeJoin(dst, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("tar entry %s: absolute path", name)
+	}
+	target := filepath.Join(dst, name)
+	rel, err := filepath.Rel(dst, target)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("tar entry %s: path escapes destination", name)
+	}
+	return target, nil
+}
+
+// dirUnder checks that every component of dir below root is a real
+// directory, creating missing ones when create is set. An entry must never
+// be written through a symlink the archive extracted earlier, that would
+// put it outside root whatever its name says.
+func dirUnder(root, dir string, create bool) error {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil || rel == "." {
+		return err
+	}
+	p := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		p = filepath.Join(p, part)
+		fi, err := os.Lstat(p)
+		switch {
+		case os.IsNotExist(err) && create:
+			if err := os.Mkdir(p, 0755); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case !fi.IsDir():
+			return fmt.Errorf("%s is not a directory", p)
+		}
+	}
+	return nil
+}
+
+// ExtractTarGz extracts the archive src into dst. The uncompressed tar
+// stream is copied to progress, if not nil, as it is read; UncompressedSize
+// is its total.
+func ExtractTarGz(ctx context.Context, src, dst string, progress io.Writer) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return extractTarGz(ctx, f, dst, progress)
+}
+
+// UncompressedSize returns the size of the data in the gzip file path, as
+// recorded in its trailer modulo 4 GiB, which Go archives stay well below.
+func UncompressedSize(path string) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var trailer [4]byte
+	fi, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if _, err := f.ReadAt(trailer[:], fi.Size()-4); err != nil {
+		return 0, err
+	}
+	return int64(binary.LittleEndian.Uint32(trailer[:])), nil
+}
+
+func ExtractTarGzReader(ctx context.Context, r io.Reader, dst string) error {
+	return extractTarGz(ctx, r, dst, nil)
+}
+
+func extractTarGz(ctx context.Context, r io.Reader, dst string, progress io.Writer) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	var tr io.Reader = gz
+	if progress != nil {
+		tr = io.TeeReader(gz, progress)
+	}
+	t := tar.NewReader(tr)
+
+	// Directory times are restored last, creating their entries would
+	// bump them again.
+	var dirs []*tar.Header
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		h, err := t.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(dst, h.Name)
+		if err != nil {
+			return err
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := dirUnder(dst, target, true); err != nil {
+				return fmt.Errorf("tar entry %s: %w", h.Name, err)
+			}
+			dirs = append(dirs, h)
+		case tar.TypeReg:
+			if err := dirUnder(dst, filepath.Dir(target), true); err != nil {
+				return fmt.Errorf("tar entry %s: %w", h.Name, err)
+			}
+			// Remove whatever is there and refuse to follow a symlink, so a
+			// crafted archive cannot write outside dst through one.
+			if err := os.Remove(target); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			Log.Log(context.Background(), LevelTrace, "extract", "file", target, "size", h.Size)
+			w, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(h.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(w, t); err != nil {
+				w.Close()
+				return err
+			}
+			if err := w.Close(); err != nil {
+				return err
+			}
+			if err := applyHeader(target, h); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if filepath.IsAbs(h.Linkname) {
+				return fmt.Errorf("tar entry %s: absolute symlink target %s", h.Name, h.Linkname)
+			}
+			if _, err := safeJoin(dst, filepath.Join(filepath.Dir(h.Name), h.Linkname)); err != nil {
+				return fmt.Errorf("tar entry %s: symlink points outside the archive", h.Name)
+			}
+			if err := dirUnder(dst, filepath.Dir(target), true); err != nil {
+				return fmt.Errorf("tar entry %s: %w", h.Name, err)
+			}
+			if err := os.Symlink(h.Linkname, target); err != nil {
+				return err
+			}
+			if err := chownOwner(target, os.Lchown); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			source, err := safeJoin(dst, h.Linkname)
+			if err != nil {
+				return fmt.Errorf("tar entry %s: hardlink points outside the archive", h.Name)
+			}
+			if err := dirUnder(dst, filepath.Dir(source), false); err != nil {
+				return fmt.Errorf("tar entry %s: %w", h.Name, err)
+			}
+			if err := dirUnder(dst, filepath.Dir(target), true); err != nil {
+				return fmt.Errorf("tar entry %s: %w", h.Name, err)
+			}
+			if err := os.Link(source, target); err != nil {
+				return err
+			}
+		case tar.TypeXGlobalHeader:
+			// PAX metadata only, nothing to extract.
+		default:
+			return fmt.Errorf("tar entry %s: unsupported type %q", h.Name, h.Typeflag)
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		target, _ := safeJoin(dst, dirs[i].Name)
+		if err := applyHeader(target, dirs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyHeader sets the permission bits and modification time recorded in
+// the archive, which would otherwise depend on the umask and the time of
+// extraction, and hands the file to its owner.
+func applyHeader(target string, h *tar.Header) error {
+	if err := os.Chmod(target, h.FileInfo().Mode().Perm()); err != nil {
+		return err
+	}
+	if err := chownOwner(target, os.Chown); err != nil {
+		return err
+	}
+	atime := h.AccessTime
+	if atime.IsZero() {
+		atime = h.ModTime
+	}
+	return os.Chtimes(target, atime, h.ModTime)
+}
+
+// chownOwner makes target owned by root:root when running as root, so an
+// install under sudo does not leave files owned by the invoking user. A
+// user install hands the files to that user instead.
+func chownOwner(target string, chown func(string, int, int) error) error {
+	if os.Geteuid() != 0 {
+		return nil
+	}
+	if installOwner != nil {
+		return chown(target, installOwner.UID, installOwner.GID)
+	}
+	return chown(target, 0, 0)
+}
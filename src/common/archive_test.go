@@ -0,0 +1,154 @@
+package common
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry is a member of a test archive.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	linkname string
+	body     string
+}
+
+func buildTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		h := &tar.Header{Name: e.name, Typeflag: e.typeflag, Linkname: e.linkname, Mode: 0755}
+		if e.typeflag == tar.TypeReg {
+			h.Mode, h.Size = 0644, int64(len(e.body))
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSafeJoin(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"go/bin/go", false},
+		{"go/../go/bin", false},
+		{"./go", false},
+		{"/etc/passwd", true},
+		{"..", true},
+		{"../x", true},
+		{"go/../../x", true},
+	}
+	for _, tt := range tests {
+		_, err := safeJoin("/dst", tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("safeJoin(%q) error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestExtractTarGz(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr bool
+		// want lists files that must exist below dst afterwards.
+		want []string
+	}{
+		{
+			name: "regular tree",
+			entries: []tarEntry{
+				{name: "go/", typeflag: tar.TypeDir},
+				{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary"},
+				{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1"},
+				{name: "go/link", typeflag: tar.TypeSymlink, linkname: "VERSION"},
+				{name: "go/hard", typeflag: tar.TypeLink, linkname: "go/VERSION"},
+			},
+			want: []string{"go/bin/go", "go/VERSION", "go/link", "go/hard"},
+		},
+		{
+			name:    "path escapes",
+			entries: []tarEntry{{name: "../ESCAPED", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute symlink",
+			entries: []tarEntry{{name: "go/x", typeflag: tar.TypeSymlink, linkname: "/etc"}},
+			wantErr: true,
+		},
+		{
+			name:    "symlink escapes",
+			entries: []tarEntry{{name: "go/x", typeflag: tar.TypeSymlink, linkname: "../.."}},
+			wantErr: true,
+		},
+		{
+			name: "file through chained symlinks",
+			entries: []tarEntry{
+				{name: "go/", typeflag: tar.TypeDir},
+				{name: "go/x", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "go/x/y", typeflag: tar.TypeSymlink, linkname: ".."},
+				{name: "go/x/y/ESCAPED", typeflag: tar.TypeReg, body: "x"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "directory through symlink",
+			entries: []tarEntry{
+				{name: "go/x", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "go/x/d/", typeflag: tar.TypeDir},
+			},
+			wantErr: true,
+		},
+		{
+			name: "file replaces symlink",
+			entries: []tarEntry{
+				{name: "go/f", typeflag: tar.TypeSymlink, linkname: "g"},
+				{name: "go/f", typeflag: tar.TypeReg, body: "x"},
+			},
+			want: []string{"go/f"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dst := filepath.Join(parent, "a", "b", "dst")
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				t.Fatal(err)
+			}
+			err := ExtractTarGzReader(context.Background(), bytes.NewReader(buildTarGz(t, tt.entries)), dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			for _, f := range tt.want {
+				if _, err := os.Lstat(filepath.Join(dst, f)); err != nil {
+					t.Errorf("%s not extracted: %v", f, err)
+				}
+			}
+			// Nothing may land next to dst or above it.
+			filepath.WalkDir(parent, func(path string, d os.DirEntry, err error) error {
+				if err == nil && d.Name() == "ESCAPED" {
+					t.Errorf("entry written outside dst: %s", path)
+				}
+				return nil
+			})
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"cmp"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const backupTimeLayout = "20060102-150405"
+
+type Backup struct {
+	// Path is the directory containing the backed up "go" tree.
+	Path    string
+	Version string
+	Created time.Time
+	// seq orders backups made within the same second.
+	seq int
+}
+
+// GoRoot returns the backed up toolchain root.
+func (b Backup) GoRoot() string {
+	return filepath.Join(b.Path, "go")
+}
+
+// BackupGoRoot moves the active toolchain into a timestamped backup
+// directory, named <time>-<version>, with a _2, _3, ... suffix for further
+// backups within the same second. It returns an empty path when there is
+// nothing to back up.
+func BackupGoRoot() (string, error) {
+	if _, err := os.Stat(GoRoot); os.IsNotExist(err) {
+		return "", nil
+	}
+	version, err := InstalledVersion(GoRoot)
+	if err != nil {
+		version = "unknown"
+	}
+	if err := os.MkdirAll(BackupsDir, 0755); err != nil {
+		return "", err
+	}
+	base := filepath.Join(BackupsDir, time.Now().Format(backupTimeLayout)+"-"+version)
+	dir := base
+	for n := 2; ; n++ {
+		err := os.Mkdir(dir, 0755)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return "", err
+		}
+		dir = base + "_" + strconv.Itoa(n)
+	}
+	if err := os.Rename(GoRoot, filepath.Join(dir, "go")); err != nil {
+		os.Remove(dir)
+		return "", err
+	}
+	RecordFile(dir, KindBackup, "created")
+	return dir, nil
+}
+
+// ListBackups returns the available backups, newest first.
+func ListBackups() ([]Backup, error) {
+	entries, err := os.ReadDir(BackupsDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Backup
+	for _, e := range entries {
+		name := e.Name()
+		if !e.IsDir() || len(name) <= len(backupTimeLayout)+1 {
+			continue
+		}
+		created, err := time.ParseInLocation(backupTimeLayout, name[:len(backupTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+		version, seq := splitBackupVersion(strings.TrimPrefix(name[len(backupTimeLayout):], "-"))
+		backups = append(backups, Backup{
+			Path:    filepath.Join(BackupsDir, name),
+			Version: version,
+			Created: created,
+			seq:     seq,
+		})
+	}
+	slices.SortFunc(backups, func(a, b Backup) int {
+		return cmp.Or(b.Created.Compare(a.Created), cmp.Compare(b.seq, a.seq))
+	})
+	return backups, nil
+}
+
+// splitBackupVersion splits the _N suffix BackupGoRoot adds to backups
+// within the same second off the version part of a backup name.
+func splitBackupVersion(s string) (string, int) {
+	if i := strings.LastIndex(s, "_"); i >= 0 {
+		if n, err := strconv.Atoi(s[i+1:]); err == nil && n > 1 {
+			return s[:i], n
+		}
+	}
+	return s, 1
+}
+
+// PruneBackups removes all but the newest keep backups.
+func PruneBackups(keep int) error {
+	backups, err := ListBackups()
+	if err != nil {
+		return err
+	}
+	for i, b := range backups {
+		if i < keep {
+			continue
+		}
+		if err := os.RemoveAll(b.Path); err != nil {
+			return err
+		}
+		ForgetFile(b.Path)
+	}
+	return nil
+}
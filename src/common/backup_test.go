@@ -0,0 +1,71 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupGoRootSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	prevRoot, prevBackups, prevState := GoRoot, BackupsDir, StatePath
+	defer func() { GoRoot, BackupsDir, StatePath = prevRoot, prevBackups, prevState }()
+	GoRoot = filepath.Join(dir, "go")
+	BackupsDir = filepath.Join(dir, "backups")
+	StatePath = filepath.Join(dir, "state.json")
+
+	var paths []string
+	for _, version := range []string{"go1.21.0", "go1.21.0", "go1.22.0"} {
+		if err := os.MkdirAll(GoRoot, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(GoRoot, "VERSION"), []byte(version+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		path, err := BackupGoRoot()
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(backups) != len(paths) {
+		t.Fatalf("ListBackups returned %d backups, want %d", len(backups), len(paths))
+	}
+	// The loop may cross a second boundary, so only the order of backups
+	// within one second is certain: the newest comes first.
+	for i, b := range backups {
+		if _, err := os.Stat(filepath.Join(b.GoRoot(), "VERSION")); err != nil {
+			t.Errorf("backup %s: %v", b.Path, err)
+		}
+		if b.Version != "go1.21.0" && b.Version != "go1.22.0" {
+			t.Errorf("backup %s: version %q", b.Path, b.Version)
+		}
+		if i > 0 && b.Created.Equal(backups[i-1].Created) && b.seq > backups[i-1].seq {
+			t.Errorf("backup %s listed after the older %s", b.Path, backups[i-1].Path)
+		}
+	}
+}
+
+func TestSplitBackupVersion(t *testing.T) {
+	tests := []struct {
+		in, version string
+		seq         int
+	}{
+		{"go1.22.1", "go1.22.1", 1},
+		{"go1.22.1_2", "go1.22.1", 2},
+		{"go1.22.1_12", "go1.22.1", 12},
+		{"unknown", "unknown", 1},
+		{"devel_go1.24", "devel_go1.24", 1},
+	}
+	for _, tt := range tests {
+		version, seq := splitBackupVersion(tt.in)
+		if version != tt.version || seq != tt.seq {
+			t.Errorf("splitBackupVersion(%q) = %q, %d; want %q, %d", tt.in, version, seq, tt.version, tt.seq)
+		}
+	}
+}
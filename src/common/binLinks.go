@@ -0,0 +1,73 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// BinLinkDir is where LinkBinaries puts the go commands, on PATH for cron
+// jobs, non-login shells and every user alike.
+const BinLinkDir = "/usr/local/bin"
+
+// linkedBinaries are the commands of GoRoot/bin linked into BinLinkDir.
+var linkedBinaries = []string{"go", "gofmt"}
+
+// LinkBinaries points BinLinkDir/go and gofmt at GoRoot, replacing earlier
+// symlinks. Regular files and update-alternatives links are left alone. It
+// returns the links it created or updated.
+func LinkBinaries() ([]string, error) {
+	for _, name := range linkedBinaries {
+		link := filepath.Join(BinLinkDir, name)
+		fi, err := os.Lstat(link)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			return nil, fmt.Errorf("%s exists and is not a symlink, remove it to link Go there", link)
+		}
+		if target, _ := os.Readlink(link); strings.HasPrefix(target, "/etc/alternatives/") {
+			return nil, fmt.Errorf("%s is managed by update-alternatives, use --alternatives instead", link)
+		}
+	}
+	if err := os.MkdirAll(BinLinkDir, 0755); err != nil {
+		return nil, err
+	}
+	var links []string
+	for _, name := range linkedBinaries {
+		link := filepath.Join(BinLinkDir, name)
+		// Rename over the old link so it never disappears for a running
+		// job.
+		tmp := link + ".go-install"
+		os.Remove(tmp)
+		if err := os.Symlink(filepath.Join(GoRoot, "bin", name), tmp); err != nil {
+			return links, err
+		}
+		if err := os.Rename(tmp, link); err != nil {
+			os.Remove(tmp)
+			return links, err
+		}
+		Log.Info("write", "symlink", link, "target", filepath.Join(GoRoot, "bin", name))
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// UnlinkBinaries removes links that still point into GoRoot.
+func UnlinkBinaries(links []string) error {
+	for _, link := range links {
+		target, err := os.Readlink(link)
+		if err != nil || !strings.HasPrefix(target, GoRoot+string(filepath.Separator)) {
+			continue
+		}
+		if err := os.Remove(link); err != nil {
+			return err
+		}
+		Log.Info("remove", "symlink", link)
+	}
+	return nil
+}
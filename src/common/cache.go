@@ -0,0 +1,122 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCacheMaxSize bounds the archive cache after each install.
+const DefaultCacheMaxSize int64 = 1 << 30
+
+// ArchiveCacheDir holds downloaded release archives for reuse.
+func ArchiveCacheDir() string {
+	return filepath.Join(CacheDir(), "archives")
+}
+
+// CachedArchivePath returns where an archive with the given checksum is
+// cached. The checksum is part of the name so a re-published file never
+// matches a stale copy.
+func CachedArchivePath(filename, sha256 string) string {
+	key := sha256
+	if len(key) > 16 {
+		key = key[:16]
+	}
+	return filepath.Join(ArchiveCacheDir(), key+"-"+filename)
+}
+
+type CachedArchive struct {
+	Path    string
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// ListCachedArchives returns the cached archives, most recently used first.
+func ListCachedArchives() ([]CachedArchive, error) {
+	entries, err := os.ReadDir(ArchiveCacheDir())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var archives []CachedArchive
+	for _, e := range entries {
+		if e.IsDir() || strings.HasSuffix(e.Name(), ".part") {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		_, name, _ := strings.Cut(e.Name(), "-")
+		archives = append(archives, CachedArchive{
+			Path:    filepath.Join(ArchiveCacheDir(), e.Name()),
+			Name:    name,
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		})
+	}
+	slices.SortFunc(archives, func(a, b CachedArchive) int { return b.ModTime.Compare(a.ModTime) })
+	return archives, nil
+}
+
+// GCArchiveCache removes the least recently used archives until the cache
+// fits in maxBytes, and returns what it removed.
+func GCArchiveCache(maxBytes int64) ([]CachedArchive, error) {
+	archives, err := ListCachedArchives()
+	if err != nil {
+		return nil, err
+	}
+	var total int64
+	var removed []CachedArchive
+	for _, a := range archives {
+		total += a.Size
+		if total <= maxBytes {
+			continue
+		}
+		if err := os.Remove(a.Path); err != nil {
+			return removed, err
+		}
+		removed = append(removed, a)
+	}
+	return removed, nil
+}
+
+// ParseSize parses sizes such as "500MB", "1G" or "1048576".
+func ParseSize(s string) (int64, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"G", 1 << 30}, {"MB", 1 << 20}, {"M", 1 << 20}, {"KB", 1 << 10}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(s, u.suffix) {
+			s, mult = strings.TrimSuffix(s, u.suffix), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// FormatSize renders a byte count for humans.
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
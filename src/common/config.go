@@ -0,0 +1,288 @@
+package common
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// SystemConfigPath holds host-wide defaults, overridden by the user's
+// config file.
+const SystemConfigPath = "/etc/go-install/config.toml"
+
+// UserConfigPath returns the per-user config file, honoring
+// XDG_CONFIG_HOME.
+func UserConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "go-install", "config.toml")
+}
+
+// Origins of a setting other than config files, lowest priority first.
+const (
+	OriginDefault = "default"
+	OriginFlag    = "flag"
+)
+
+// Setting is the effective value of a setting and where it came from.
+type Setting struct {
+	Key    string
+	Value  string
+	Origin string
+}
+
+// Config merges settings from, in increasing priority: flag defaults, the
+// system config file, the user config file, project files, GO_INSTALL_*
+// environment variables and command line flags. Setting keys are the
+// names of the top-level flags.
+type Config struct {
+	settings map[string]Setting
+	// profiles are the [env-profiles.NAME] tables of go env settings.
+	profiles map[string]map[string]string
+	// theme are the colors of the [theme] table.
+	theme map[string]ThemeColor
+	// Problems lists unknown keys and invalid values with their origin.
+	Problems []string
+}
+
+// EnvName returns the environment variable that sets key, e.g.
+// GO_INSTALL_KEEP_BACKUPS for keep-backups.
+func EnvName(key string) string {
+	return "GO_INSTALL_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// LoadConfig resolves every flag of fs, which must already be parsed, and
+// sets the flags not given on the command line to their configured value.
+// Flags in ignore, such as --help or short aliases, are not settings.
+func LoadConfig(fs *flag.FlagSet, ignore ...string) *Config {
+	c := &Config{settings: map[string]Setting{}, profiles: map[string]map[string]string{}, theme: map[string]ThemeColor{}}
+	fs.VisitAll(func(f *flag.Flag) {
+		if !slices.Contains(ignore, f.Name) {
+			c.settings[f.Name] = Setting{Key: f.Name, Value: f.DefValue, Origin: OriginDefault}
+		}
+	})
+
+	for _, path := range []string{SystemConfigPath, UserConfigPath()} {
+		if path != "" {
+			c.loadFile(path)
+		}
+	}
+	if version, origin := projectVersion(); version != "" {
+		c.set("version", version, origin)
+	}
+	for key := range c.settings {
+		if v, ok := os.LookupEnv(EnvName(key)); ok {
+			c.set(key, v, "env "+EnvName(key))
+		}
+	}
+
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	if explicit["y"] {
+		explicit["yes"] = true
+	}
+	for key, s := range c.settings {
+		if explicit[key] {
+			c.settings[key] = Setting{Key: key, Value: fs.Lookup(key).Value.String(), Origin: OriginFlag}
+			continue
+		}
+		if s.Origin == OriginDefault {
+			continue
+		}
+		if err := fs.Set(key, s.Value); err != nil {
+			c.Problems = append(c.Problems, fmt.Sprintf("%s: invalid value %q for %s: %v", s.Origin, s.Value, key, err))
+			c.settings[key] = Setting{Key: key, Value: fs.Lookup(key).DefValue, Origin: OriginDefault}
+		}
+	}
+	return c
+}
+
+func (c *Config) set(key, value, origin string) {
+	if _, ok := c.settings[key]; !ok {
+		c.Problems = append(c.Problems, fmt.Sprintf("%s: unknown setting %q", origin, key))
+		return
+	}
+	c.settings[key] = Setting{Key: key, Value: value, Origin: origin}
+}
+
+func (c *Config) loadFile(path string) {
+	var values map[string]any
+	if _, err := toml.DecodeFile(path, &values); err != nil {
+		if !os.IsNotExist(err) {
+			c.Problems = append(c.Problems, fmt.Sprintf("%s: %v", path, err))
+		}
+		return
+	}
+	for key, v := range values {
+		if key == envProfilesKey {
+			c.loadProfiles(path, v)
+			continue
+		}
+		if key == themeKey {
+			c.loadTheme(path, v)
+			continue
+		}
+		if _, ok := v.(map[string]any); ok {
+			c.Problems = append(c.Problems, fmt.Sprintf("%s: unknown section [%s]", path, key))
+			continue
+		}
+		if list, ok := v.([]any); ok {
+			// Lists set comma separated flags.
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			c.set(key, strings.Join(items, ","), path)
+			continue
+		}
+		c.set(key, fmt.Sprint(v), path)
+	}
+}
+
+// envProfilesKey is the config table holding named go env profiles, e.g.
+//
+//	[env-profiles.corporate]
+//	GOPROXY = "https://proxy.corp.example"
+//	GONOSUMDB = "corp.example/*"
+const envProfilesKey = "env-profiles"
+
+// loadProfiles merges the profiles of one file, a later file overrides
+// single variables of a profile.
+func (c *Config) loadProfiles(path string, v any) {
+	profiles, ok := v.(map[string]any)
+	if !ok {
+		c.Problems = append(c.Problems, fmt.Sprintf("%s: %s must be a table", path, envProfilesKey))
+		return
+	}
+	for name, p := range profiles {
+		vars, ok := p.(map[string]any)
+		if !ok {
+			c.Problems = append(c.Problems, fmt.Sprintf("%s: %s.%s must be a table", path, envProfilesKey, name))
+			continue
+		}
+		if c.profiles[name] == nil {
+			c.profiles[name] = map[string]string{}
+		}
+		for key, value := range vars {
+			c.profiles[name][key] = fmt.Sprint(value)
+		}
+	}
+}
+
+// EnvProfile returns the go env variables of the named profile.
+func (c *Config) EnvProfile(name string) (map[string]string, bool) {
+	vars, ok := c.profiles[name]
+	return vars, ok
+}
+
+// EnvProfiles returns the names of all profiles, sorted.
+func (c *Config) EnvProfiles() []string {
+	names := make([]string, 0, len(c.profiles))
+	for name := range c.profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// themeKey is the config table overriding colors of the UI, either one
+// color for both backgrounds or one for each, e.g.
+//
+//	[theme]
+//	title = "#7d56f4"
+//	info = { light = "238", dark = "250" }
+const themeKey = "theme"
+
+// ThemeColor is a color of the theme, used on light and dark terminal
+// backgrounds respectively.
+type ThemeColor struct {
+	Light, Dark string
+}
+
+// loadTheme merges the colors of one file, a later file overrides single
+// colors.
+func (c *Config) loadTheme(path string, v any) {
+	colors, ok := v.(map[string]any)
+	if !ok {
+		c.Problems = append(c.Problems, fmt.Sprintf("%s: %s must be a table", path, themeKey))
+		return
+	}
+	for name, value := range colors {
+		switch value := value.(type) {
+		case map[string]any:
+			light, dark := fmt.Sprint(value["light"]), fmt.Sprint(value["dark"])
+			if value["light"] == nil || value["dark"] == nil {
+				c.Problems = append(c.Problems, fmt.Sprintf("%s: %s.%s needs both light and dark", path, themeKey, name))
+				continue
+			}
+			c.theme[name] = ThemeColor{Light: light, Dark: dark}
+		default:
+			c.theme[name] = ThemeColor{Light: fmt.Sprint(value), Dark: fmt.Sprint(value)}
+		}
+	}
+}
+
+// Theme returns the colors of the [theme] tables by name.
+func (c *Config) Theme() map[string]ThemeColor {
+	return c.theme
+}
+
+// Origin returns where the setting key came from.
+func (c *Config) Origin(key string) string {
+	return c.settings[key].Origin
+}
+
+// Settings returns every setting sorted by key.
+func (c *Config) Settings() []Setting {
+	all := make([]Setting, 0, len(c.settings))
+	for _, s := range c.settings {
+		all = append(all, s)
+	}
+	slices.SortFunc(all, func(a, b Setting) int { return strings.Compare(a.Key, b.Key) })
+	return all
+}
+
+// projectVersion finds the Go version a project asks for, from a
+// .go-version file or the toolchain line of go.mod in the working
+// directory or one of its parents.
+func projectVersion() (version, origin string) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", ""
+	}
+	for {
+		path := filepath.Join(dir, ".go-version")
+		if data, err := os.ReadFile(path); err == nil {
+			if v := strings.TrimSpace(string(data)); v != "" {
+				return v, path
+			}
+		}
+		path = filepath.Join(dir, "go.mod")
+		if f, err := os.Open(path); err == nil {
+			sc := bufio.NewScanner(f)
+			for sc.Scan() {
+				if v, ok := strings.CutPrefix(strings.TrimSpace(sc.Text()), "toolchain "); ok {
+					f.Close()
+					return strings.TrimSpace(v), path
+				}
+			}
+			f.Close()
+			// A module without a toolchain line pins nothing, do not look
+			// further up.
+			return "", ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,47 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// cgroupRuntimes maps markers in /proc/1/cgroup to the container runtime.
+var cgroupRuntimes = []struct{ marker, name string }{
+	{"docker", "Docker"},
+	{"kubepods", "Kubernetes"},
+	{"libpod", "Podman"},
+	{"lxc", "LXC"},
+}
+
+// DetectContainer returns the container runtime go-install runs under, such
+// as Docker, Podman or LXC. Containers are usually built from a Dockerfile,
+// where shell configuration files are never read and nobody answers prompts.
+func DetectContainer() (string, bool) {
+	switch {
+	case exists("/.dockerenv"):
+		return "Docker", true
+	case exists("/run/.containerenv"):
+		return "Podman", true
+	}
+	// Set by systemd-nspawn, LXC and Podman for the container's init.
+	if name := os.Getenv("container"); name != "" {
+		return name, true
+	}
+	data, err := os.ReadFile("/proc/1/cgroup")
+	if err != nil {
+		return "", false
+	}
+	for _, r := range cgroupRuntimes {
+		if strings.Contains(string(data), r.marker) {
+			return r.name, true
+		}
+	}
+	return "", false
+}
+
+// ContainerEnvLine is the Dockerfile instruction that puts the installed
+// toolchain on PATH.
+func ContainerEnvLine() string {
+	return "ENV PATH=$PATH:" + filepath.Join(GoRoot, "bin")
+}
@@ -0,0 +1,111 @@
+package common
+
+import (
+	"bufio"
+	"debug/elf"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/sys/cpu"
+)
+
+// ARMVersion returns the ARM architecture version of a 32-bit ARM system,
+// e.g. 7 on a Raspberry Pi 2 or 8 for a 64-bit CPU in 32-bit mode. Without
+// /proc/cpuinfo a GOARM hint is used, and 6 when there is none. It returns
+// 0 on other architectures.
+func ARMVersion() int {
+	if runtime.GOARCH != "arm" {
+		return 0
+	}
+	version := cpuinfoARMVersion()
+	if version == 0 {
+		version, _ = strconv.Atoi(strings.SplitN(os.Getenv("GOARM"), ",", 2)[0])
+	}
+	if version == 0 {
+		return 6
+	}
+	return version
+}
+
+// cpuinfoARMVersion reads the "CPU architecture" line of /proc/cpuinfo,
+// returning 0 when there is none.
+func cpuinfoARMVersion() int {
+	f, err := os.Open("/proc/cpuinfo")
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok || strings.TrimSpace(key) != "CPU architecture" {
+			continue
+		}
+		// Old kernels report e.g. "7" or "5TEJ".
+		value = strings.TrimSpace(value)
+		end := strings.IndexFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+		if end >= 0 {
+			value = value[:end]
+		}
+		version, _ := strconv.Atoi(value)
+		return version
+	}
+	return 0
+}
+
+// UserlandArch returns the architecture of a 32-bit userland, 386 or
+// armv7l, running on a 64-bit Linux kernel, as on Raspberry Pi OS with its
+// 64-bit kernel. The 64-bit toolchain would run there but not link against
+// the system's libraries. It returns "" otherwise.
+func UserlandArch() string {
+	return userlandArch()
+}
+
+// userlandArch inspects the userland once, GetArch is called often.
+var userlandArch = sync.OnceValue(func() string {
+	if runtime.GOOS != "linux" || (runtime.GOARCH != "amd64" && runtime.GOARCH != "arm64") {
+		return ""
+	}
+	if f, err := elf.Open("/bin/sh"); err == nil {
+		defer f.Close()
+		switch f.Machine {
+		case elf.EM_386:
+			return "386"
+		case elf.EM_ARM:
+			return "armv7l"
+		}
+		return ""
+	}
+	// Without a readable /bin/sh ask for the width of long in userland.
+	out, err := Command("getconf", "LONG_BIT").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "32" {
+		return ""
+	}
+	if runtime.GOARCH == "amd64" {
+		return "386"
+	}
+	return "armv7l"
+})
+
+// AMD64Level returns the x86-64 microarchitecture level (1-4) the CPU
+// supports, matching the GOAMD64 values v1-v4. It returns 0 on other
+// architectures.
+func AMD64Level() int {
+	if runtime.GOARCH != "amd64" {
+		return 0
+	}
+	x := cpu.X86
+	if !(x.HasCX16 && x.HasPOPCNT && x.HasSSE3 && x.HasSSSE3 && x.HasSSE41 && x.HasSSE42) {
+		return 1
+	}
+	if !(x.HasAVX && x.HasAVX2 && x.HasBMI1 && x.HasBMI2 && x.HasFMA && x.HasOSXSAVE) {
+		return 2
+	}
+	if !(x.HasAVX512F && x.HasAVX512BW && x.HasAVX512CD && x.HasAVX512DQ && x.HasAVX512VL) {
+		return 3
+	}
+	return 4
+}
@@ -1,12 +1,26 @@
 package common
 
 type GoRelease struct {
-	Version string `json:"version"`
-	Files   []struct {
-		Filename string `json:"filename"`
-		OS       string `json:"os"`
-		Arch     string `json:"arch"`
-		Kind     string `json:"kind"`
-		Sha256   string `json:"sha256"`
-	} `json:"files"`
+	Version string   `json:"version"`
+	Stable  bool     `json:"stable"`
+	Files   []GoFile `json:"files"`
+}
+
+type GoFile struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Kind     string `json:"kind"`
+	Sha256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+}
+
+// File returns the file of the release with the given name.
+func (r GoRelease) File(filename string) (GoFile, bool) {
+	for _, f := range r.Files {
+		if f.Filename == filename {
+			return f, true
+		}
+	}
+	return GoFile{}, false
 }
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"errors"
+	"os"
+)
+
+// Exit codes, so automation can branch on the class of a failure. A failing
+// --post-install-cmd passes its own exit code through instead.
+const (
+	ExitOK = 0
+	// ExitFailure covers every failure without a more specific code.
+	ExitFailure = 1
+	// ExitUsage is returned for invalid arguments.
+	ExitUsage = 2
+	// ExitVersionNotFound means the requested version does not exist or
+	// publishes no archive for this platform.
+	ExitVersionNotFound  = 3
+	ExitChecksumMismatch = 4
+	// ExitNetwork means go.dev or the mirror could not be reached or
+	// answered with an error status.
+	ExitNetwork = 5
+	// ExitPermission means go-install was not run as root or a file could
+	// not be written.
+	ExitPermission = 6
+	// ExitAborted means the user declined a prompt, quit the picker or
+	// interrupted go-install.
+	ExitAborted = 7
+	// ExitLocked means another go-install run holds the lock.
+	ExitLocked = 8
+	// ExitOutdated is returned by check when a newer stable release than
+	// the installed one exists.
+	ExitOutdated = 9
+)
+
+var (
+	ErrVersionNotFound  = errors.New("version not found")
+	ErrNoArchive        = errors.New("release not published for this platform")
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	ErrNotRoot          = errors.New("this tool requires root privileges")
+	// ErrCancelled is wrapped as e.g. "installation cancelled".
+	ErrCancelled = errors.New("cancelled")
+)
+
+// ExitCodeFor returns the exit code for err, ExitOK when it is nil.
+func ExitCodeFor(err error) int {
+	var statusErr *StatusError
+	var netErr *NetworkError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.Is(err, ErrVersionNotFound), errors.Is(err, ErrNoArchive):
+		return ExitVersionNotFound
+	case errors.Is(err, ErrChecksumMismatch):
+		return ExitChecksumMismatch
+	case errors.As(err, &netErr), errors.As(err, &statusErr):
+		return ExitNetwork
+	case errors.Is(err, ErrNotRoot), errors.Is(err, os.ErrPermission):
+		return ExitPermission
+	case errors.Is(err, ErrCancelled):
+		return ExitAborted
+	case errors.Is(err, ErrLocked):
+		return ExitLocked
+	}
+	return ExitFailure
+}
@@ -16,25 +16,120 @@ func NormalizeVersion(v string) string {
 	return v
 }
 
+// GetOS returns the GOOS whose release archives run here. Android, as in
+// Termux, runs the linux archives.
 func GetOS() string {
+	if runtime.GOOS == "android" {
+		return "linux"
+	}
 	return runtime.GOOS
 }
 
+// archOverride is the architecture set with SetArch.
+var archOverride string
+
+// SetArch makes GetArch return arch, to install archives for another
+// architecture than detected, e.g. amd64 under Rosetta.
+func SetArch(arch string) {
+	archOverride = arch
+}
+
+// ArchOverridden reports whether SetArch chose the architecture.
+func ArchOverridden() bool {
+	return archOverride != ""
+}
+
+// GetArch returns the architecture of this system as release archives name
+// it, which for 32-bit ARM carries the variant, e.g. armv7l. FindBuild
+// picks the archive that runs on it, e.g. armv6l.
 func GetArch() string {
+	if archOverride != "" {
+		return archOverride
+	}
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "amd64" && Translated() {
+		// The Mac runs arm64 natively, the Intel toolchain would run
+		// translated as well.
+		return "arm64"
+	}
+	if runtime.GOARCH == "arm" {
+		return fmt.Sprintf("armv%dl", ARMVersion())
+	}
+	if arch := UserlandArch(); arch != "" {
+		return arch
+	}
 	return runtime.GOARCH
 }
 
+// FindBuild returns the archive of version ver that runs on goos/arch,
+// matching the architectures the release's file list actually publishes.
 func FindBuild(all []GoRelease, ver, goos, arch string) (GoRelease, string, string, error) {
 	for _, r := range all {
 		if r.Version != ver {
 			continue
 		}
+		var best GoFile
 		for _, f := range r.Files {
-			if f.Kind == "archive" && f.OS == goos && f.Arch == arch {
-				return r, f.Filename, f.Sha256, nil
+			if f.Kind != "archive" || f.OS != goos || !archRuns(f.Arch, arch) {
+				continue
 			}
+			// Prefer the exact architecture, then the newest ARM variant.
+			if best.Filename == "" || best.Arch != arch && (f.Arch == arch || armVariant(f.Arch) > armVariant(best.Arch)) {
+				best = f
+			}
+		}
+		if best.Filename != "" {
+			return r, best.Filename, best.Sha256, nil
 		}
-		return r, "", "", fmt.Errorf("version %s exists but no archive for %s/%s", ver, goos, arch)
+		return r, "", "", fmt.Errorf("%w: no archive of %s for %s/%s", ErrNoArchive, ver, goos, arch)
 	}
-	return GoRelease{}, "", "", fmt.Errorf("version %s not found", ver)
+	return GoRelease{}, "", "", fmt.Errorf("%w: %s", ErrVersionNotFound, ver)
 }
+
+// archRuns reports whether an archive built for fileArch runs on arch.
+// 32-bit ARM archives such as armv6l also run on newer variants.
+func archRuns(fileArch, arch string) bool {
+	if fileArch == arch {
+		return true
+	}
+	file, host := armVariant(fileArch), armVariant(arch)
+	return file > 0 && host >= file
+}
+
+// armVariant returns N of an armvNl architecture name, or 0.
+func armVariant(arch string) int {
+	var n int
+	if _, err := fmt.Sscanf(arch, "armv%dl", &n); err != nil {
+		return 0
+	}
+	return n
+}
+
+// LatestStable returns the newest stable release from the go.dev listing,
+// which is ordered from newest to oldest.
+func LatestStable(all []GoRelease) (GoRelease, error) {
+	for _, r := range all {
+		if r.Stable {
+			return r, nil
+		}
+	}
+	return GoRelease{}, fmt.Errorf("no stable release found")
+}
+
+// LatestWithBuild returns the newest stable release that still publishes an
+// archive for goos/arch. It is used to suggest an alternative when a port
+// such as linux/386 is missing from the requested version.
+func LatestWithBuild(all []GoRelease, goos, arch string) (GoRelease, bool) {
+	for _, r := range all {
+		if !r.Stable {
+			continue
+		}
+		if _, _, _, err := FindBuild([]GoRelease{r}, r.Version, goos, arch); err == nil {
+			return r, true
+		}
+	}
+	return GoRelease{}, false
+}
+
+// AppVersion is the version of go-install itself, set at build time with
+// -ldflags "-X go-installer/common.AppVersion=v1.2.3".
+var AppVersion = "dev"
@@ -0,0 +1,70 @@
+package common
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFindBuild(t *testing.T) {
+	archive := func(goos, arch string) GoFile {
+		name := "go1.22.1." + goos + "-" + arch + ".tar.gz"
+		return GoFile{Filename: name, OS: goos, Arch: arch, Kind: "archive", Sha256: "sha-" + arch}
+	}
+	releases := []GoRelease{
+		{Version: "go1.22.1", Stable: true, Files: []GoFile{
+			{Filename: "go1.22.1.src.tar.gz", Kind: "source"},
+			{Filename: "go1.22.1.linux-amd64.msi", OS: "linux", Arch: "amd64", Kind: "installer"},
+			archive("linux", "amd64"),
+			archive("linux", "arm64"),
+			archive("linux", "armv6l"),
+			archive("darwin", "arm64"),
+		}},
+		{Version: "go1.21.0", Stable: true, Files: []GoFile{
+			archive("linux", "armv6l"),
+			{Filename: "go1.21.0.linux-armv7l.tar.gz", OS: "linux", Arch: "armv7l", Kind: "archive"},
+		}},
+	}
+	tests := []struct {
+		ver, goos, arch string
+		want            string
+		wantErr         error
+	}{
+		{"go1.22.1", "linux", "amd64", "go1.22.1.linux-amd64.tar.gz", nil},
+		{"go1.22.1", "linux", "arm64", "go1.22.1.linux-arm64.tar.gz", nil},
+		{"go1.22.1", "darwin", "arm64", "go1.22.1.darwin-arm64.tar.gz", nil},
+		// 32-bit ARM falls back to the newest published variant that runs.
+		{"go1.22.1", "linux", "armv7l", "go1.22.1.linux-armv6l.tar.gz", nil},
+		{"go1.22.1", "linux", "armv6l", "go1.22.1.linux-armv6l.tar.gz", nil},
+		{"go1.22.1", "linux", "armv5l", "", ErrNoArchive},
+		{"go1.21.0", "linux", "armv7l", "go1.21.0.linux-armv7l.tar.gz", nil},
+		{"go1.21.0", "linux", "armv8l", "go1.21.0.linux-armv7l.tar.gz", nil},
+		{"go1.22.1", "windows", "amd64", "", ErrNoArchive},
+		{"go1.22.1", "linux", "386", "", ErrNoArchive},
+		{"go1.20.0", "linux", "amd64", "", ErrVersionNotFound},
+	}
+	for _, tt := range tests {
+		_, file, _, err := FindBuild(releases, tt.ver, tt.goos, tt.arch)
+		if file != tt.want || !errors.Is(err, tt.wantErr) {
+			t.Errorf("FindBuild(%s, %s/%s) = %q, %v; want %q, %v", tt.ver, tt.goos, tt.arch, file, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestArchRuns(t *testing.T) {
+	tests := []struct {
+		file, host string
+		want       bool
+	}{
+		{"amd64", "amd64", true},
+		{"arm64", "amd64", false},
+		{"armv6l", "armv7l", true},
+		{"armv7l", "armv6l", false},
+		{"armv6l", "arm64", false},
+		{"386", "amd64", false},
+	}
+	for _, tt := range tests {
+		if got := archRuns(tt.file, tt.host); got != tt.want {
+			t.Errorf("archRuns(%s, %s) = %v, want %v", tt.file, tt.host, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"strings"
+)
+
+// ErrNoHome is returned by HomeDir when no usable home directory exists,
+// as for system users running go-install from a service or container.
+var ErrNoHome = errors.New("no home directory")
+
+// HomeDir returns the home directory of the current user. HOME is used when
+// it names an existing directory, otherwise the passwd entry is consulted.
+func HomeDir() (string, error) {
+	if home, err := os.UserHomeDir(); err == nil && isDir(home) && home != "/" {
+		return home, nil
+	}
+	if u, err := user.Current(); err == nil && isDir(u.HomeDir) && u.HomeDir != "/" {
+		return u.HomeDir, nil
+	}
+	return "", ErrNoHome
+}
+
+func isDir(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// Account is the user whose dotfiles go-install edits.
+type Account struct {
+	Name  string
+	Home  string
+	Shell string
+	UID   int
+	GID   int
+}
+
+// Elevator returns the tool used to run commands as root: sudo, or doas
+// where sudo is not installed, as on many Alpine systems.
+func Elevator() string {
+	if _, err := exec.LookPath("sudo"); err != nil {
+		if _, err := exec.LookPath("doas"); err == nil {
+			return "doas"
+		}
+	}
+	return "sudo"
+}
+
+// Privileged is Command run through Elevator when go-install itself is not
+// root and the system needs root to change packages.
+func Privileged(name string, args ...string) *exec.Cmd {
+	if os.Geteuid() == 0 || !NeedsRoot() {
+		return Command(name, args...)
+	}
+	return Command(Elevator(), append([]string{name}, args...)...)
+}
+
+// invokingName returns the user who ran sudo or doas.
+func invokingName() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	return os.Getenv("DOAS_USER")
+}
+
+// InvokingUser returns the user who ran go-install. Under sudo or doas that
+// is SUDO_USER or DOAS_USER rather than root, whose home they usually switch
+// to.
+func InvokingUser() (Account, error) {
+	if name := invokingName(); os.Geteuid() == 0 && name != "" && name != "root" {
+		u, err := user.Lookup(name)
+		if err != nil && os.Getenv("SUDO_UID") != "" {
+			u, err = user.LookupId(os.Getenv("SUDO_UID"))
+		}
+		if err != nil {
+			return Account{}, fmt.Errorf("invoking user %s: %w", name, err)
+		}
+		if !isDir(u.HomeDir) || u.HomeDir == "/" {
+			return Account{}, ErrNoHome
+		}
+		uid, _ := strconv.Atoi(u.Uid)
+		gid, _ := strconv.Atoi(u.Gid)
+		shell := loginShell(u.Username)
+		if shell == "" {
+			shell = os.Getenv("SHELL")
+		}
+		return Account{Name: u.Username, Home: u.HomeDir, Shell: shell, UID: uid, GID: gid}, nil
+	}
+
+	home, err := HomeDir()
+	if err != nil {
+		return Account{}, err
+	}
+	a := Account{Home: home, Shell: os.Getenv("SHELL"), UID: os.Getuid(), GID: os.Getgid()}
+	if u, err := user.Current(); err == nil {
+		a.Name = u.Username
+	}
+	return a, nil
+}
+
+// Chown hands path, written by root on behalf of a, over to a so their
+// login shell and editors can still modify it.
+func (a Account) Chown(path string) error {
+	if os.Geteuid() != 0 || a.UID == 0 {
+		return nil
+	}
+	return os.Lchown(path, a.UID, a.GID)
+}
+
+// loginShell returns the shell of name in /etc/passwd.
+func loginShell(name string) string {
+	data, err := os.ReadFile("/etc/passwd")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) == 7 && fields[0] == name {
+			return fields[6]
+		}
+	}
+	return ""
+}
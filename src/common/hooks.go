@@ -0,0 +1,44 @@
+package common
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Hook names, passed to hooks in GO_INSTALL_HOOK.
+const (
+	HookPostInstall = "post-install"
+	HookPreRemove   = "pre-remove"
+)
+
+// HookCommand returns the shell command of a hook with the toolchain in
+// GoRoot first on PATH. GO_INSTALL_HOOK, GO_INSTALL_VERSION,
+// GO_INSTALL_PREFIX and GO_INSTALL_GOROOT describe what happens. Its output
+// goes to stdout and stderr and is copied into the log.
+func HookCommand(hook, script, version string) *exec.Cmd {
+	cmd := Command("sh", "-c", script)
+	cmd.Env = append(os.Environ(),
+		"GOROOT="+GoRoot,
+		"PATH="+filepath.Join(GoRoot, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GO_INSTALL_HOOK="+hook,
+		"GO_INSTALL_VERSION="+version,
+		"GO_INSTALL_PREFIX="+InstallPrefix,
+		"GO_INSTALL_GOROOT="+GoRoot,
+	)
+	cmd.Stdout = hookLog{hook, "stdout", os.Stdout}
+	cmd.Stderr = hookLog{hook, "stderr", os.Stderr}
+	return cmd
+}
+
+// hookLog passes hook output on to next and logs it.
+type hookLog struct {
+	hook, stream string
+	next         *os.File
+}
+
+func (w hookLog) Write(p []byte) (int, error) {
+	Log.Info("hook output", "hook", w.hook, "stream", w.stream, "output", strings.TrimRight(string(p), "\n"))
+	return w.next.Write(p)
+}
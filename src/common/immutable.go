@@ -0,0 +1,58 @@
+package common
+
+import (
+	"os"
+	"slices"
+)
+
+// ImmutableSystem describes a distribution whose /usr is managed as a whole,
+// where go-install must neither run the package manager nor own /usr/local.
+type ImmutableSystem struct {
+	Name string
+	// Guidance explains how to get the build dependencies there.
+	Guidance []string
+}
+
+// immutableIDs are os-release IDs of image based distributions that do not
+// announce themselves through /run/ostree-booted.
+var immutableIDs = []string{"opensuse-microos", "opensuse-aeon", "opensuse-kalpa", "steamos"}
+
+// DetectImmutable reports whether the host is NixOS or an image based
+// distribution such as Fedora Silverblue.
+func DetectImmutable() (ImmutableSystem, bool) {
+	rel, _ := ReadOSRelease()
+	name := rel.PrettyName
+	if name == "" {
+		name = rel.ID
+	}
+	switch {
+	case rel.ID == "nixos" || exists("/etc/NIXOS"):
+		return ImmutableSystem{Name: orDefault(name, "NixOS"), Guidance: []string{
+			"get a C toolchain and git per project with: nix-shell -p gcc gnumake git",
+			"or add gcc, gnumake and git to environment.systemPackages in configuration.nix",
+		}}, true
+	case exists("/run/ostree-booted"):
+		return ImmutableSystem{Name: orDefault(name, "an ostree based system"), Guidance: []string{
+			"build inside a toolbox or distrobox container, which has dnf",
+			"or layer the packages with: rpm-ostree install gcc make git (needs a reboot)",
+		}}, true
+	case slices.Contains(immutableIDs, rel.ID):
+		return ImmutableSystem{Name: name, Guidance: []string{
+			"build inside a distrobox container",
+			"or add the packages with: transactional-update pkg install gcc make git",
+		}}, true
+	}
+	return ImmutableSystem{}, false
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
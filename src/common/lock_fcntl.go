@@ -0,0 +1,47 @@
+//go:build solaris || aix
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// AcquireLock takes an exclusive, non-blocking lock like the flock based
+// version. Solaris and AIX lack flock, so a POSIX record lock is used.
+func AcquireLock() (release func(), err error) {
+	// FreeBSD and some minimal images have no /var/lock.
+	if err := os.MkdirAll(filepath.Dir(LockPath), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(LockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ownState(LockPath)
+	lk := unix.Flock_t{Type: unix.F_WRLCK, Whence: 0}
+	if err := unix.FcntlFlock(f.Fd(), unix.F_SETLK, &lk); err != nil {
+		data, _ := os.ReadFile(LockPath)
+		f.Close()
+		if pid, perr := strconv.Atoi(strings.TrimSpace(string(data))); perr == nil {
+			return nil, fmt.Errorf("%w (pid %d)", ErrLocked, pid)
+		}
+		return nil, ErrLocked
+	}
+
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	lockHeld.Store(true)
+
+	return func() {
+		lockHeld.Store(false)
+		lk.Type = unix.F_UNLCK
+		unix.FcntlFlock(f.Fd(), unix.F_SETLK, &lk)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package common
+
+// AcquireLock is a no-op on platforms without flock.
+func AcquireLock() (release func(), err error) {
+	return func() {}, nil
+}
@@ -0,0 +1,45 @@
+//go:build unix && !solaris && !aix
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// AcquireLock takes an exclusive, non-blocking lock so parallel runs do not
+// race on GoRoot and the downloaded archive. The lock is released by the
+// returned function or when the process exits.
+func AcquireLock() (release func(), err error) {
+	// FreeBSD and some minimal images have no /var/lock.
+	if err := os.MkdirAll(filepath.Dir(LockPath), 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(LockPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	ownState(LockPath)
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		data, _ := os.ReadFile(LockPath)
+		f.Close()
+		if pid, perr := strconv.Atoi(strings.TrimSpace(string(data))); perr == nil {
+			return nil, fmt.Errorf("%w (pid %d)", ErrLocked, pid)
+		}
+		return nil, ErrLocked
+	}
+
+	f.Truncate(0)
+	f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	lockHeld.Store(true)
+
+	return func() {
+		lockHeld.Store(false)
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
@@ -0,0 +1,125 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SystemLogPath is where the log goes when go-install runs as root.
+const SystemLogPath = "/var/log/go-install.log"
+
+// LevelTrace logs with -vv what is too noisy for -v, such as every file
+// extracted from an archive.
+const LevelTrace = slog.LevelDebug - 4
+
+// Log records what go-install does for bug reports. It discards everything
+// until OpenLog is called.
+var Log = slog.New(slog.DiscardHandler)
+
+// LogPath is the file Log writes to, empty when there is none.
+var LogPath string
+
+// OpenLog appends to SystemLogPath, or to the go-install.log in the user's
+// state directory when that is not writable. verbosity 0 logs HTTP requests,
+// commands run and files written, 1 (-v) adds details and 2 (-vv) adds
+// per-file traces.
+func OpenLog(verbosity int) error {
+	level := slog.LevelInfo
+	switch {
+	case verbosity >= 2:
+		level = LevelTrace
+	case verbosity == 1:
+		level = slog.LevelDebug
+	}
+
+	var f *os.File
+	var errs []string
+	for _, path := range logPaths() {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		var err error
+		f, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+		if err == nil {
+			LogPath = path
+			break
+		}
+		errs = append(errs, err.Error())
+	}
+	if f == nil {
+		return fmt.Errorf("no writable log file: %s", strings.Join(errs, "; "))
+	}
+
+	Log = slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.LevelKey && a.Value.Any() == LevelTrace {
+				a.Value = slog.StringValue("TRACE")
+			}
+			return a
+		},
+	}))
+	Log.Info("start", "version", AppVersion, "args", os.Args[1:], "euid", os.Geteuid())
+	http.DefaultTransport = loggingTransport{http.DefaultTransport}
+	return nil
+}
+
+func logPaths() []string {
+	paths := []string{SystemLogPath}
+	state := os.Getenv("XDG_STATE_HOME")
+	if state == "" {
+		if home, err := HomeDir(); err == nil {
+			state = filepath.Join(home, ".local", "state")
+		}
+	}
+	if state != "" {
+		paths = append(paths, filepath.Join(state, "go-install", "go-install.log"))
+	}
+	return paths
+}
+
+// Command is exec.Command that logs the command line.
+func Command(name string, args ...string) *exec.Cmd {
+	Log.Info("exec", "cmd", append([]string{name}, args...))
+	return exec.Command(name, args...)
+}
+
+// CommandContext is exec.CommandContext that logs the command line. Output
+// pipes are closed shortly after ctx ends, so a child that keeps them open
+// cannot block Wait.
+func CommandContext(ctx context.Context, name string, args ...string) *exec.Cmd {
+	Log.Info("exec", "cmd", append([]string{name}, args...))
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// loggingTransport logs every HTTP request made through it.
+type loggingTransport struct {
+	next http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	attrs := []any{"method", req.Method, "url", req.URL.Redacted(), "duration", time.Since(start).Round(time.Millisecond)}
+	if err != nil {
+		Log.Warn("http", append(attrs, "err", err)...)
+		return nil, err
+	}
+	attrs = append(attrs, "status", resp.StatusCode)
+	if resp.ContentLength >= 0 {
+		attrs = append(attrs, "size", resp.ContentLength)
+	}
+	Log.Info("http", attrs...)
+	Log.Log(req.Context(), LevelTrace, "http headers", "request", req.Header, "response", resp.Header)
+	return resp, nil
+}
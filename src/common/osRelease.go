@@ -0,0 +1,63 @@
+package common
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// OSRelease holds the fields of os-release(5) go-install uses.
+type OSRelease struct {
+	ID string
+	// IDLike lists the distributions this one derives from, closest first.
+	IDLike     []string
+	PrettyName string
+	VersionID  string
+}
+
+// osReleasePaths are read in order, as the specification requires.
+var osReleasePaths = []string{"/etc/os-release", "/usr/lib/os-release"}
+
+// ReadOSRelease parses the host's os-release file.
+func ReadOSRelease() (OSRelease, error) {
+	var lastErr error
+	for _, path := range osReleasePaths {
+		f, err := os.Open(path)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		defer f.Close()
+		return parseOSRelease(f), nil
+	}
+	return OSRelease{}, lastErr
+}
+
+func parseOSRelease(f *os.File) OSRelease {
+	var rel OSRelease
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else {
+			value = strings.Trim(value, `"'`)
+		}
+		switch key {
+		case "ID":
+			rel.ID = strings.ToLower(value)
+		case "ID_LIKE":
+			rel.IDLike = strings.Fields(strings.ToLower(value))
+		case "PRETTY_NAME":
+			rel.PrettyName = value
+		case "VERSION_ID":
+			rel.VersionID = value
+		}
+	}
+	return rel
+}
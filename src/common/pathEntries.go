@@ -0,0 +1,127 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	// export PATH=..., PATH=..., set -gx PATH ... (fish), fish_add_path ...,
+	// setenv PATH ... and set path = (...) (csh)
+	pathLineRe   = regexp.MustCompile(`^\s*(?:export\s+)?PATH=(.*)$|^\s*set\s+-\w*x\w*\s+PATH\s+(.*)$|^\s*fish_add_path\s+(.*)$|^\s*setenv\s+PATH\s+(.*)$|^\s*set\s+path\s*=\s*(.*)$`)
+	assignLineRe = regexp.MustCompile(`^\s*(?:export\s+)?([A-Za-z_][A-Za-z0-9_]*)=(.*)$`)
+)
+
+// ShellPathEntries returns the directories a shell config adds to PATH,
+// with variables such as $HOME or an earlier `export GOROOT=...` expanded.
+func ShellPathEntries(content, home string) []string {
+	var dirs []string
+	for _, e := range shellPathEntries(content, home) {
+		dirs = append(dirs, e.dir)
+	}
+	return dirs
+}
+
+// pathEntry is a directory a shell config adds to PATH, first when it goes
+// ahead of the inherited PATH.
+type pathEntry struct {
+	dir   string
+	first bool
+}
+
+func shellPathEntries(content, home string) []pathEntry {
+	vars := map[string]string{"HOME": home}
+	lookup := func(name string) string {
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return os.Getenv(name)
+	}
+
+	var entries []pathEntry
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		if m := pathLineRe.FindStringSubmatch(line); m != nil {
+			value, sep := m[1], ":"
+			switch {
+			case m[4] != "":
+				value = m[4]
+			case m[5] != "":
+				value, sep = strings.Trim(m[5], "() \t"), " "
+			case value == "":
+				value, sep = m[2]+m[3], " "
+			}
+			// fish_add_path prepends unless told to append.
+			first := true
+			for _, f := range strings.Fields(m[3]) {
+				if f == "--append" || (strings.HasPrefix(f, "-") && !strings.HasPrefix(f, "--") && strings.Contains(f, "a")) {
+					first = false
+				}
+			}
+			for _, p := range strings.Split(unquote(value), sep) {
+				p = strings.TrimSpace(p)
+				if p == "$PATH" || p == "${PATH}" || p == "$path" {
+					first = false
+					continue
+				}
+				if p == "" || strings.HasPrefix(p, "-") {
+					continue
+				}
+				if strings.HasPrefix(p, "~/") {
+					p = home + p[1:]
+				}
+				entries = append(entries, pathEntry{filepath.Clean(os.Expand(unquote(p), lookup)), first})
+			}
+			continue
+		}
+		if m := assignLineRe.FindStringSubmatch(line); m != nil {
+			vars[m[1]] = os.Expand(unquote(m[2]), lookup)
+		}
+	}
+	return entries
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// SamePath reports whether two paths point at the same location once
+// symlinks are resolved.
+func SamePath(a, b string) bool {
+	if filepath.Clean(a) == filepath.Clean(b) {
+		return true
+	}
+	ra, errA := filepath.EvalSymlinks(a)
+	rb, errB := filepath.EvalSymlinks(b)
+	return errA == nil && errB == nil && ra == rb
+}
+
+// ConfiguresDir reports whether a shell config already puts dir on PATH,
+// however the entry is spelled (e.g. $HOME/goroot/bin symlinked to it).
+func ConfiguresDir(content, home, dir string) bool {
+	for _, e := range ShellPathEntries(content, home) {
+		if SamePath(e, dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// PrependsDir reports whether a shell config puts dir on PATH ahead of the
+// inherited PATH, see ConfiguresDir.
+func PrependsDir(content, home, dir string) bool {
+	for _, e := range shellPathEntries(content, home) {
+		if e.first && SamePath(e.dir, dir) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// DefaultPrefix is the install prefix unless one is configured.
+const DefaultPrefix = "/usr/local"
+
+// Locations below the install prefix, see SetPrefix.
+var (
+	// InstallPrefix is the directory the release archive is extracted into.
+	InstallPrefix = DefaultPrefix
+	// GoRoot is the globally active toolchain.
+	GoRoot = "/usr/local/go"
+	// VersionsDir keeps side-by-side toolchains, one directory per version.
+	VersionsDir = "/usr/local/go-install/versions"
+	// BackupsDir holds previous toolchains replaced by an install.
+	BackupsDir = "/usr/local/go-install/backups"
+)
+
+// UpstreamDL is the official download location of release archives.
+const UpstreamDL = "https://go.dev/dl/"
+
+// LockPath is the advisory lock held while go-install modifies the system.
+var LockPath = "/var/lock/go-install.lock"
+
+// DownloadBase is where release archives are downloaded from. The release
+// index and its checksums always come from go.dev, so a mirror cannot serve
+// tampered archives.
+var DownloadBase = UpstreamDL
+
+// SetPrefix moves GoRoot and the directories go-install manages below
+// prefix, which must be an absolute path.
+func SetPrefix(prefix string) error {
+	if !filepath.IsAbs(prefix) {
+		return fmt.Errorf("install prefix %q is not an absolute path", prefix)
+	}
+	prefix = filepath.Clean(prefix)
+	InstallPrefix = prefix
+	GoRoot = filepath.Join(prefix, "go")
+	VersionsDir = filepath.Join(prefix, "go-install", "versions")
+	BackupsDir = filepath.Join(prefix, "go-install", "backups")
+	return nil
+}
+
+// SetMirror downloads release archives from the mirror at base, which
+// serves the same file names as go.dev/dl/.
+func SetMirror(base string) error {
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("mirror %q is not an http(s) URL", base)
+	}
+	DownloadBase = strings.TrimSuffix(base, "/") + "/"
+	return nil
+}
+
+// ErrLocked is returned by AcquireLock when another run holds the lock.
+var ErrLocked = errors.New("another go-install run is in progress")
+
+// lockHeld is set while this process holds the lock, so code shared with
+// the installer library does not try to take it a second time.
+var lockHeld atomic.Bool
+
+// LockHeld reports whether this process holds the go-install lock.
+func LockHeld() bool {
+	return lockHeld.Load()
+}
+
+// VersionRoot returns the GOROOT of a toolchain kept in the versions store.
+func VersionRoot(version string) string {
+	return filepath.Join(VersionsDir, version, "go")
+}
+
+// CacheDir returns the go-install cache directory, honoring XDG_CACHE_HOME.
+// Installs that need root use root's cache, so commands run by the invoking
+// user, such as du, and sudo keeping HOME see the same directory.
+func CacheDir() string {
+	if NeedsRoot() && (os.Geteuid() != 0 || os.Getenv("SUDO_USER") != "") {
+		if dir := rootCacheDir(); dir != "" {
+			return filepath.Join(dir, "go-install")
+		}
+	}
+	if dir, err := os.UserCacheDir(); err == nil {
+		return filepath.Join(dir, "go-install")
+	}
+	return "/var/cache/go-install"
+}
+
+// rootCacheDir returns the default user cache directory of root, "" when
+// root has no home.
+func rootCacheDir() string {
+	u, err := user.LookupId("0")
+	if err != nil || u.HomeDir == "" {
+		return ""
+	}
+	if runtime.GOOS == "darwin" {
+		return filepath.Join(u.HomeDir, "Library", "Caches")
+	}
+	return filepath.Join(u.HomeDir, ".cache")
+}
@@ -0,0 +1,77 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtractedSizeFactor estimates the size of an extracted toolchain from the
+// size of its compressed archive.
+const ExtractedSizeFactor = 4
+
+// Requirement describes a directory go-install is about to write to.
+type Requirement struct {
+	Dir string
+	// Bytes is the free space needed in Dir.
+	Bytes int64
+	// Exec is set when binaries placed in Dir must be runnable.
+	Exec bool
+}
+
+// Preflight checks every requirement before anything is modified and
+// reports all problems at once.
+func Preflight(reqs ...Requirement) error {
+	var problems []string
+	for _, r := range reqs {
+		problems = append(problems, checkRequirement(r)...)
+	}
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("preflight checks failed:\n  • %s", strings.Join(problems, "\n  • "))
+}
+
+func checkRequirement(r Requirement) []string {
+	// Missing directories are created later, so check the closest
+	// existing parent instead.
+	dir := r.Dir
+	for !isDir(dir) {
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return []string{fmt.Sprintf("%s: no existing parent directory", r.Dir)}
+		}
+		dir = parent
+	}
+
+	var problems []string
+	fs, err := statFS(dir)
+	if err == nil {
+		if fs.readOnly {
+			problems = append(problems, fmt.Sprintf("%s is on a read-only filesystem", dir))
+		}
+		if r.Exec && fs.noExec {
+			problems = append(problems, fmt.Sprintf("%s is mounted noexec, the installed go binary could not run", dir))
+		}
+		if r.Bytes > 0 && fs.free < uint64(r.Bytes) {
+			problems = append(problems, fmt.Sprintf("%s has %s free, %s needed",
+				dir, FormatSize(int64(fs.free)), FormatSize(r.Bytes)))
+		}
+	}
+	if fs.readOnly {
+		return problems
+	}
+
+	// Permissions, ACLs and security modules all have a say, so probe
+	// with a real file rather than inspecting mode bits.
+	f, err := os.CreateTemp(dir, ".go-install-preflight-")
+	if err != nil {
+		problems = append(problems, fmt.Sprintf("%s is not writable: %v", dir, errors.Unwrap(err)))
+		return problems
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return problems
+}
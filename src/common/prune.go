@@ -0,0 +1,117 @@
+package common
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// PruneItem is a kept toolchain, backup or cached archive that prune
+// removes.
+type PruneItem struct {
+	// Kind is KindToolchain, KindBackup or KindCache.
+	Kind    string
+	Path    string
+	Version string
+	Size    int64
+}
+
+// DirSize returns the size of the regular files below path.
+func DirSize(path string) int64 {
+	var size int64
+	filepath.WalkDir(path, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if fi, err := d.Info(); err == nil {
+				size += fi.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// archiveOf reports whether the archive name, e.g.
+// go1.22.1.linux-amd64.tar.gz, holds version.
+func archiveOf(name, version string) bool {
+	rest, ok := strings.CutPrefix(name, version+".")
+	return ok && rest != "" && (rest[0] < '0' || rest[0] > '9')
+}
+
+// PlanPrune lists what prune removes: toolchains of the versions store
+// beyond the newest keep, backups beyond the newest keep and cached
+// archives of versions that are neither kept nor protected. The active
+// toolchain and protected versions are never removed.
+func PlanPrune(keep int, protected []string) ([]PruneItem, error) {
+	var spared []string
+	for _, p := range protected {
+		spared = append(spared, NormalizeVersion(p))
+	}
+	if active, err := InstalledVersion(GoRoot); err == nil {
+		spared = append(spared, active)
+	}
+
+	var items []PruneItem
+	stored := StoredVersions()
+	slices.SortFunc(stored, func(a, b string) int {
+		va, _ := ParseVersion(a)
+		vb, _ := ParseVersion(b)
+		if vb.Less(va) {
+			return -1
+		}
+		return 1
+	})
+	kept := slices.Clone(spared)
+	for i, v := range stored {
+		if i < keep || slices.Contains(spared, v) {
+			kept = append(kept, v)
+			continue
+		}
+		dir := filepath.Dir(VersionRoot(v))
+		items = append(items, PruneItem{Kind: KindToolchain, Path: dir, Version: v, Size: DirSize(dir)})
+	}
+
+	backups, err := ListBackups()
+	if err != nil {
+		return nil, err
+	}
+	for i, b := range backups {
+		if i < keep || slices.Contains(spared, b.Version) {
+			kept = append(kept, b.Version)
+			continue
+		}
+		items = append(items, PruneItem{Kind: KindBackup, Path: b.Path, Version: b.Version, Size: DirSize(b.Path)})
+	}
+
+	archives, err := ListCachedArchives()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range archives {
+		if slices.ContainsFunc(kept, func(v string) bool { return archiveOf(a.Name, v) }) {
+			continue
+		}
+		items = append(items, PruneItem{Kind: KindCache, Path: a.Path, Version: a.Name, Size: a.Size})
+	}
+	return items, nil
+}
+
+// Prune removes item, along with the file hashes of a toolchain.
+func Prune(item PruneItem) error {
+	if err := os.RemoveAll(item.Path); err != nil {
+		return err
+	}
+	if item.Kind != KindToolchain {
+		ForgetFile(item.Path)
+		return nil
+	}
+	// Toolchains are recorded by their GOROOT below the version directory.
+	ForgetFile(VersionRoot(item.Version))
+	os.Remove(TreeManifestPath(item.Version))
+	ForgetFile(TreeManifestPath(item.Version))
+	return nil
+}
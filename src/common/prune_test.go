@@ -0,0 +1,87 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPlanPrune(t *testing.T) {
+	dir := t.TempDir()
+	prev := []string{GoRoot, VersionsDir, BackupsDir, StatePath}
+	prevOwner := installOwner
+	defer func() {
+		GoRoot, VersionsDir, BackupsDir, StatePath = prev[0], prev[1], prev[2], prev[3]
+		installOwner = prevOwner
+	}()
+	GoRoot = filepath.Join(dir, "go")
+	VersionsDir = filepath.Join(dir, "versions")
+	BackupsDir = filepath.Join(dir, "backups")
+	StatePath = filepath.Join(dir, "state.json")
+	// Keep the cache in a scratch directory rather than root's.
+	installOwner = &Account{Home: dir}
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, "cache"))
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(GoRoot, "VERSION"), "go1.22.1\n")
+	for _, v := range []string{"go1.20.0", "go1.21.0", "go1.22.0", "go1.22.1"} {
+		write(filepath.Join(VersionRoot(v), "bin", "go"), "")
+	}
+	for _, name := range []string{"20240101-000000-go1.19.0", "20240102-000000-go1.21.0"} {
+		write(filepath.Join(BackupsDir, name, "go", "VERSION"), "")
+	}
+	for _, v := range []string{"go1.19.0", "go1.21.0", "go1.22.0", "go1.22.1", "go1.22.10"} {
+		write(CachedArchivePath(v+".linux-amd64.tar.gz", "0123456789abcdef0123"), "")
+	}
+
+	tests := []struct {
+		keep int
+		want []string
+	}{
+		{1, []string{
+			"backup go1.19.0",
+			"cache go1.19.0.linux-amd64.tar.gz",
+			"cache go1.22.0.linux-amd64.tar.gz",
+			"cache go1.22.10.linux-amd64.tar.gz",
+			"toolchain go1.21.0",
+			"toolchain go1.22.0",
+		}},
+		{0, []string{
+			"backup go1.19.0",
+			"backup go1.21.0",
+			"cache go1.19.0.linux-amd64.tar.gz",
+			"cache go1.21.0.linux-amd64.tar.gz",
+			"cache go1.22.0.linux-amd64.tar.gz",
+			"cache go1.22.10.linux-amd64.tar.gz",
+			"toolchain go1.21.0",
+			"toolchain go1.22.0",
+		}},
+		// The archives of kept backups stay as well.
+		{5, []string{
+			"cache go1.22.10.linux-amd64.tar.gz",
+		}},
+	}
+	for _, tt := range tests {
+		items, err := PlanPrune(tt.keep, []string{"1.20.0"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, item := range items {
+			got = append(got, item.Kind+" "+item.Version)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("PlanPrune(%d) = %q, want %q", tt.keep, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	releasesURL = "https://go.dev/dl/?mode=json"
+	// The full history is several megabytes, so it is fetched at most once
+	// per releaseIndexTTL and reused from the cache in between.
+	releaseIndexTTL = time.Hour
+)
+
+// FetchReleases returns the supported releases go.dev lists by default,
+// or with all set the complete release history. The history is cached and
+// a stale copy is used when go.dev cannot be reached.
+func FetchReleases(all bool) ([]GoRelease, error) {
+	if !all {
+		return decodeReleases(releasesURL)
+	}
+
+	path := filepath.Join(CacheDir(), "releases-all.json")
+	var cached []GoRelease
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &cached) == nil {
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) < releaseIndexTTL {
+			return cached, nil
+		}
+	}
+
+	releases, err := decodeReleases(releasesURL + "&include=all")
+	if err != nil {
+		if len(cached) > 0 {
+			return cached, nil
+		}
+		return nil, err
+	}
+	if data, err := json.Marshal(releases); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			_ = os.WriteFile(path, data, 0644)
+		}
+	}
+	return releases, nil
+}
+
+func decodeReleases(url string) ([]GoRelease, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, DiagnoseNetwork(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, DiagnoseNetwork(url, &StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+
+	var releases []GoRelease
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// ReleaseDate returns when the archive file was published. go.dev does not
+// list dates, the Last-Modified header of the download has it.
+func ReleaseDate(ctx context.Context, file string) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, DownloadBase+file, nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return time.Time{}, fmt.Errorf("HEAD %s: %s", file, resp.Status)
+	}
+	return http.ParseTime(resp.Header.Get("Last-Modified"))
+}
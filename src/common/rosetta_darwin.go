@@ -0,0 +1,10 @@
+package common
+
+import "golang.org/x/sys/unix"
+
+// Translated reports whether go-install itself runs under Rosetta, where
+// runtime.GOARCH is amd64 on an Apple Silicon Mac.
+func Translated() bool {
+	v, err := unix.SysctlUint32("sysctl.proc_translated")
+	return err == nil && v == 1
+}
@@ -0,0 +1,8 @@
+//go:build !darwin
+
+package common
+
+// Translated is only possible on macOS.
+func Translated() bool {
+	return false
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package common
+
+import "os/exec"
+
+// Command runs name for a, which is the current user on platforms without
+// setuid.
+func (a Account) Command(name string, args ...string) *exec.Cmd {
+	return Command(name, args...)
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package common
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Command runs name as a, so files it creates such as a module cache belong
+// to a. Without root it is Command.
+func (a Account) Command(name string, args ...string) *exec.Cmd {
+	cmd := Command(name, args...)
+	if os.Geteuid() != 0 || a.UID == 0 {
+		return cmd
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Credential: &syscall.Credential{Uid: uint32(a.UID), Gid: uint32(a.GID)}}
+	cmd.Env = append(os.Environ(), "HOME="+a.Home, "USER="+a.Name, "LOGNAME="+a.Name)
+	return cmd
+}
@@ -0,0 +1,311 @@
+package common
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PreferGoRoot puts the installed toolchain first on PATH, ahead of a Go
+// installed by the package manager.
+var PreferGoRoot bool
+
+// prependsGoRoot reports whether the toolchain goes first on PATH, which it
+// also does under WSL with a Windows Go on PATH so that one does not shadow
+// it.
+func prependsGoRoot() bool {
+	return PreferGoRoot || len(WindowsGoDirs()) > 0
+}
+
+// ExportLine is the shell line that puts the installed toolchain on PATH.
+func ExportLine() string {
+	if prependsGoRoot() {
+		return "export PATH=" + filepath.Join(GoRoot, "bin") + ":$PATH"
+	}
+	return "export PATH=$PATH:" + filepath.Join(GoRoot, "bin")
+}
+
+// ShellChange is the edit SetupEnvironment makes to a shell configuration
+// file, so it can be shown before it is applied.
+type ShellChange struct {
+	File string
+	// Lines are appended to File.
+	Lines []string
+	// Owner is the user File belongs to.
+	Owner Account
+	// Workspace is a GOPATH directory created along with the change.
+	Workspace string
+}
+
+// SetupEnvironment adds the Go bin directory to PATH in the shell config. It
+// returns the change it made and the previous content of the file, or a
+// change without File when nothing had to change.
+func SetupEnvironment() (ShellChange, []byte, error) {
+	c, err := PlanShellConfig()
+	if err != nil || c.File == "" {
+		return ShellChange{}, nil, err
+	}
+	original, err := ApplyShellChange(c)
+	if err != nil {
+		return ShellChange{}, nil, err
+	}
+	return c, original, nil
+}
+
+// RecordShellChange remembers c in the state so uninstall can remove the
+// lines again.
+func RecordShellChange(c ShellChange) error {
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+	s.AddFile(c.File, KindShellConfig, "modified")
+	s.Files[len(s.Files)-1].Added = c.Lines
+	return s.Save()
+}
+
+// DefaultShellLines are the lines go-install appends, for state records
+// written before the lines were tracked.
+func DefaultShellLines() []string {
+	return []string{"# Added by go-install", "export PATH=$PATH:" + filepath.Join(GoRoot, "bin")}
+}
+
+// RemoveShellChange removes the lines c appended from its file, leaving
+// anything the user added since in place. It reports whether they were
+// found. The file is edited in place through one descriptor, see
+// openShellFile.
+func RemoveShellChange(c ShellChange) (bool, error) {
+	f, err := openShellFile(c.File, c.Owner, false)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return false, err
+	}
+	content := string(data)
+	block := strings.Join(c.Lines, "\n") + "\n"
+	switch {
+	case strings.Contains(content, "\n"+block):
+		content = strings.Replace(content, "\n"+block, "", 1)
+	case strings.Contains(content, block):
+		content = strings.Replace(content, block, "", 1)
+	default:
+		return false, nil
+	}
+	if err := f.Truncate(0); err != nil {
+		return false, err
+	}
+	if _, err := f.WriteAt([]byte(content), 0); err != nil {
+		return false, err
+	}
+	Log.Info("write", "file", c.File, "remove", c.Lines)
+	return true, nil
+}
+
+// ApplyShellChange appends the lines of c and returns the previous content
+// of the file, read and written through one descriptor, see openShellFile.
+func ApplyShellChange(c ShellChange) ([]byte, error) {
+	if c.Workspace != "" {
+		if err := createWorkspace(c.Workspace, c.Owner); err != nil {
+			return nil, err
+		}
+	}
+	f, err := openShellFile(c.File, c.Owner, true)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	original, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := f.WriteString("\n" + strings.Join(c.Lines, "\n") + "\n"); err != nil {
+		return nil, err
+	}
+	Log.Info("write", "file", c.File, "append", c.Lines)
+	return original, nil
+}
+
+// createWorkspace creates dir and its bin directory for owner.
+func createWorkspace(dir string, owner Account) error {
+	for _, d := range []string{dir, filepath.Join(dir, "bin")} {
+		if _, err := os.Stat(d); err == nil {
+			continue
+		}
+		if err := os.Mkdir(d, 0755); err != nil {
+			return err
+		}
+		if err := owner.Chown(d); err != nil {
+			return err
+		}
+		Log.Info("write", "dir", d)
+	}
+	return nil
+}
+
+// PlanShellConfig returns the change SetupEnvironment would make, with an
+// empty File when PATH is configured already. The files edited are those of
+// the user who invoked sudo, not root's.
+func PlanShellConfig() (ShellChange, error) {
+	owner, err := InvokingUser()
+	if err != nil {
+		return ShellChange{}, err
+	}
+	files, csh := shellFiles(owner)
+	lines := []string{"# Added by go-install", ExportLine()}
+	if csh {
+		lines[1] = "setenv PATH ${PATH}:" + filepath.Join(GoRoot, "bin")
+		if prependsGoRoot() {
+			lines[1] = "setenv PATH " + filepath.Join(GoRoot, "bin") + ":${PATH}"
+		}
+	}
+	configured := putsOnPath(owner, filepath.Join(GoRoot, "bin"))
+	if prependsGoRoot() {
+		configured = putsFirstOnPath(owner, filepath.Join(GoRoot, "bin"))
+	}
+	return planShellChange(owner, files, lines, configured)
+}
+
+// Workspace setups offered by PlanWorkspace.
+const (
+	// WorkspacePath adds ~/go/bin, where go install puts tools, to PATH.
+	WorkspacePath = "path"
+	// WorkspaceExport also exports GOPATH and GOBIN explicitly.
+	WorkspaceExport = "export"
+)
+
+// PlanWorkspace returns the change that creates the ~/go workspace and puts
+// its bin directory on PATH, with an empty File when PATH has it already.
+func PlanWorkspace(mode string) (ShellChange, error) {
+	owner, err := InvokingUser()
+	if err != nil {
+		return ShellChange{}, err
+	}
+	files, csh := shellFiles(owner)
+	lines := []string{"# Go workspace, added by go-install"}
+	switch {
+	case mode == WorkspaceExport && csh:
+		lines = append(lines, "setenv GOPATH ${HOME}/go", "setenv GOBIN ${GOPATH}/bin", "setenv PATH ${PATH}:${GOBIN}")
+	case mode == WorkspaceExport:
+		lines = append(lines, "export GOPATH=$HOME/go", "export GOBIN=$GOPATH/bin", "export PATH=$PATH:$GOBIN")
+	case csh:
+		lines = append(lines, "setenv PATH ${PATH}:${HOME}/go/bin")
+	default:
+		lines = append(lines, "export PATH=$PATH:$HOME/go/bin")
+	}
+	workspace := filepath.Join(owner.Home, "go")
+	c, err := planShellChange(owner, files, lines, putsOnPath(owner, filepath.Join(workspace, "bin")))
+	if c.File != "" {
+		c.Workspace = workspace
+	}
+	return c, err
+}
+
+// ToolchainLocal is the GOTOOLCHAIN setting that keeps the go command on the
+// installed toolchain instead of downloading the one a go.mod asks for.
+const ToolchainLocal = "GOTOOLCHAIN=local"
+
+// SwitchesToolchains reports whether version downloads other toolchains on
+// demand, which Go does since 1.21.
+func SwitchesToolchains(version string) bool {
+	v, err := ParseVersion(version)
+	return err == nil && (v.Major > 1 || v.Minor >= 21)
+}
+
+// PlanToolchainPin returns the change that exports GOTOOLCHAIN=local, with
+// an empty File when the shell configuration sets GOTOOLCHAIN already.
+func PlanToolchainPin() (ShellChange, error) {
+	owner, err := InvokingUser()
+	if err != nil {
+		return ShellChange{}, err
+	}
+	files, csh := shellFiles(owner)
+	lines := []string{"# Pin the installed Go toolchain, added by go-install", "export " + ToolchainLocal}
+	if csh {
+		lines[1] = "setenv GOTOOLCHAIN local"
+	}
+	return planShellChange(owner, files, lines, func(content string) bool {
+		return strings.Contains(content, "GOTOOLCHAIN")
+	})
+}
+
+// putsOnPath returns a check whether a shell config puts dir on PATH.
+// Differently spelled entries that resolve to the same directory (symlinks,
+// $HOME paths) count as well.
+func putsOnPath(owner Account, dir string) func(string) bool {
+	return func(content string) bool {
+		return ConfiguresDir(content, owner.Home, dir)
+	}
+}
+
+// putsFirstOnPath is putsOnPath for a dir that must go ahead of PATH. An
+// existing line appending dir does not count, the prepending line added
+// after it wins.
+func putsFirstOnPath(owner Account, dir string) func(string) bool {
+	return func(content string) bool {
+		return PrependsDir(content, owner.Home, dir)
+	}
+}
+
+// shellFiles returns the configuration files of owner's login shell in the
+// order they are tried, and whether it is a csh.
+func shellFiles(owner Account) ([]string, bool) {
+	homeDir := owner.Home
+	shell := owner.Shell
+	switch base := filepath.Base(shell); {
+	case strings.Contains(shell, "zsh"):
+		return []string{filepath.Join(homeDir, ".zshrc")}, false
+	case strings.Contains(shell, "bash"):
+		return []string{
+			filepath.Join(homeDir, ".bashrc"),
+			filepath.Join(homeDir, ".bash_profile"),
+		}, false
+	case base == "csh" || base == "tcsh":
+		// The default shell of root on FreeBSD.
+		return []string{filepath.Join(homeDir, ".tcshrc"), filepath.Join(homeDir, ".cshrc")}, true
+	case base == "sh" || base == "ksh" || base == "mksh":
+		return []string{filepath.Join(homeDir, ".profile"), filepath.Join(homeDir, ".bashrc")}, false
+	}
+	return []string{filepath.Join(homeDir, ".bashrc")}, false
+}
+
+// planShellChange appends lines to the first existing file of files,
+// unless configured reports that its content has the setting already.
+func planShellChange(owner Account, files []string, lines []string, configured func(string) bool) (ShellChange, error) {
+	for _, configFile := range files {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			continue
+		}
+		if configured(string(content)) {
+			return ShellChange{}, nil
+		}
+		return ShellChange{File: configFile, Lines: lines, Owner: owner}, nil
+	}
+
+	// A fresh home, as in Termux, has no config files yet.
+	if _, ok := TermuxPrefix(); ok {
+		return ShellChange{File: files[0], Lines: lines, Owner: owner}, nil
+	}
+	return ShellChange{}, fmt.Errorf("could not find shell config file to update")
+}
+
+// MergeShellChanges combines two changes of the same user into one, with
+// the lines of b after those of a. The file of a wins when both have one.
+func MergeShellChanges(a, b ShellChange) ShellChange {
+	switch {
+	case a.File == "":
+		return b
+	case b.File == "":
+		return a
+	}
+	a.Lines = append(append([]string{}, a.Lines...), b.Lines...)
+	if b.Workspace != "" {
+		a.Workspace = b.Workspace
+	}
+	return a
+}
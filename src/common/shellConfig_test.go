@@ -0,0 +1,170 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestShellFiles(t *testing.T) {
+	home := "/home/u"
+	tests := []struct {
+		shell string
+		want  []string
+		csh   bool
+	}{
+		{"/bin/zsh", []string{".zshrc"}, false},
+		{"/usr/bin/bash", []string{".bashrc", ".bash_profile"}, false},
+		{"/bin/tcsh", []string{".tcshrc", ".cshrc"}, true},
+		{"/bin/csh", []string{".tcshrc", ".cshrc"}, true},
+		{"/bin/sh", []string{".profile", ".bashrc"}, false},
+		{"/bin/mksh", []string{".profile", ".bashrc"}, false},
+		{"/usr/bin/fish", []string{".bashrc"}, false},
+		{"", []string{".bashrc"}, false},
+	}
+	for _, tt := range tests {
+		files, csh := shellFiles(Account{Home: home, Shell: tt.shell})
+		var want []string
+		for _, f := range tt.want {
+			want = append(want, filepath.Join(home, f))
+		}
+		if strings.Join(files, " ") != strings.Join(want, " ") || csh != tt.csh {
+			t.Errorf("shellFiles(%s) = %v, %v; want %v, %v", tt.shell, files, csh, want, tt.csh)
+		}
+	}
+}
+
+func TestPlanShellChange(t *testing.T) {
+	t.Setenv("TERMUX_VERSION", "")
+	t.Setenv("PREFIX", "")
+	home := t.TempDir()
+	bashrc := filepath.Join(home, ".bashrc")
+	profile := filepath.Join(home, ".bash_profile")
+	files := []string{bashrc, profile}
+	lines := []string{"# Added by go-install", "export PATH=$PATH:/usr/local/go/bin"}
+	owner := Account{Home: home}
+	configured := putsOnPath(owner, "/usr/local/go/bin")
+
+	tests := []struct {
+		name     string
+		existing map[string]string
+		want     string
+		wantErr  bool
+	}{
+		{"no config file", nil, "", true},
+		{"first file", map[string]string{bashrc: "alias ll='ls -l'\n", profile: ""}, bashrc, false},
+		{"second file", map[string]string{profile: ""}, profile, false},
+		{"configured", map[string]string{bashrc: "export PATH=/usr/local/go/bin:$PATH\n"}, "", false},
+		{"commented out", map[string]string{bashrc: "# export PATH=$PATH:/usr/local/go/bin\n"}, bashrc, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Remove(bashrc)
+			os.Remove(profile)
+			for f, content := range tt.existing {
+				if err := os.WriteFile(f, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			c, err := planShellChange(owner, files, lines, configured)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if c.File != tt.want {
+				t.Errorf("File = %q, want %q", c.File, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfiguresDir(t *testing.T) {
+	home := "/home/u"
+	dir := "/home/u/go/bin"
+	tests := []struct {
+		content              string
+		configures, prepends bool
+	}{
+		{"export PATH=$PATH:$HOME/go/bin", true, false},
+		{"export PATH=\"$HOME/go/bin:$PATH\"", true, true},
+		{"PATH=~/go/bin:$PATH", true, true},
+		{"GOBIN=$HOME/go/bin\nexport PATH=$PATH:$GOBIN", true, false},
+		{"setenv PATH ${PATH}:/home/u/go/bin", true, false},
+		{"fish_add_path /home/u/go/bin", true, true},
+		{"fish_add_path --append /home/u/go/bin", true, false},
+		{"# export PATH=$HOME/go/bin:$PATH", false, false},
+		{"export PATH=$PATH:/usr/local/go/bin", false, false},
+	}
+	for _, tt := range tests {
+		if got := ConfiguresDir(tt.content, home, dir); got != tt.configures {
+			t.Errorf("ConfiguresDir(%q) = %v, want %v", tt.content, got, tt.configures)
+		}
+		if got := PrependsDir(tt.content, home, dir); got != tt.prepends {
+			t.Errorf("PrependsDir(%q) = %v, want %v", tt.content, got, tt.prepends)
+		}
+	}
+}
+
+func TestShellChangeRoundTrip(t *testing.T) {
+	rc := filepath.Join(t.TempDir(), ".bashrc")
+	before := "alias ll='ls -l'\n"
+	if err := os.WriteFile(rc, []byte(before), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c := ShellChange{File: rc, Lines: []string{"# Added by go-install", "export PATH=$PATH:/usr/local/go/bin"}}
+	original, err := ApplyShellChange(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(original) != before {
+		t.Errorf("ApplyShellChange returned %q, want %q", original, before)
+	}
+	if err := os.WriteFile(rc, append(mustRead(t, rc), "alias la='ls -a'\n"...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	removed, err := RemoveShellChange(c)
+	if err != nil || !removed {
+		t.Fatalf("RemoveShellChange = %v, %v", removed, err)
+	}
+	if got, want := string(mustRead(t, rc)), before+"alias la='ls -a'\n"; got != want {
+		t.Errorf("after removal %q, want %q", got, want)
+	}
+	if removed, err := RemoveShellChange(c); err != nil || removed {
+		t.Errorf("second RemoveShellChange = %v, %v, want false", removed, err)
+	}
+}
+
+func TestShellChangeRefusesSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("rc files are not symlink checked on windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "shadow")
+	if err := os.WriteFile(target, []byte("root:x\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	rc := filepath.Join(dir, ".bashrc")
+	if err := os.Symlink(target, rc); err != nil {
+		t.Fatal(err)
+	}
+	c := ShellChange{File: rc, Lines: []string{"root:x"}}
+	if _, err := ApplyShellChange(c); err == nil {
+		t.Error("ApplyShellChange followed a symlink")
+	}
+	if _, err := RemoveShellChange(c); err == nil {
+		t.Error("RemoveShellChange followed a symlink")
+	}
+	if got := string(mustRead(t, target)); got != "root:x\n" {
+		t.Errorf("symlink target changed to %q", got)
+	}
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
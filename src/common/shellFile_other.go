@@ -0,0 +1,14 @@
+//go:build !unix
+
+package common
+
+import "os"
+
+// openShellFile opens the shell configuration file path for reading and
+// writing, with create creating it when missing.
+func openShellFile(path string, owner Account, create bool) (*os.File, error) {
+	if create {
+		return os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	}
+	return os.OpenFile(path, os.O_RDWR, 0)
+}
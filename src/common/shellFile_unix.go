@@ -0,0 +1,49 @@
+//go:build unix
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// openShellFile opens the shell configuration file path of owner for
+// reading and writing without following a symlink, with create creating
+// it for owner when missing. Running as root it refuses anything but a
+// regular file owned by owner, so a user cannot point their rc file at a
+// system file for root to append to or rewrite.
+func openShellFile(path string, owner Account, create bool) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOFOLLOW, 0)
+	if errors.Is(err, os.ErrNotExist) && create {
+		f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL|syscall.O_NOFOLLOW, 0644)
+		if err == nil {
+			if err := owner.Chown(path); err != nil {
+				f.Close()
+				return nil, err
+			}
+			return f, nil
+		}
+	}
+	if errors.Is(err, syscall.ELOOP) {
+		return nil, fmt.Errorf("%s is a symlink, not changing it", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if !fi.Mode().IsRegular() {
+		f.Close()
+		return nil, fmt.Errorf("%s is not a regular file, not changing it", path)
+	}
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok && os.Geteuid() == 0 && int(st.Uid) != owner.UID {
+		f.Close()
+		return nil, fmt.Errorf("%s is not owned by %s, not changing it", path, owner.Name)
+	}
+	return f, nil
+}
@@ -0,0 +1,140 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StatePath is where go-install remembers what it changed on this host.
+var StatePath = "/var/lib/go-install/state.json"
+
+// Kinds of files recorded in the state manifest.
+const (
+	KindGoRoot       = "goroot"
+	KindToolchain    = "toolchain"
+	KindBackup       = "backup"
+	KindShellConfig  = "shell-config"
+	KindCompletion   = "completion"
+	KindIDESettings  = "ide-settings"
+	KindSymlink      = "symlink"
+	KindCache        = "cache"
+	KindState        = "state"
+	KindLock         = "lock"
+	KindTreeManifest = "tree-manifest"
+	KindAlternatives = "alternatives"
+	KindBinLink      = "bin-link"
+)
+
+// ManagedFile is a file or directory go-install created or modified.
+type ManagedFile struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"`
+	// Action is "created" or "modified".
+	Action  string    `json:"action"`
+	Updated time.Time `json:"updated"`
+	// Added holds the lines appended to a modified file, so they can be
+	// removed again.
+	Added []string `json:"added,omitempty"`
+}
+
+// State is the persistent record of files owned by go-install, used to undo
+// its changes later and exported for backup and audit tools.
+type State struct {
+	Files []ManagedFile `json:"managed,omitempty"`
+	// Labels are free-form key/value pairs describing the host, such as
+	// role=ci, exported with the manifest for inventory systems.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// LoadState reads the state file. A missing file yields an empty state.
+func LoadState() (State, error) {
+	var s State
+	data, err := os.ReadFile(StatePath)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(data, &s)
+	return s, err
+}
+
+func (s State) Save() error {
+	if err := os.MkdirAll(filepath.Dir(StatePath), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	Log.Debug("write", "file", StatePath)
+	tmp := StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	ownState(tmp)
+	return os.Rename(tmp, StatePath)
+}
+
+// AddFile records path, replacing an earlier record of the same path.
+func (s *State) AddFile(path, kind, action string) {
+	s.RemoveFile(path)
+	s.Files = append(s.Files, ManagedFile{Path: path, Kind: kind, Action: action, Updated: time.Now()})
+}
+
+func (s *State) RemoveFile(path string) {
+	s.Files = slices.DeleteFunc(s.Files, func(f ManagedFile) bool { return f.Path == path })
+}
+
+// RecordFile adds a single record to the state file.
+func RecordFile(path, kind, action string) error {
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+	s.AddFile(path, kind, action)
+	return s.Save()
+}
+
+var labelKeyRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// ParseLabel splits a key=value label and validates the key.
+func ParseLabel(s string) (key, value string, err error) {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("label %q: expected key=value", s)
+	}
+	if !labelKeyRe.MatchString(key) {
+		return "", "", fmt.Errorf("label %q: key may only contain letters, digits, '.', '_' and '-'", s)
+	}
+	return key, value, nil
+}
+
+// SetLabel sets a label, an empty value removes it.
+func (s *State) SetLabel(key, value string) {
+	if value == "" {
+		delete(s.Labels, key)
+		return
+	}
+	if s.Labels == nil {
+		s.Labels = map[string]string{}
+	}
+	s.Labels[key] = value
+}
+
+// ForgetFile removes a single record from the state file.
+func ForgetFile(path string) error {
+	s, err := LoadState()
+	if err != nil {
+		return err
+	}
+	s.RemoveFile(path)
+	return s.Save()
+}
@@ -0,0 +1,21 @@
+package common
+
+import "golang.org/x/sys/unix"
+
+type fsInfo struct {
+	readOnly bool
+	noExec   bool
+	free     uint64
+}
+
+func statFS(dir string) (fsInfo, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(dir, &st); err != nil {
+		return fsInfo{}, err
+	}
+	return fsInfo{
+		readOnly: st.Flags&unix.ST_RDONLY != 0,
+		noExec:   st.Flags&unix.ST_NOEXEC != 0,
+		free:     st.Bavail * uint64(st.Bsize),
+	}, nil
+}
@@ -0,0 +1,17 @@
+//go:build !linux
+
+package common
+
+import "errors"
+
+type fsInfo struct {
+	readOnly bool
+	noExec   bool
+	free     uint64
+}
+
+// statFS is only implemented on Linux, elsewhere Preflight falls back to
+// probing writability.
+func statFS(dir string) (fsInfo, error) {
+	return fsInfo{}, errors.New("filesystem information not available")
+}
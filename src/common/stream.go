@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// StreamArchive downloads the archive file into the cache, verifies it and
+// only then extracts it into dst, so not a byte from the network reaches
+// the extractor unchecked, even from a plain http mirror. A valid cached
+// archive is extracted without downloading. progress receives the download,
+// or the read of a cached archive during extraction. dst must be a scratch
+// directory that the caller discards on error. It returns the path of the
+// cached archive.
+func StreamArchive(ctx context.Context, file, sha string, dst string, progress io.Writer) (string, error) {
+	path := CachedArchivePath(file, sha)
+	f, err := os.Open(path)
+	if err == nil && VerifyReader(f, sha) != nil {
+		// A cached archive that no longer matches is corrupt.
+		f.Close()
+		os.Remove(path)
+		err = os.ErrNotExist
+	}
+	if err != nil {
+		if f, err = downloadVerified(ctx, file, sha, path, progress); err != nil {
+			return "", err
+		}
+		progress = nil
+	}
+	defer f.Close()
+
+	// Extract from the descriptor that was verified, not the path.
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	var src io.Reader = f
+	if progress != nil {
+		src = io.TeeReader(f, progress)
+	}
+	if err := ExtractTarGzReader(ctx, src, dst); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// downloadVerified downloads file to path through a partial file that is
+// only renamed into place once its checksum matches sha, and returns it
+// open.
+func downloadVerified(ctx context.Context, file, sha, path string, progress io.Writer) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	part := path + ".part"
+	if err := DownloadFile(ctx, file, part, progress); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(part)
+	if err != nil {
+		os.Remove(part)
+		return nil, err
+	}
+	if err := VerifyReader(f, sha); err != nil {
+		f.Close()
+		os.Remove(part)
+		return nil, err
+	}
+	if err := os.Rename(part, path); err != nil {
+		f.Close()
+		os.Remove(part)
+		return nil, err
+	}
+	return f, nil
+}
@@ -0,0 +1,66 @@
+package common
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStreamArchive(t *testing.T) {
+	archive := buildTarGz(t, []tarEntry{{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1"}})
+	sum := sha256.Sum256(archive)
+	good := hex.EncodeToString(sum[:])
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	}))
+	defer srv.Close()
+	prevBase, prevOwner := DownloadBase, installOwner
+	defer func() { DownloadBase, installOwner = prevBase, prevOwner }()
+	DownloadBase = srv.URL + "/"
+	// Keep the cache in a scratch directory rather than root's.
+	installOwner = &Account{Home: t.TempDir()}
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+
+	tests := []struct {
+		name    string
+		sha     string
+		wantErr error
+	}{
+		{"checksum mismatch", "0000000000000000000000000000000000000000000000000000000000000000", ErrChecksumMismatch},
+		{"verified", good, nil},
+		{"cached", good, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := t.TempDir()
+			path, err := StreamArchive(context.Background(), "go1.22.1.linux-amd64.tar.gz", tt.sha, dst, nil)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			_, statErr := os.Stat(filepath.Join(dst, "go", "VERSION"))
+			if tt.wantErr != nil {
+				if statErr == nil {
+					t.Error("an unverified archive was extracted")
+				}
+				if _, err := os.Stat(CachedArchivePath("go1.22.1.linux-amd64.tar.gz", tt.sha)); err == nil {
+					t.Error("an unverified archive was cached")
+				}
+				return
+			}
+			if statErr != nil {
+				t.Errorf("archive not extracted: %v", statErr)
+			}
+			if VerifyChecksum(path, tt.sha) != nil {
+				t.Errorf("cached archive %s does not verify", path)
+			}
+		})
+	}
+}
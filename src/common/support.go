@@ -0,0 +1,61 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrEndOfLife is wrapped when an unsupported release would be installed
+// without --allow-eol.
+var ErrEndOfLife = errors.New("release is end of life")
+
+// supportedSeriesCount is how many minor release lines the Go team
+// supports with security fixes, see https://go.dev/doc/devel/release.
+const supportedSeriesCount = 2
+
+// SupportedSeries returns the minor release lines with a stable release
+// that are still supported upstream, newest first, e.g. go1.25 and go1.24.
+func SupportedSeries(all []GoRelease) []Version {
+	var series []Version
+	for _, r := range all {
+		v, err := ParseVersion(r.Version)
+		if err != nil || !r.Stable {
+			continue
+		}
+		v = Version{Major: v.Major, Minor: v.Minor}
+		if !slices.Contains(series, v) {
+			series = append(series, v)
+		}
+	}
+	slices.SortFunc(series, func(a, b Version) int {
+		if b.Less(a) {
+			return -1
+		}
+		return 1
+	})
+	return series[:min(len(series), supportedSeriesCount)]
+}
+
+// EndOfLife reports whether version belongs to a minor release line that
+// no longer gets security fixes. Prereleases of the next line are not end
+// of life.
+func EndOfLife(all []GoRelease, version string) bool {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	supported := SupportedSeries(all)
+	if len(supported) == 0 {
+		return false
+	}
+	oldest := supported[len(supported)-1]
+	return v.Major < oldest.Major || (v.Major == oldest.Major && v.Minor < oldest.Minor)
+}
+
+// EndOfLifeError explains that version is end of life and how to install
+// it anyway.
+func EndOfLifeError(version string) error {
+	v, _ := ParseVersion(version)
+	return fmt.Errorf("%s: %s %w, it gets no security fixes; pass --allow-eol to install it anyway", version, v.Series(), ErrEndOfLife)
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// termuxDefaultPrefix is $PREFIX of the Termux app.
+const termuxDefaultPrefix = "/data/data/com.termux/files/usr"
+
+// TermuxPrefix returns Termux's $PREFIX when go-install runs inside the
+// Termux app on Android, where there is no root and no /usr/local.
+func TermuxPrefix() (string, bool) {
+	prefix := os.Getenv("PREFIX")
+	if os.Getenv("TERMUX_VERSION") == "" && !strings.Contains(prefix, "com.termux") {
+		return "", false
+	}
+	if prefix == "" {
+		prefix = termuxDefaultPrefix
+	}
+	return prefix, true
+}
+
+// NeedsRoot reports whether modifying the installation requires root. Termux
+// owns its prefix as the app user, and a prefix in the home of the invoking
+// user is theirs, see SetUserInstall.
+func NeedsRoot() bool {
+	_, termux := TermuxPrefix()
+	return !termux && installOwner == nil
+}
+
+// installOwner is the user a prefix in their home is installed for.
+var installOwner *Account
+
+// InHome reports whether path lies in the home directory of a.
+func InHome(path string, a Account) bool {
+	rel, err := filepath.Rel(a.Home, path)
+	return err == nil && a.Home != "/" && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+// SetUserInstall installs for a, whose home holds the install prefix: the
+// state, manifests and lock move to ~/.local/state/go-install and extracted
+// files are owned by a rather than root.
+func SetUserInstall(a Account) {
+	dir := filepath.Join(a.Home, ".local", "state", "go-install")
+	StatePath = filepath.Join(dir, "state.json")
+	ManifestsDir = filepath.Join(dir, "manifests")
+	LockPath = filepath.Join(dir, "go-install.lock")
+	installOwner = &a
+}
+
+// ownState hands path, a state file or directory of a user install, and
+// the directories above it in their home to that user when running as
+// root, so sudo does not leave root owned files in the home.
+func ownState(path string) {
+	if installOwner == nil {
+		return
+	}
+	for p := path; InHome(p, *installOwner) && p != installOwner.Home; p = filepath.Dir(p) {
+		installOwner.Chown(p)
+	}
+}
+
+// SetStateRoot moves the state, manifests and lock below root, for systems
+// whose /var is not writable.
+func SetStateRoot(root string) {
+	StatePath = filepath.Join(root, "var", "lib", "go-install", "state.json")
+	ManifestsDir = filepath.Join(root, "var", "lib", "go-install", "manifests")
+	LockPath = filepath.Join(root, "var", "lock", "go-install.lock")
+}
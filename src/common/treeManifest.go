@@ -0,0 +1,150 @@
+package common
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ManifestsDir keeps one sha256sum style listing per installed version.
+var ManifestsDir = "/var/lib/go-install/manifests"
+
+// TreeManifestPath returns where the file hashes of version are stored.
+func TreeManifestPath(version string) string {
+	return filepath.Join(ManifestsDir, version+".sha256")
+}
+
+// HashTree returns the SHA-256 of every regular file below root, keyed by
+// slash separated path relative to root. A symlinked root, as GoRoot is
+// after 'go-install use', is followed.
+func HashTree(root string) (map[string]string, error) {
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	sums := map[string]string{}
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		sums[filepath.ToSlash(rel)] = hex.EncodeToString(h.Sum(nil))
+		return nil
+	})
+	return sums, err
+}
+
+// WriteTreeManifest hashes the toolchain at root and stores the result as
+// the manifest of version.
+func WriteTreeManifest(root, version string) error {
+	sums, err := HashTree(root)
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(sums))
+	for p := range sums {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+
+	var sb strings.Builder
+	for _, p := range paths {
+		fmt.Fprintf(&sb, "%s  %s\n", sums[p], p)
+	}
+	if err := os.MkdirAll(ManifestsDir, 0755); err != nil {
+		return err
+	}
+	path := TreeManifestPath(version)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return err
+	}
+	ownState(path)
+	return RecordFile(path, KindTreeManifest, "created")
+}
+
+// LoadTreeManifest reads the stored manifest of version.
+func LoadTreeManifest(version string) (map[string]string, error) {
+	f, err := os.Open(TreeManifestPath(version))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	sums := map[string]string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		sum, path, ok := strings.Cut(sc.Text(), "  ")
+		if !ok {
+			return nil, fmt.Errorf("%s: malformed line %q", f.Name(), sc.Text())
+		}
+		sums[path] = sum
+	}
+	return sums, sc.Err()
+}
+
+// TreeDiff lists how an installed tree differs from its manifest.
+type TreeDiff struct {
+	Modified []string
+	Missing  []string
+	Extra    []string
+}
+
+func (d TreeDiff) Clean() bool {
+	return len(d.Modified)+len(d.Missing)+len(d.Extra) == 0
+}
+
+// VerifyTree re-hashes root and compares it to the manifest of version.
+func VerifyTree(root, version string) (TreeDiff, error) {
+	var d TreeDiff
+	want, err := LoadTreeManifest(version)
+	if err != nil {
+		return d, err
+	}
+	got, err := HashTree(root)
+	if err != nil {
+		return d, err
+	}
+	for p, sum := range want {
+		switch g, ok := got[p]; {
+		case !ok:
+			d.Missing = append(d.Missing, p)
+		case g != sum:
+			d.Modified = append(d.Modified, p)
+		}
+	}
+	for p := range got {
+		if _, ok := want[p]; !ok {
+			d.Extra = append(d.Extra, p)
+		}
+	}
+	slices.Sort(d.Modified)
+	slices.Sort(d.Missing)
+	slices.Sort(d.Extra)
+	return d, nil
+}
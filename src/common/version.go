@@ -0,0 +1,166 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed Go release version such as go1.22.1 or go1.23rc2.
+type Version struct {
+	Major, Minor, Patch int
+	// Pre is the prerelease suffix ("rc2", "beta1"), empty for stable releases.
+	Pre string
+}
+
+func ParseVersion(v string) (Version, error) {
+	s := strings.TrimPrefix(NormalizeVersion(v), "go")
+	var ver Version
+
+	if i := strings.IndexAny(s, "abcdefghijklmnopqrstuvwxyz"); i >= 0 {
+		ver.Pre = s[i:]
+		s = s[:i]
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) < 1 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", v)
+	}
+	nums := []*int{&ver.Major, &ver.Minor, &ver.Patch}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("invalid version %q", v)
+		}
+		*nums[i] = n
+	}
+	return ver, nil
+}
+
+// Series returns the minor release line, e.g. "go1.22".
+func (v Version) Series() string {
+	return fmt.Sprintf("go%d.%d", v.Major, v.Minor)
+}
+
+func (v Version) String() string {
+	if v.Pre != "" {
+		return fmt.Sprintf("go%d.%d%s", v.Major, v.Minor, v.Pre)
+	}
+	return fmt.Sprintf("go%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than o. Prereleases sort before the
+// release they precede.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	if v.Patch != o.Patch {
+		return v.Patch < o.Patch
+	}
+	if v.Pre == "" || o.Pre == "" {
+		return v.Pre != "" && o.Pre == ""
+	}
+	return comparePre(v.Pre, o.Pre) < 0
+}
+
+func comparePre(a, b string) int {
+	ai := strings.IndexAny(a, "0123456789")
+	bi := strings.IndexAny(b, "0123456789")
+	if ai < 0 || bi < 0 || a[:ai] != b[:bi] {
+		return strings.Compare(a, b)
+	}
+	an, _ := strconv.Atoi(a[ai:])
+	bn, _ := strconv.Atoi(b[bi:])
+	return an - bn
+}
+
+// InstalledVersion reads the version of the toolchain rooted at goroot from
+// its VERSION file.
+func InstalledVersion(goroot string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(goroot, "VERSION"))
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return "", fmt.Errorf("empty VERSION file in %s", goroot)
+	}
+	return line, nil
+}
+
+// LatestPatch returns the newest stable release in the same minor series as
+// version.
+func LatestPatch(all []GoRelease, version string) (GoRelease, error) {
+	cur, err := ParseVersion(version)
+	if err != nil {
+		return GoRelease{}, err
+	}
+	var best GoRelease
+	var bestVer Version
+	for _, r := range all {
+		if !r.Stable {
+			continue
+		}
+		v, err := ParseVersion(r.Version)
+		if err != nil || v.Major != cur.Major || v.Minor != cur.Minor {
+			continue
+		}
+		if best.Version == "" || bestVer.Less(v) {
+			best, bestVer = r, v
+		}
+	}
+	if best.Version == "" {
+		return GoRelease{}, fmt.Errorf("no stable release found for %s", cur.Series())
+	}
+	return best, nil
+}
+
+// ActiveGoVersion returns the version reported by `go version` for the go
+// binary found on PATH, falling back to the managed installation since sudo
+// usually resets PATH.
+func ActiveGoVersion() (string, error) {
+	bin, err := exec.LookPath("go")
+	if err != nil {
+		bin = filepath.Join(GoRoot, "bin", "go")
+	}
+	cmd := exec.Command(bin, "version")
+	// Without it a go.mod toolchain line in the working directory makes go
+	// report, or even download, another toolchain.
+	cmd.Env = append(os.Environ(), ToolchainLocal)
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("could not run go version: %w", err)
+	}
+	// go version go1.22.1 linux/amd64
+	fields := strings.Fields(string(out))
+	if len(fields) < 3 {
+		return "", fmt.Errorf("unexpected go version output %q", strings.TrimSpace(string(out)))
+	}
+	return fields[2], nil
+}
+
+// NewerStable returns the latest stable release when it is newer than
+// installed.
+func NewerStable(all []GoRelease, installed string) (GoRelease, bool) {
+	latest, err := LatestStable(all)
+	if err != nil {
+		return GoRelease{}, false
+	}
+	cur, err := ParseVersion(installed)
+	if err != nil {
+		return GoRelease{}, false
+	}
+	lv, err := ParseVersion(latest.Version)
+	if err != nil || !cur.Less(lv) {
+		return GoRelease{}, false
+	}
+	return latest, true
+}
@@ -0,0 +1,60 @@
+package common
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Version
+		wantErr bool
+	}{
+		{"go1.22.1", Version{Major: 1, Minor: 22, Patch: 1}, false},
+		{"1.22.1", Version{Major: 1, Minor: 22, Patch: 1}, false},
+		{"go1.22", Version{Major: 1, Minor: 22}, false},
+		{"go1.23rc2", Version{Major: 1, Minor: 23, Pre: "rc2"}, false},
+		{"go1.21beta1", Version{Major: 1, Minor: 21, Pre: "beta1"}, false},
+		{"go1", Version{Major: 1}, false},
+		{"go1.2.3.4", Version{}, true},
+		{"go1.x", Version{}, true},
+		{"", Version{}, true},
+		{"devel", Version{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, %v; want %+v, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestVersionLess(t *testing.T) {
+	// Each version is older than the next.
+	ordered := []string{
+		"go1.9.7",
+		"go1.10",
+		"go1.21beta1",
+		"go1.21rc2",
+		"go1.21rc10",
+		"go1.21.0",
+		"go1.21.1",
+		"go1.21.10",
+		"go1.22rc1",
+		"go1.22.0",
+		"go2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, err := ParseVersion(ordered[i])
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ParseVersion(ordered[j])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := a.Less(b); got != (i < j) {
+				t.Errorf("%s.Less(%s) = %v, want %v", ordered[i], ordered[j], got, i < j)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// LegacyInstall reports the version of a plain (non-symlinked) GoRoot
+// directory, as created by single-version installs.
+func LegacyInstall() (string, bool) {
+	fi, err := os.Lstat(GoRoot)
+	if err != nil || !fi.IsDir() {
+		return "", false
+	}
+	version, err := InstalledVersion(GoRoot)
+	if err != nil {
+		return "unknown", true
+	}
+	return version, true
+}
+
+// MigrateLegacy moves a plain GoRoot directory into the versions store and
+// replaces it with a symlink, keeping the toolchain instead of downloading
+// it again.
+func MigrateLegacy() (string, error) {
+	version, ok := LegacyInstall()
+	if !ok {
+		return "", nil
+	}
+	if version == "unknown" {
+		return "", fmt.Errorf("cannot detect the version of %s (missing VERSION file)", GoRoot)
+	}
+	dst := VersionRoot(version)
+	if _, err := os.Stat(dst); err == nil {
+		return "", fmt.Errorf("%s is already in the versions store at %s", version, dst)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(GoRoot, dst); err != nil {
+		return "", err
+	}
+	if err := os.Symlink(dst, GoRoot); err != nil {
+		// Put the toolchain back rather than leave the host without one.
+		os.Rename(dst, GoRoot)
+		return "", err
+	}
+	RecordFile(dst, KindToolchain, "created")
+	RecordFile(GoRoot, KindSymlink, "created")
+	return version, nil
+}
+
+// IsStored reports whether version is present in the versions store.
+func IsStored(version string) bool {
+	_, err := os.Stat(filepath.Join(VersionRoot(version), "bin", "go"))
+	return err == nil
+}
+
+// UseVersion points GoRoot at a stored version. The symlink is replaced
+// with a rename so GoRoot never disappears.
+func UseVersion(version string) error {
+	if !IsStored(version) {
+		return fmt.Errorf("%s is not in the versions store", version)
+	}
+	if _, ok := LegacyInstall(); ok {
+		return fmt.Errorf("%s is a plain directory, migrate it first", GoRoot)
+	}
+	tmp := GoRoot + ".tmp-link"
+	os.Remove(tmp)
+	if err := os.Symlink(VersionRoot(version), tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, GoRoot); err != nil {
+		return err
+	}
+	return RecordFile(GoRoot, KindSymlink, "modified")
+}
+
+// StoredVersions lists the versions in the versions store.
+func StoredVersions() []string {
+	entries, err := os.ReadDir(VersionsDir)
+	if err != nil {
+		return nil
+	}
+	var versions []string
+	for _, e := range entries {
+		if e.IsDir() && IsStored(e.Name()) {
+			versions = append(versions, e.Name())
+		}
+	}
+	return versions
+}
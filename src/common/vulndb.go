@@ -0,0 +1,217 @@
+package common
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const vulnDBURL = "https://vuln.go.dev"
+
+// vulnModules are the modules of the vulnerability database that cover the
+// Go distribution itself.
+var vulnModules = []string{"stdlib", "toolchain"}
+
+// Vuln is an entry of the Go vulnerability database in the OSV format.
+type Vuln struct {
+	ID       string         `json:"id"`
+	Modified string         `json:"modified"`
+	Summary  string         `json:"summary"`
+	Aliases  []string       `json:"aliases"`
+	Affected []VulnAffected `json:"affected"`
+}
+
+type VulnAffected struct {
+	Package struct {
+		Name string `json:"name"`
+	} `json:"package"`
+	Ranges []struct {
+		Type   string `json:"type"`
+		Events []struct {
+			Introduced string `json:"introduced"`
+			Fixed      string `json:"fixed"`
+		} `json:"events"`
+	} `json:"ranges"`
+}
+
+type vulnIndexModule struct {
+	Path  string `json:"path"`
+	Vulns []struct {
+		ID       string `json:"id"`
+		Modified string `json:"modified"`
+		Fixed    string `json:"fixed"`
+	} `json:"vulns"`
+}
+
+// semverVersion converts a version of the vulnerability database, e.g.
+// 1.21.0-rc.1, to a Go release version.
+func semverVersion(s string) (Version, error) {
+	base, pre, _ := strings.Cut(strings.TrimPrefix(s, "v"), "-")
+	v, err := ParseVersion("go" + base)
+	if err != nil {
+		return Version{}, err
+	}
+	v.Pre = strings.ReplaceAll(pre, ".", "")
+	return v, nil
+}
+
+// FixedIn reports whether v of the Go distribution is affected and the
+// release that fixes it, the zero Version when no fix is released yet.
+func (e Vuln) FixedIn(v Version) (Version, bool) {
+	for _, a := range e.Affected {
+		if !slices.Contains(vulnModules, a.Package.Name) {
+			continue
+		}
+		for _, r := range a.Ranges {
+			if r.Type != "SEMVER" {
+				continue
+			}
+			affected := false
+			for _, ev := range r.Events {
+				if ev.Introduced != "" {
+					from, err := semverVersion(ev.Introduced)
+					if ev.Introduced == "0" || (err == nil && !v.Less(from)) {
+						affected = true
+					}
+				}
+				if ev.Fixed != "" {
+					fixed, err := semverVersion(ev.Fixed)
+					if err != nil {
+						continue
+					}
+					if affected && v.Less(fixed) {
+						return fixed, true
+					}
+					affected = false
+				}
+			}
+			if affected {
+				return Version{}, true
+			}
+		}
+	}
+	return Version{}, false
+}
+
+// Affects reports whether v of the Go distribution is affected.
+func (e Vuln) Affects(v Version) bool {
+	_, ok := e.FixedIn(v)
+	return ok
+}
+
+// FetchVulns returns the advisories of the Go vulnerability database for
+// the standard library and the toolchain that affect version, sorted by ID.
+// Entries are cached and only fetched again when the index lists a newer
+// modification.
+func FetchVulns(version string) ([]Vuln, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	var index []vulnIndexModule
+	if err := getJSON(vulnDBURL+"/index/modules.json", &index); err != nil {
+		return nil, err
+	}
+
+	modified := map[string]string{}
+	for _, m := range index {
+		if !slices.Contains(vulnModules, m.Path) {
+			continue
+		}
+		for _, e := range m.Vulns {
+			// The index lists the newest fix, releases after it are
+			// not affected.
+			if fixed, err := semverVersion(e.Fixed); e.Fixed != "" && err == nil && !v.Less(fixed) {
+				continue
+			}
+			modified[e.ID] = e.Modified
+		}
+	}
+
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		vulns    []Vuln
+		firstErr error
+	)
+	sem := make(chan struct{}, 8)
+	for id, mod := range modified {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			e, err := fetchVuln(id, mod)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil:
+				if firstErr == nil {
+					firstErr = err
+				}
+			case e.Affects(v):
+				vulns = append(vulns, e)
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	slices.SortFunc(vulns, func(a, b Vuln) int { return strings.Compare(a.ID, b.ID) })
+	return vulns, nil
+}
+
+func fetchVuln(id, modified string) (Vuln, error) {
+	path := filepath.Join(CacheDir(), "vulndb", id+".json")
+	var e Vuln
+	if data, err := os.ReadFile(path); err == nil && json.Unmarshal(data, &e) == nil && e.Modified == modified {
+		return e, nil
+	}
+	if err := getJSON(vulnDBURL+"/ID/"+id+".json", &e); err != nil {
+		return Vuln{}, err
+	}
+	if data, err := json.Marshal(e); err == nil {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+			_ = os.WriteFile(path, data, 0644)
+		}
+	}
+	return e, nil
+}
+
+func getJSON(url string, v any) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return DiagnoseNetwork(url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return DiagnoseNetwork(url, &StatusError{URL: url, StatusCode: resp.StatusCode, Status: resp.Status})
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// FirstUnaffected returns the oldest stable release newer than version that
+// none of vulns affect.
+func FirstUnaffected(all []GoRelease, version string, vulns []Vuln) (GoRelease, bool) {
+	cur, err := ParseVersion(version)
+	if err != nil {
+		return GoRelease{}, false
+	}
+	var best GoRelease
+	var bestVer Version
+	for _, r := range all {
+		v, err := ParseVersion(r.Version)
+		if err != nil || !r.Stable || !cur.Less(v) || (best.Version != "" && bestVer.Less(v)) {
+			continue
+		}
+		if !slices.ContainsFunc(vulns, func(e Vuln) bool { return e.Affects(v) }) {
+			best, bestVer = r, v
+		}
+	}
+	return best, best.Version != ""
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// windowsDriveRe matches PATH entries WSL interop adds from Windows.
+var windowsDriveRe = regexp.MustCompile(`^/mnt/[a-z]/`)
+
+// IsWSL reports whether go-install runs in the Windows Subsystem for Linux.
+func IsWSL() bool {
+	data, err := os.ReadFile("/proc/version")
+	return err == nil && strings.Contains(strings.ToLower(string(data)), "microsoft")
+}
+
+// WindowsGoDirs returns the Windows directories on PATH holding a go or
+// go.exe, such as C:\Program Files\Go\bin or a Scoop shim directory. Their
+// go can win over the Linux toolchain appended to PATH.
+func WindowsGoDirs() []string {
+	if !IsWSL() {
+		return nil
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if !windowsDriveRe.MatchString(dir) {
+			continue
+		}
+		for _, name := range []string{"go", "go.exe"} {
+			if fi, err := os.Stat(filepath.Join(dir, name)); err == nil && !fi.IsDir() {
+				dirs = append(dirs, dir)
+				break
+			}
+		}
+	}
+	return dirs
+}
@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"strings"
+)
+
+// RunAudit lists the known vulnerabilities of the standard library and the
+// toolchain that affect the active toolchain and recommends the oldest
+// release that fixes all of them. It reports whether any were found.
+func RunAudit() (bool, error) {
+	installed, err := common.ActiveGoVersion()
+	if err != nil {
+		return false, err
+	}
+	v, err := common.ParseVersion(installed)
+	if err != nil {
+		return false, err
+	}
+	vulns, err := common.FetchVulns(installed)
+	if err != nil {
+		return false, err
+	}
+	if len(vulns) == 0 {
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ No known vulnerabilities affect %s", installed)))
+		return false, nil
+	}
+
+	fmt.Println(TitleStyle.Render(fmt.Sprintf("%d known vulnerabilities affect %s", len(vulns), installed)))
+	for _, e := range vulns {
+		id := e.ID
+		if len(e.Aliases) > 0 {
+			id += " (" + strings.Join(e.Aliases, ", ") + ")"
+		}
+		fixed := "no fix released yet"
+		if f, _ := e.FixedIn(v); f != (common.Version{}) {
+			fixed = "fixed in " + f.String()
+		}
+		fmt.Println(ErrorStyle.Render("✗ " + id))
+		fmt.Printf("  %s\n", e.Summary)
+		fmt.Println(InfoStyle.Render("  " + fixed + ", https://pkg.go.dev/vuln/" + e.ID))
+	}
+	fmt.Println()
+
+	releases, err := getReleases()
+	if err != nil {
+		return true, err
+	}
+	target, ok := common.FirstUnaffected(releases, installed, vulns)
+	if !ok {
+		fmt.Println(InfoStyle.Render("! No release fixes all of them yet."))
+		return true, nil
+	}
+	fmt.Println(TitleStyle.Render(fmt.Sprintf("⬆  Upgrade to %s or later: go-install --version %s",
+		target.Version, strings.TrimPrefix(target.Version, "go"))))
+	if common.EndOfLife(releases, target.Version) {
+		fmt.Println(InfoStyle.Render(fmt.Sprintf("! %s is end of life, go-install update --major installs a supported release.", target.Version)))
+	}
+	return true, nil
+}
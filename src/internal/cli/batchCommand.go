@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// batchManifest lists the versions a build farm keeps available.
+type batchManifest struct {
+	Versions []batchEntry `json:"versions" toml:"versions" yaml:"versions"`
+}
+
+type batchEntry struct {
+	Version string `json:"version" toml:"version" yaml:"version"`
+	// Prefix installs into Prefix/go, empty means the versions store.
+	Prefix string `json:"prefix" toml:"prefix" yaml:"prefix"`
+}
+
+// loadBatchManifest reads a JSON, TOML or YAML manifest, told apart by the
+// file extension.
+func loadBatchManifest(path string) (batchManifest, error) {
+	var m batchManifest
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return m, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.Unmarshal(data, &m)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &m)
+	default:
+		err = json.Unmarshal(data, &m)
+	}
+	if err != nil {
+		return m, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(m.Versions) == 0 {
+		return m, fmt.Errorf("%s lists no versions", path)
+	}
+	for i, e := range m.Versions {
+		if e.Version == "" {
+			return m, fmt.Errorf("%s: entry %d has no version", path, i+1)
+		}
+		m.Versions[i].Version = common.NormalizeVersion(e.Version)
+	}
+	return m, nil
+}
+
+// RunBatch installs every version of the manifest at path, skipping those
+// already present, and keeps going when one fails.
+func RunBatch(path string, heartbeat time.Duration) error {
+	manifest, err := loadBatchManifest(path)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+
+	total := len(manifest.Versions)
+	var installed, present int
+	var failed []string
+	for i, e := range manifest.Versions {
+		prefix := filepath.Join(common.VersionsDir, e.Version)
+		if e.Prefix != "" {
+			prefix = e.Prefix
+		}
+		root := filepath.Join(prefix, "go")
+		fmt.Fprintln(os.Stderr, TitleStyle.UnsetMarginBottom().Render(fmt.Sprintf("[%d/%d] %s → %s", i+1, total, e.Version, root)))
+		if v, err := common.InstalledVersion(root); err == nil && v == e.Version {
+			fmt.Fprintln(os.Stderr, SuccessStyle.Render("✓ Already installed"))
+			present++
+			continue
+		}
+		if err := installInto(ctx, releases, e.Version, prefix, heartbeat); err != nil {
+			if errors.Is(ctx.Err(), context.Canceled) {
+				return fmt.Errorf("batch install %w after %d of %d versions", common.ErrCancelled, i, total)
+			}
+			fmt.Fprintln(os.Stderr, ErrorStyle.Render(fmt.Sprintf("✗ %s: %v", e.Version, err)))
+			failed = append(failed, e.Version)
+			continue
+		}
+		installed++
+	}
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render(fmt.Sprintf("\n%d installed, %d already present, %d failed", installed, present, len(failed))))
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d versions failed: %s", len(failed), total, strings.Join(failed, ", "))
+	}
+	return nil
+}
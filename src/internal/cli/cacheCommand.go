@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+)
+
+// RunCache lists or cleans the archive cache.
+func RunCache(action string, maxSize int64, all bool) error {
+	switch action {
+	case "list":
+		archives, err := common.ListCachedArchives()
+		if err != nil {
+			return err
+		}
+		if len(archives) == 0 {
+			fmt.Println(InfoStyle.Render("Archive cache is empty (" + common.ArchiveCacheDir() + ")"))
+			return nil
+		}
+		var total int64
+		for _, a := range archives {
+			total += a.Size
+			fmt.Printf("  %-40s %10s  %s\n", a.Name, common.FormatSize(a.Size), a.ModTime.Format("2006-01-02 15:04"))
+		}
+		fmt.Println(InfoStyle.Render(fmt.Sprintf("\n%d archives, %s in %s", len(archives), common.FormatSize(total), common.ArchiveCacheDir())))
+		return nil
+
+	case "clean":
+		if all {
+			maxSize = 0
+		}
+		removed, err := common.GCArchiveCache(maxSize)
+		if err != nil {
+			return err
+		}
+		var freed int64
+		for _, a := range removed {
+			freed += a.Size
+			fmt.Println(InfoStyle.Render("Removed " + a.Name))
+		}
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Freed %s", common.FormatSize(freed))))
+		return nil
+	}
+	return fmt.Errorf("usage: go-install cache list|clean [--max-size SIZE] [--all]")
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	releaseHistoryURL = "https://go.dev/doc/devel/release"
+	releaseHistoryTTL = 24 * time.Hour
+)
+
+var (
+	// Every release on the history page is a paragraph (patch releases) or
+	// a heading followed by a paragraph (major releases) with the version
+	// as its id.
+	releaseEntryRe = regexp.MustCompile(`(?s)<(?:p|h2) id="(go[0-9][0-9a-z.]*)">(.*?)</(?:p|h2)>(?:\s*<p>(.*?)</p>)?`)
+	tagRe          = regexp.MustCompile(`<[^>]+>`)
+	spaceRe        = regexp.MustCompile(`\s+`)
+)
+
+type releaseNote struct {
+	version common.Version
+	text    string
+}
+
+// releaseHistory returns the go.dev release history page, served from the
+// cache when it is fresh enough.
+func releaseHistory() (string, error) {
+	path := filepath.Join(common.CacheDir(), "release-history.html")
+	if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) < releaseHistoryTTL {
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data), nil
+		}
+	}
+
+	resp, err := http.Get(releaseHistoryURL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("release history: %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err == nil {
+		_ = os.WriteFile(path, data, 0644)
+	}
+	return string(data), nil
+}
+
+func cleanHTML(s string) string {
+	s = tagRe.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	return strings.TrimSpace(spaceRe.ReplaceAllString(s, " "))
+}
+
+func parseReleaseNotes(page string) []releaseNote {
+	var notes []releaseNote
+	for _, m := range releaseEntryRe.FindAllStringSubmatch(page, -1) {
+		v, err := common.ParseVersion(m[1])
+		if err != nil {
+			continue
+		}
+		text := cleanHTML(m[2])
+		if m[3] != "" {
+			text += "\n" + cleanHTML(m[3])
+		}
+		notes = append(notes, releaseNote{version: v, text: text})
+	}
+	return notes
+}
+
+// RunChangelog prints the release notes of every release newer than the
+// active toolchain up to the latest stable one.
+func RunChangelog() error {
+	installed, err := common.ActiveGoVersion()
+	if err != nil {
+		return err
+	}
+	from, err := common.ParseVersion(installed)
+	if err != nil {
+		return err
+	}
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+	latest, err := common.LatestStable(releases)
+	if err != nil {
+		return err
+	}
+	to, err := common.ParseVersion(latest.Version)
+	if err != nil {
+		return err
+	}
+	if !from.Less(to) {
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ %s is the latest stable release", installed)))
+		return nil
+	}
+
+	page, err := releaseHistory()
+	if err != nil {
+		return err
+	}
+
+	var notes []releaseNote
+	for _, n := range parseReleaseNotes(page) {
+		if from.Less(n.version) && !to.Less(n.version) {
+			notes = append(notes, n)
+		}
+	}
+	slices.SortFunc(notes, func(a, b releaseNote) int {
+		if a.version.Less(b.version) {
+			return -1
+		}
+		return 1
+	})
+
+	fmt.Println(TitleStyle.Render(fmt.Sprintf("Changes from %s to %s", installed, latest.Version)))
+	for _, n := range notes {
+		fmt.Println(SuccessStyle.Render(n.version.String()))
+		fmt.Println(n.text)
+		fmt.Println()
+	}
+	if len(notes) == 0 {
+		fmt.Println(InfoStyle.Render("No release notes found, see " + releaseHistoryURL))
+	}
+	return nil
+}
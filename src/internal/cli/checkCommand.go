@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// updateNotice formats the banner shown when a newer release is available.
+func updateNotice(latest, installed string) string {
+	return fmt.Sprintf("Go %s is available (you have %s)",
+		strings.TrimPrefix(latest, "go"), strings.TrimPrefix(installed, "go"))
+}
+
+// checkResult is the --json output of check.
+type checkResult struct {
+	Installed    string `json:"installed"`
+	LatestPatch  string `json:"latest_patch,omitempty"`
+	LatestStable string `json:"latest_stable"`
+	Outdated     bool   `json:"outdated"`
+	// Labels are the labels of this host, see RunLabel.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// metricNameRe matches what a Prometheus label name may not contain.
+var metricNameRe = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeMetrics prints r in the Prometheus text format, for the textfile
+// collector of node_exporter. The host labels become label_KEY labels.
+func (r checkResult) writeMetrics() {
+	keys := make([]string, 0, len(r.Labels))
+	for k := range r.Labels {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var labels strings.Builder
+	fmt.Fprintf(&labels, "version=%q,latest_stable=%q", r.Installed, r.LatestStable)
+	for _, k := range keys {
+		fmt.Fprintf(&labels, ",label_%s=%q", metricNameRe.ReplaceAllString(k, "_"), r.Labels[k])
+	}
+	outdated := 0
+	if r.Outdated {
+		outdated = 1
+	}
+	fmt.Println("# HELP go_install_toolchain_info The active Go toolchain and the labels of this host.")
+	fmt.Println("# TYPE go_install_toolchain_info gauge")
+	fmt.Printf("go_install_toolchain_info{%s} 1\n", labels.String())
+	fmt.Println("# HELP go_install_toolchain_outdated Whether a newer stable Go release exists.")
+	fmt.Println("# TYPE go_install_toolchain_outdated gauge")
+	fmt.Printf("go_install_toolchain_outdated{%s} %d\n", labels.String(), outdated)
+}
+
+// RunCheck compares the active toolchain with the latest patch of its
+// series and the latest stable release, and reports whether a newer stable
+// release exists. asMetrics prints Prometheus metrics instead.
+func RunCheck(asJSON, asMetrics bool) (bool, error) {
+	installed, err := common.ActiveGoVersion()
+	if err != nil {
+		return false, err
+	}
+	releases, err := getReleases()
+	if err != nil {
+		return false, err
+	}
+	latest, err := common.LatestStable(releases)
+	if err != nil {
+		return false, err
+	}
+	result := checkResult{Installed: installed, LatestStable: latest.Version}
+	// Prereleases have no patch releases.
+	if patch, err := common.LatestPatch(releases, installed); err == nil {
+		result.LatestPatch = patch.Version
+	}
+	_, result.Outdated = common.NewerStable(releases, installed)
+	if state, err := common.LoadState(); err == nil {
+		result.Labels = state.Labels
+	}
+
+	switch {
+	case asJSON:
+		return result.Outdated, json.NewEncoder(os.Stdout).Encode(result)
+	case asMetrics:
+		result.writeMetrics()
+		return result.Outdated, nil
+	}
+	fmt.Printf("  %-14s %s\n", "Installed", result.Installed)
+	if result.LatestPatch != "" {
+		fmt.Printf("  %-14s %s\n", "Latest patch", result.LatestPatch)
+	}
+	fmt.Printf("  %-14s %s\n", "Latest stable", result.LatestStable)
+	if len(result.Labels) > 0 {
+		var pairs []string
+		for k, v := range result.Labels {
+			pairs = append(pairs, k+"="+v)
+		}
+		slices.Sort(pairs)
+		fmt.Printf("  %-14s %s\n", "Labels", strings.Join(pairs, " "))
+	}
+	if result.Outdated {
+		fmt.Println(TitleStyle.Render("⬆  " + updateNotice(latest.Version, installed)))
+		return true, nil
+	}
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ %s is the latest stable release", installed)))
+	return false, nil
+}
@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// checkItem is a pipeline step as listed in the install checklist.
+type checkItem struct {
+	step  string
+	label string
+	// status is empty while the step is pending.
+	status            StepStatus
+	started, finished time.Time
+}
+
+// checklist shows every step of an install with its outcome and duration,
+// so a failure is easy to place.
+type checklist []checkItem
+
+// newChecklist lists the steps m will run, in order.
+func newChecklist(m installModel) checklist {
+	var c checklist
+	add := func(step, label string) {
+		c = append(c, checkItem{step: step, label: label})
+	}
+	switch {
+	case !m.opts.runs(StepDownload):
+		add(StepDownload, "Find cached archive")
+	case m.streams():
+		add(StepDownload, "Download, verify and extract archive")
+	default:
+		add(StepDownload, "Download archive")
+	}
+	if !m.streams() {
+		if m.opts.runs(StepVerify) {
+			add(StepVerify, "Verify checksum")
+		}
+		if m.opts.runs(StepExtract) {
+			add(StepExtract, "Extract archive")
+		}
+	}
+	if m.opts.runs(StepReplace) {
+		add(StepReplace, "Replace previous installation")
+	}
+	if m.opts.runsPipeline(StepSmokeTest) {
+		add(StepSmokeTest, "Smoke test go version")
+	}
+	if m.opts.runs(StepConfigure) {
+		add(StepConfigure, "Configure environment")
+	}
+	if m.opts.PostInstallCmd != "" {
+		add(StepPostInstall, "Run post-install command")
+	}
+	return c
+}
+
+// mark records e for its step. Steps the download covered when streaming
+// are not listed, their events are ignored.
+func (c checklist) mark(e StepEvent) {
+	for i := range c {
+		if c[i].step != e.Step {
+			continue
+		}
+		switch e.Status {
+		case StatusStarted:
+			c[i].status, c[i].started = StatusStarted, e.Time
+		case StatusDone, StatusFailed:
+			c[i].status, c[i].finished = e.Status, e.Time
+		}
+	}
+}
+
+// view renders the checklist with spinner in front of the running step and
+// the throughput of its transfer below it.
+func (c checklist) view(spinner string, transfer *transferStats) string {
+	width := 0
+	for _, item := range c {
+		width = max(width, len(item.label))
+	}
+	var sb strings.Builder
+	for _, item := range c {
+		line := fmt.Sprintf("%-*s", width, item.label)
+		switch item.status {
+		case StatusStarted:
+			sb.WriteString(fmt.Sprintf("  %s %s  %s\n", spinner, line, elapsed(time.Since(item.started))))
+			if stats := transfer.line(item.step); stats != "" {
+				sb.WriteString(InfoStyle.Render("    "+stats) + "\n")
+			}
+		case StatusDone:
+			sb.WriteString(SuccessStyle.Render(fmt.Sprintf("  ✓ %s  %s", line, elapsed(item.finished.Sub(item.started)))) + "\n")
+		case StatusFailed:
+			sb.WriteString(ErrorStyle.Render(fmt.Sprintf("  ✗ %s  %s", line, elapsed(item.finished.Sub(item.started)))) + "\n")
+		default:
+			sb.WriteString(mutedStyle.Render("  · "+item.label) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// elapsed formats a step duration with a precision that suits it.
+func elapsed(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("%.1fs", d.Seconds())
+	}
+	return d.Round(time.Second).String()
+}
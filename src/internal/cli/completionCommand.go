@@ -0,0 +1,180 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// commandNames lists the subcommands offered by shell completion.
+var commandNames = []string{"exec", "update", "check", "completion", "self-update", "changelog", "audit", "rollback", "use", "cache", "manifest", "ide", "mirror-compare", "support-bundle", "reinstall", "label", "verify", "doctor", "config", "uninstall", "prune", "du", "platforms", "batch", "fleet", "env-setup"}
+
+var flagNames = []string{"--version", "--yes", "--force", "--allow-system-changes", "--deps", "--no-cgo-deps", "--dependencies", "--skip-deps", "--prefix", "--mirror", "--proxy", "--arch", "--os", "--print-script", "--keep-archive", "--packaged-go", "--alternatives", "--shell-config", "--workspace", "--pin-toolchain", "--no-env", "--heartbeat", "--keep-backups", "--only", "--skip", "--post-install-cmd", "--pre-remove-cmd", "--protect", "--label", "--env-profile", "--tools", "--connections", "--allow-eol", "--prerelease", "--all", "--crash-report", "--json", "--events-fd", "--plain", "--no-altscreen", "-v", "-vv", "--help"}
+
+func completionScript(shell string) (string, error) {
+	words := strings.Join(append(append([]string{}, commandNames...), flagNames...), " ")
+	switch shell {
+	case "bash":
+		return fmt.Sprintf(`# bash completion for go-install
+_go_install() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    if [[ ${COMP_CWORD} -eq 2 && ${COMP_WORDS[1]} == completion ]]; then
+        COMPREPLY=($(compgen -W "bash zsh fish install uninstall" -- "$cur"))
+        return
+    fi
+    COMPREPLY=($(compgen -W "%s" -- "$cur"))
+}
+complete -F _go_install go-install
+`, words), nil
+	case "zsh":
+		return fmt.Sprintf(`#compdef go-install
+# zsh completion for go-install
+_arguments '1: :(%s)' '*:: :_files'
+`, words), nil
+	case "fish":
+		var sb strings.Builder
+		sb.WriteString("# fish completion for go-install\n")
+		sb.WriteString("complete -c go-install -f\n")
+		for _, c := range commandNames {
+			sb.WriteString(fmt.Sprintf("complete -c go-install -n __fish_use_subcommand -a %s\n", c))
+		}
+		for _, f := range flagNames {
+			sb.WriteString(fmt.Sprintf("complete -c go-install -l %s\n", strings.TrimPrefix(f, "--")))
+		}
+		return sb.String(), nil
+	}
+	return "", fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+}
+
+// completionPaths lists the system-wide completion locations per shell,
+// preferring directories that the shell loads without extra configuration.
+var completionPaths = map[string][]string{
+	"bash": {
+		"/usr/share/bash-completion/completions/go-install",
+		"/etc/bash_completion.d/go-install",
+	},
+	"zsh": {
+		"/usr/local/share/zsh/site-functions/_go-install",
+		"/usr/share/zsh/site-functions/_go-install",
+		"/usr/share/zsh/vendor-completions/_go-install",
+	},
+	"fish": {
+		"/usr/share/fish/vendor_completions.d/go-install.fish",
+		"/etc/fish/completions/go-install.fish",
+	},
+}
+
+func completionPath(shell string) (string, error) {
+	candidates, ok := completionPaths[shell]
+	if !ok {
+		return "", fmt.Errorf("unsupported shell %q (supported: bash, zsh, fish)", shell)
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(filepath.Dir(c)); err == nil {
+			return c, nil
+		}
+	}
+	// Nothing exists yet, create the first (most standard) location.
+	return candidates[0], nil
+}
+
+func isCompletionFile(path string) bool {
+	for _, candidates := range completionPaths {
+		if slices.Contains(candidates, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func detectShell() string {
+	return filepath.Base(os.Getenv("SHELL"))
+}
+
+// RunCompletion prints a completion script, or installs/uninstalls it for
+// the detected shell.
+func RunCompletion(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: go-install completion bash|zsh|fish|install [SHELL]|uninstall")
+	}
+
+	switch args[0] {
+	case "install":
+		shell := detectShell()
+		if len(args) > 1 {
+			shell = args[1]
+		}
+		return installCompletion(shell)
+	case "uninstall":
+		return uninstallCompletion()
+	}
+
+	script, err := completionScript(args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Print(script)
+	return nil
+}
+
+func installCompletion(shell string) error {
+	script, err := completionScript(shell)
+	if err != nil {
+		return err
+	}
+	path, err := completionPath(shell)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		return err
+	}
+	if err := os.Chmod(path, 0644); err != nil {
+		return err
+	}
+
+	// Record the file so uninstall can remove it again.
+	state, err := common.LoadState()
+	if err != nil {
+		return err
+	}
+	state.AddFile(path, common.KindCompletion, "created")
+	if err := state.Save(); err != nil {
+		return err
+	}
+
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Installed %s completion to %s", shell, path)))
+	fmt.Println(InfoStyle.Render("Start a new shell to load it."))
+	return nil
+}
+
+func uninstallCompletion() error {
+	state, err := common.LoadState()
+	if err != nil {
+		return err
+	}
+	removed := 0
+	for _, mf := range slices.Clone(state.Files) {
+		f := mf.Path
+		if mf.Kind != common.KindCompletion || !isCompletionFile(f) {
+			continue
+		}
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		state.RemoveFile(f)
+		fmt.Println(InfoStyle.Render("Removed " + f))
+		removed++
+	}
+	if removed == 0 {
+		fmt.Println(InfoStyle.Render("No installed completions found"))
+		return nil
+	}
+	return state.Save()
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+)
+
+// RunConfig prints the effective settings, with the source of each one
+// when origins is set. doctor also validates the configuration and fails
+// when it contains unknown keys or invalid values.
+func RunConfig(cfg *common.Config, origins, doctor bool) error {
+	settings := cfg.Settings()
+	keyWidth, valueWidth := 0, 0
+	for i, s := range settings {
+		if s.Value == "" {
+			settings[i].Value = `""`
+		}
+		keyWidth = max(keyWidth, len(s.Key))
+		valueWidth = max(valueWidth, len(settings[i].Value))
+	}
+	for _, s := range settings {
+		if origins || doctor {
+			fmt.Printf("  %-*s  %-*s  %s\n", keyWidth, s.Key, valueWidth, s.Value, InfoStyle.Render(s.Origin))
+		} else {
+			fmt.Printf("  %-*s  %s\n", keyWidth, s.Key, s.Value)
+		}
+	}
+	if !doctor {
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Println(InfoStyle.Render(fmt.Sprintf("Priority: flags > environment (GO_INSTALL_*) > project (.go-version, go.mod) > %s > %s > defaults",
+		common.UserConfigPath(), common.SystemConfigPath)))
+	if len(cfg.Problems) == 0 {
+		fmt.Println(SuccessStyle.Render("✓ Configuration is valid"))
+		return nil
+	}
+	for _, p := range cfg.Problems {
+		fmt.Println(ErrorStyle.Render("  ✗ " + p))
+	}
+	return fmt.Errorf("%d configuration problems", len(cfg.Problems))
+}
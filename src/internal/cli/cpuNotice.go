@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// rosettaNotice warns that go-install runs translated by Rosetta and
+// installs the native arm64 toolchain instead of the Intel one its own
+// GOARCH suggests.
+func rosettaNotice() string {
+	if !common.Translated() || common.ArchOverridden() {
+		return ""
+	}
+	return "! go-install runs under Rosetta on an Apple Silicon Mac; installing the native darwin/arm64 toolchain. Pass --arch amd64 for the Intel one."
+}
+
+// wslNotice warns that a Windows Go reachable through WSL interop would
+// shadow the Linux toolchain, which the shell configuration then puts first.
+func wslNotice() string {
+	dirs := common.WindowsGoDirs()
+	if len(dirs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("! WSL: a Windows Go is on PATH (%s); the shell configuration puts the Linux toolchain before it.", strings.Join(dirs, ", "))
+}
+
+// amd64Notice warns when binaries built with the configured GOAMD64 level
+// would not run on this CPU, and reminds owners of v1-only CPUs (old Atom,
+// VIA) to keep the v1 default. It returns an empty string when nothing is
+// worth saying.
+func amd64Notice() string {
+	level := common.AMD64Level()
+	if level == 0 {
+		return ""
+	}
+	if want := os.Getenv("GOAMD64"); want != "" {
+		n, err := strconv.Atoi(strings.TrimPrefix(want, "v"))
+		if err == nil && n > level {
+			return fmt.Sprintf("! GOAMD64=%s is set but this CPU only supports x86-64-v%d; binaries built here will not run on it. Use GOAMD64=v%d.", want, level, level)
+		}
+	}
+	if level == 1 {
+		return "! This CPU only supports x86-64-v1. Keep GOAMD64=v1 (the default) and do not copy GOAMD64=v2+ settings from other machines."
+	}
+	return ""
+}
@@ -0,0 +1,299 @@
+package cli
+
+import (
+	"crypto/x509"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+type checkLevel int
+
+const (
+	checkOK checkLevel = iota
+	checkWarn
+	checkFail
+)
+
+// doctorCheck is the outcome of one diagnosis with an optional fix.
+type doctorCheck struct {
+	level checkLevel
+	msg   string
+	fix   string
+}
+
+// staleMarkers identify PATH entries left behind by other Go version
+// managers and package formats.
+var staleMarkers = []string{"/.gvm/", "/.asdf/", "/.goenv/", "/snap/", "/.g/", "/sdk/go"}
+
+// goBinaries returns every go executable reachable through PATH, in the
+// order the shell would try them.
+func goBinaries() []string {
+	return goBinariesIn(filepath.SplitList(os.Getenv("PATH")))
+}
+
+// goBinariesIn returns the go executables in dirs, in order.
+func goBinariesIn(dirs []string) []string {
+	var bins []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		bin := filepath.Join(dir, "go")
+		if fi, err := os.Stat(bin); err == nil && !fi.IsDir() && fi.Mode()&0111 != 0 {
+			dup := false
+			for _, b := range bins {
+				if common.SamePath(b, bin) {
+					dup = true
+				}
+			}
+			if !dup {
+				bins = append(bins, bin)
+			}
+		}
+	}
+	return bins
+}
+
+func goEnv(bin, name string) string {
+	out, err := exec.Command(bin, "env", name).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func checkGoOnPath() []doctorCheck {
+	managedBin := filepath.Join(common.GoRoot, "bin", "go")
+	bins := goBinaries()
+	if len(bins) == 0 {
+		return []doctorCheck{{checkFail, "go is not on PATH",
+			fmt.Sprintf("add %s to PATH, e.g. export PATH=$PATH:%s", filepath.Dir(managedBin), filepath.Dir(managedBin))}}
+	}
+
+	var checks []doctorCheck
+	winner := bins[0]
+	if common.SamePath(winner, managedBin) {
+		checks = append(checks, doctorCheck{checkOK, "go on PATH resolves to " + winner, ""})
+	} else {
+		checks = append(checks, doctorCheck{checkWarn,
+			fmt.Sprintf("go on PATH resolves to %s, not the managed %s", winner, managedBin),
+			fmt.Sprintf("put %s before %s in PATH or remove the other installation", filepath.Dir(managedBin), filepath.Dir(winner))})
+	}
+	for _, b := range bins[1:] {
+		checks = append(checks, doctorCheck{checkWarn, "another go is shadowed on PATH: " + b,
+			"remove it or its PATH entry to avoid confusion"})
+	}
+	return checks
+}
+
+func checkGoEnv() []doctorCheck {
+	var checks []doctorCheck
+	bin := filepath.Join(common.GoRoot, "bin", "go")
+	if bins := goBinaries(); len(bins) > 0 {
+		bin = bins[0]
+	}
+
+	if env := os.Getenv("GOROOT"); env != "" {
+		actual := filepath.Dir(filepath.Dir(bin))
+		if !common.SamePath(env, actual) {
+			checks = append(checks, doctorCheck{checkFail,
+				fmt.Sprintf("GOROOT=%s does not match the go binary on PATH (%s)", env, actual),
+				"unset GOROOT, go finds its own root since Go 1.10"})
+		} else {
+			checks = append(checks, doctorCheck{checkWarn, "GOROOT is set explicitly",
+				"unset GOROOT so switching toolchains cannot leave it stale"})
+		}
+	}
+
+	goroot := goEnv(bin, "GOROOT")
+	gopath := goEnv(bin, "GOPATH")
+	for _, p := range filepath.SplitList(gopath) {
+		if goroot != "" && (common.SamePath(p, goroot) || strings.HasPrefix(p+"/", goroot+"/")) {
+			checks = append(checks, doctorCheck{checkFail,
+				fmt.Sprintf("GOPATH %s is inside GOROOT %s", p, goroot),
+				"point GOPATH at a separate directory such as $HOME/go"})
+		}
+	}
+	if gopath != "" && len(checks) == 0 {
+		checks = append(checks, doctorCheck{checkOK, fmt.Sprintf("GOROOT %s and GOPATH %s are consistent", goroot, gopath), ""})
+	}
+	return checks
+}
+
+func checkStalePath() []doctorCheck {
+	// GOPATH/bin only appears after the first go install, so it is not
+	// stale when missing.
+	gopathBin := ""
+	if gopath := goEnv(filepath.Join(common.GoRoot, "bin", "go"), "GOPATH"); gopath != "" {
+		gopathBin = filepath.Join(filepath.SplitList(gopath)[0], "bin")
+	}
+	var checks []doctorCheck
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		for _, marker := range staleMarkers {
+			if strings.Contains(dir+"/", marker) {
+				checks = append(checks, doctorCheck{checkWarn, "PATH contains a Go version manager entry: " + dir,
+					"remove it from your shell configuration if you no longer use that tool"})
+				break
+			}
+		}
+		if _, err := os.Stat(dir); os.IsNotExist(err) && strings.Contains(strings.ToLower(dir), "go") && dir != gopathBin {
+			checks = append(checks, doctorCheck{checkWarn, "PATH contains a missing directory: " + dir,
+				"remove the stale entry from your shell configuration"})
+		}
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{checkOK, "no stale Go entries on PATH", ""})
+	}
+	return checks
+}
+
+// checkDuplicateInstalls looks for Go toolchains outside of PATH in the
+// places distributions and other installers use.
+func checkDuplicateInstalls() []doctorCheck {
+	var checks []doctorCheck
+	candidates, _ := filepath.Glob("/usr/lib/go*/bin/go")
+	more, _ := filepath.Glob("/opt/go*/bin/go")
+	candidates = append(candidates, more...)
+	candidates = append(candidates, "/usr/bin/go", "/snap/bin/go", "/usr/local/bin/go")
+	if home, err := common.HomeDir(); err == nil {
+		more, _ = filepath.Glob(filepath.Join(home, "sdk", "go*", "bin", "go"))
+		candidates = append(candidates, more...)
+	}
+	managed := filepath.Join(common.GoRoot, "bin", "go")
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err != nil || common.SamePath(c, managed) {
+			continue
+		}
+		checks = append(checks, doctorCheck{checkWarn, "another Go installation exists: " + c,
+			"remove it with your package manager unless you need it"})
+	}
+	return checks
+}
+
+func checkShellConfig() []doctorCheck {
+	account, err := common.InvokingUser()
+	home := account.Home
+	if err != nil {
+		return []doctorCheck{{checkWarn, "no home directory, shell configuration not checked", ""}}
+	}
+	bin := filepath.Join(common.GoRoot, "bin")
+	var checks []doctorCheck
+	configured := false
+	for _, name := range []string{".profile", ".bash_profile", ".bashrc", ".zshrc", ".config/fish/config.fish"} {
+		path := filepath.Join(home, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		n := 0
+		for _, e := range common.ShellPathEntries(string(data), home) {
+			if common.SamePath(e, bin) {
+				n++
+			}
+		}
+		if n > 0 {
+			configured = true
+		}
+		if n > 1 {
+			checks = append(checks, doctorCheck{checkWarn, fmt.Sprintf("%s adds %s to PATH %d times", path, bin, n),
+				"remove the duplicate lines"})
+		}
+	}
+	if !configured {
+		checks = append(checks, doctorCheck{checkFail, "no shell configuration adds " + bin + " to PATH",
+			"run go-install again or add export PATH=$PATH:" + bin + " to your shell configuration"})
+	} else if len(checks) == 0 {
+		checks = append(checks, doctorCheck{checkOK, "shell configuration adds " + bin + " to PATH", ""})
+	}
+	return checks
+}
+
+func checkCertificates() []doctorCheck {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil || len(pool.Subjects()) == 0 {
+		return []doctorCheck{{checkFail, "no CA certificates found, HTTPS downloads will fail",
+			"install the ca-certificates package"}}
+	}
+	return []doctorCheck{{checkOK, "CA certificates available", ""}}
+}
+
+func checkCPU() []doctorCheck {
+	if common.Translated() {
+		return []doctorCheck{{checkWarn, "go-install runs under Rosetta, Go toolchains built for amd64 run translated",
+			"install the native darwin/arm64 build of go-install"}}
+	}
+	if notice := amd64Notice(); notice != "" {
+		return []doctorCheck{{checkWarn, strings.TrimPrefix(notice, "! "), ""}}
+	}
+	if level := common.AMD64Level(); level > 0 {
+		return []doctorCheck{{checkOK, fmt.Sprintf("CPU supports x86-64-v%d", level), ""}}
+	}
+	return nil
+}
+
+func checkWSL() []doctorCheck {
+	if !common.IsWSL() {
+		return nil
+	}
+	var checks []doctorCheck
+	for _, dir := range common.WindowsGoDirs() {
+		checks = append(checks, doctorCheck{checkWarn, "a Windows Go is on PATH through WSL interop: " + dir,
+			"put the Linux toolchain first with: " + common.ExportLine()})
+	}
+	if len(checks) == 0 {
+		checks = append(checks, doctorCheck{checkOK, "running in WSL, no Windows Go on PATH", ""})
+	}
+	return checks
+}
+
+// RunDoctor diagnoses the Go setup of the current user and suggests fixes.
+// It fails when any check found a problem that breaks the toolchain.
+func RunDoctor() error {
+	sections := []struct {
+		title  string
+		checks func() []doctorCheck
+	}{
+		{"go on PATH", checkGoOnPath},
+		{"GOROOT and GOPATH", checkGoEnv},
+		{"PATH entries", checkStalePath},
+		{"Other installations", checkDuplicateInstalls},
+		{"Shell configuration", checkShellConfig},
+		{"Certificates", checkCertificates},
+		{"CPU", checkCPU},
+		{"WSL", checkWSL},
+	}
+
+	failed := 0
+	for _, s := range sections {
+		checks := s.checks()
+		if len(checks) == 0 {
+			continue
+		}
+		fmt.Println(TitleStyle.UnsetMarginBottom().Render(s.title))
+		for _, c := range checks {
+			switch c.level {
+			case checkOK:
+				fmt.Println(SuccessStyle.Render("  ✓ " + c.msg))
+			case checkWarn:
+				fmt.Println(InfoStyle.Render("  ! " + c.msg))
+			case checkFail:
+				failed++
+				fmt.Println(ErrorStyle.Render("  ✗ " + c.msg))
+			}
+			if c.fix != "" {
+				fmt.Println("    → " + c.fix)
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d problems found", failed)
+	}
+	return nil
+}
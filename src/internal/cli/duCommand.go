@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// RunDu reports the disk space taken by the installed toolchains, the
+// backups and the archive cache.
+func RunDu() error {
+	var total int64
+	row := func(name, path string, size int64) {
+		total += size
+		fmt.Printf("  %-24s %10s  %s\n", name, common.FormatSize(size), path)
+	}
+
+	active, _ := common.InstalledVersion(common.GoRoot)
+	fmt.Println(TitleStyle.Render("Toolchains"))
+	if version, ok := common.LegacyInstall(); ok {
+		row(version+" (active)", common.GoRoot, common.DirSize(common.GoRoot))
+	}
+	for _, v := range common.StoredVersions() {
+		name := v
+		if v == active {
+			name += " (active)"
+		}
+		root := common.VersionRoot(v)
+		row(name, root, common.DirSize(root))
+	}
+
+	backups, err := common.ListBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) > 0 {
+		fmt.Println(TitleStyle.Render("Backups"))
+		for _, b := range backups {
+			row(b.Version+" "+b.Created.Format("2006-01-02"), b.Path, common.DirSize(b.Path))
+		}
+	}
+
+	archives, err := common.ListCachedArchives()
+	unreadable := os.IsPermission(err)
+	if err != nil && !unreadable {
+		return err
+	}
+	var cached int64
+	for _, a := range archives {
+		cached += a.Size
+	}
+	fmt.Println(TitleStyle.Render("Cache"))
+	if unreadable {
+		// Installs use root's cache, which the invoking user cannot read.
+		fmt.Printf("  %-24s %10s  %s\n", "not readable, run as root", "?", common.ArchiveCacheDir())
+	} else {
+		row(fmt.Sprintf("%d archives", len(archives)), common.ArchiveCacheDir(), cached)
+	}
+
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("\nTotal %s", common.FormatSize(total))))
+	fmt.Println(InfoStyle.Render("go-install prune removes old toolchains, backups and archives."))
+	return nil
+}
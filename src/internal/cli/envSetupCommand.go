@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// goCommand runs the installed go binary as account, with the toolchain
+// first on PATH and toolchain switching disabled.
+func goCommand(account common.Account, args ...string) *exec.Cmd {
+	cmd := account.Command(filepath.Join(common.GoRoot, "bin", "go"), args...)
+	if cmd.Env == nil {
+		cmd.Env = os.Environ()
+	}
+	cmd.Env = append(cmd.Env,
+		"GOROOT="+common.GoRoot,
+		"PATH="+filepath.Join(common.GoRoot, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GOTOOLCHAIN=local",
+	)
+	return cmd
+}
+
+// pinToolchain writes GOTOOLCHAIN=local to account's go env file.
+func pinToolchain(account common.Account) error {
+	if out, err := goCommand(account, "env", "-w", common.ToolchainLocal).CombinedOutput(); err != nil {
+		return fmt.Errorf("go env -w %s: %w: %s", common.ToolchainLocal, err, strings.TrimSpace(string(out)))
+	}
+	common.Log.Info("go env -w", "vars", common.ToolchainLocal)
+	return nil
+}
+
+// toolchainPinned reports whether account's go env file sets GOTOOLCHAIN.
+func toolchainPinned(account common.Account) bool {
+	out, err := goCommand(account, "env", "GOENV").Output()
+	if err != nil {
+		return false
+	}
+	data, err := os.ReadFile(strings.TrimSpace(string(out)))
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(line, "GOTOOLCHAIN=") {
+			return true
+		}
+	}
+	return false
+}
+
+// RunEnvSetup writes the variables of the env profile name with go env -w,
+// as the invoking user so they land in their go env file.
+func RunEnvSetup(name string, vars map[string]string) error {
+	if len(vars) == 0 {
+		return fmt.Errorf("env profile %q sets no variables", name)
+	}
+	if _, err := os.Stat(filepath.Join(common.GoRoot, "bin", "go")); err != nil {
+		return fmt.Errorf("no Go installation in %s: %w", common.GoRoot, err)
+	}
+	account, err := common.InvokingUser()
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(vars))
+	for key := range vars {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	args := []string{"env", "-w"}
+	for _, key := range keys {
+		args = append(args, key+"="+vars[key])
+	}
+
+	common.Log.Info("go env -w", "profile", name, "vars", args[2:])
+	if out, err := goCommand(account, args...).CombinedOutput(); err != nil {
+		common.Log.Error("go env -w failed", "profile", name, "output", string(out))
+		return fmt.Errorf("env profile %q: %w: %s", name, err, strings.TrimSpace(string(out)))
+	}
+	for _, arg := range args[2:] {
+		fmt.Println(SuccessStyle.Render("✓ go env -w " + arg))
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-installer/common"
+	"go-installer/pkg/installer"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StepStatus is the stage of a pipeline step a StepEvent reports.
+type StepStatus string
+
+const (
+	StatusStarted  StepStatus = "started"
+	StatusProgress StepStatus = "progress"
+	StatusDone     StepStatus = "done"
+	StatusFailed   StepStatus = "failed"
+)
+
+// Steps reported in events besides the selectable pipeline steps.
+const (
+	StepDependencies = "dependencies"
+	StepReleases     = "releases"
+	StepPostInstall  = "post-install"
+	// StepSummary reports what the install is about to change.
+	StepSummary = "summary"
+	// StepTool installs the developer tool named by StepEvent.Tool.
+	StepTool = "tool"
+	// StepSmokeTest runs the new toolchain after the replace step.
+	StepSmokeTest = installer.StepSmokeTest
+	// StepInstall reports the overall result.
+	StepInstall = "install"
+)
+
+// EventSchema is the version of the StepEvent JSON encoding. Fields are only
+// ever added within a version; renaming or removing one bumps it.
+const EventSchema = 1
+
+// StepEvent is the only message pipeline steps send. The install model acts
+// on finished steps and forwards every event to the sinks in Options.Sinks,
+// so the UI and other consumers see the same stream.
+type StepEvent struct {
+	Schema int        `json:"schema"`
+	Step   string     `json:"step"`
+	Status StepStatus `json:"status"`
+	// Bytes and Total describe the progress of transfers.
+	Bytes int64 `json:"bytes,omitempty"`
+	Total int64 `json:"total,omitempty"`
+	// Percent is derived from Bytes and Total when the total is known.
+	Percent float64 `json:"percent,omitempty"`
+	// Version is the Go version the overall result is about.
+	Version string `json:"version,omitempty"`
+	// Summary maps the rows of the pre-install summary, e.g. "install_to",
+	// to their values.
+	Summary map[string]string `json:"summary,omitempty"`
+	// Tool is the developer tool a tool step installs.
+	Tool  string    `json:"tool,omitempty"`
+	Error string    `json:"error,omitempty"`
+	Time  time.Time `json:"time"`
+
+	err error
+	// rows keep the order of Summary for plain output.
+	rows []summaryRow
+	// result is what a finished step hands on to the following ones.
+	result stepResult
+}
+
+// Err returns the error a failed step ended with.
+func (e StepEvent) Err() error { return e.err }
+
+type stepResult struct {
+	// archive is the verified or to be verified release archive.
+	archive string
+	sha256  string
+	// dir holds the extracted toolchain, streamed is set when the download
+	// step extracted it already.
+	dir      string
+	streamed bool
+	// config is the shell configuration change that was made, or
+	// configSkip the reason none was.
+	config     common.ShellChange
+	configSkip string
+	// links are the symlinks created instead of a shell configuration
+	// change.
+	links []string
+	// pinnedEnv is set when GOTOOLCHAIN=local was written with go env -w.
+	pinnedEnv bool
+	undo      *undoAction
+}
+
+// EventSink receives step events. Progress events are delivered from the
+// goroutine running the step, so sinks must be safe for concurrent use.
+type EventSink func(StepEvent)
+
+func stepStarted(step string) StepEvent {
+	return StepEvent{Step: step, Status: StatusStarted}
+}
+
+func stepDone(step string, r stepResult) StepEvent {
+	return StepEvent{Step: step, Status: StatusDone, result: r}
+}
+
+func stepFailed(step string, err error) StepEvent {
+	return StepEvent{Step: step, Status: StatusFailed, err: err}
+}
+
+// publish timestamps e and hands it to every sink.
+func publish(sinks []EventSink, e StepEvent) {
+	switch e.Status {
+	case StatusFailed:
+		common.Log.Error("step failed", "step", e.Step, "err", e.err)
+	case StatusStarted, StatusDone:
+		common.Log.Debug("step "+string(e.Status), "step", e.Step, "version", e.Version)
+	}
+	if len(sinks) == 0 {
+		return
+	}
+	e.Schema = EventSchema
+	e.Time = time.Now()
+	if e.Total > 0 {
+		e.Percent = math.Round(float64(e.Bytes)*1000/float64(e.Total)) / 10
+	}
+	if e.err != nil {
+		e.Error = e.err.Error()
+	}
+	for _, s := range sinks {
+		s(e)
+	}
+}
+
+// progressInterval limits how often transfers report progress.
+const progressInterval = 250 * time.Millisecond
+
+// progressWriter publishes progress events for the bytes written to it.
+type progressWriter struct {
+	step  string
+	total int64
+	sinks []EventSink
+
+	// stats, if set, also count the bytes for the installing view.
+	stats *transferStats
+
+	mu   sync.Mutex
+	n    int64
+	last time.Time
+}
+
+func newProgressWriter(step string, total int64, sinks []EventSink) *progressWriter {
+	return &progressWriter{step: step, total: total, sinks: sinks}
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	if w.stats != nil {
+		w.stats.add(len(p))
+	}
+	w.mu.Lock()
+	w.n += int64(len(p))
+	report := time.Since(w.last) >= progressInterval || w.n == w.total
+	if report {
+		w.last = time.Now()
+	}
+	n := w.n
+	w.mu.Unlock()
+
+	if report {
+		publish(w.sinks, StepEvent{Step: w.step, Status: StatusProgress, Bytes: n, Total: w.total})
+	}
+	return len(p), nil
+}
+
+// NewJSONSink writes every event to w as a line of JSON.
+func NewJSONSink(w io.Writer) EventSink {
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	return func(e StepEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		enc.Encode(e)
+	}
+}
+
+// PublishResult reports how the program ended with model m, or with runErr
+// when the program itself failed.
+func PublishResult(sinks []EventSink, m tea.Model, runErr error) {
+	e := StepEvent{Step: StepInstall, Status: StatusDone}
+	err := runErr
+	switch m := m.(type) {
+	case preInstallModel:
+		e.Version = m.selectedVer
+		if err == nil {
+			err = m.err
+		}
+		if err == nil && m.aborted {
+			err = fmt.Errorf("installation %w", common.ErrCancelled)
+		}
+	case installModel:
+		e.Version = m.version
+		if err == nil {
+			err = m.err
+		}
+		if err == nil {
+			err = m.postErr
+		}
+	}
+	if err != nil {
+		e.Status = StatusFailed
+		e.err = err
+	}
+	publish(sinks, e)
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-installer/common"
+	"go-installer/pkg/installer"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// RunExec runs command with GOROOT and PATH pointing at the given version
+// from the versions store, installing it first when it is missing. The
+// globally active toolchain is left untouched. It returns the exit code of
+// the command. heartbeat sets how often progress lines are printed during
+// long steps.
+func RunExec(version string, command []string, heartbeat time.Duration) (int, error) {
+	if len(command) == 0 {
+		return 1, fmt.Errorf("no command given")
+	}
+	version = common.NormalizeVersion(version)
+	root := common.VersionRoot(version)
+
+	// A legacy single-directory install of the same version is used as is
+	// instead of downloading it again.
+	if legacy, ok := common.LegacyInstall(); ok && legacy == version {
+		root = common.GoRoot
+	} else if !common.IsStored(version) {
+		if os.Geteuid() != 0 && common.NeedsRoot() {
+			return 1, fmt.Errorf("%s is not installed, run as root to install it", version)
+		}
+		if err := installVersion(version, heartbeat); err != nil {
+			return 1, err
+		}
+	}
+
+	cmd := common.Command(command[0], command[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		"GOROOT="+root,
+		"PATH="+filepath.Join(root, "bin")+string(os.PathListSeparator)+os.Getenv("PATH"),
+		"GOTOOLCHAIN=local",
+	)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), nil
+		}
+		return 1, err
+	}
+	return 0, nil
+}
+
+// installVersion downloads, verifies and extracts a version into the
+// versions store without any interactive UI.
+func installVersion(version string, heartbeat time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+	return installInto(ctx, releases, version, filepath.Join(common.VersionsDir, version), heartbeat)
+}
+
+// installInto downloads, verifies and extracts a version into prefix/go,
+// replacing what is there, without any interactive UI.
+func installInto(ctx context.Context, releases []common.GoRelease, version, prefix string, heartbeat time.Duration) error {
+	_, file, _, err := common.FindBuild(releases, version, common.GetOS(), common.GetArch())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Downloading and extracting "+file+"..."))
+	counter := &byteCounter{}
+	stopBeat := startHeartbeat(os.Stderr, heartbeat, "installing "+file, counter)
+	result, err := installer.Install(ctx, installer.Options{
+		Version: version,
+		Dir:     prefix,
+		Progress: func(e installer.Event) {
+			if e.Status == installer.StatusProgress && e.Step == installer.StepDownload {
+				counter.n.Store(e.Bytes)
+			}
+		},
+	})
+	stopBeat()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(os.Stderr, SuccessStyle.Render("✓ Installed "+version+" to "+result.GoRoot))
+	return nil
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"errors"
+	"go-installer/common"
+	"os/exec"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ExitCode returns the process exit code for the model a program ended
+// with, see common.ExitCodeFor.
+func ExitCode(m tea.Model) int {
+	switch m := m.(type) {
+	case preInstallModel:
+		if m.aborted {
+			return common.ExitAborted
+		}
+		return common.ExitCodeFor(m.err)
+	case installModel:
+		if m.err != nil {
+			return common.ExitCodeFor(m.err)
+		}
+		if m.postErr != nil {
+			var exitErr *exec.ExitError
+			if errors.As(m.postErr, &exitErr) {
+				return exitErr.ExitCode()
+			}
+			return common.ExitFailure
+		}
+	}
+	return common.ExitOK
+}
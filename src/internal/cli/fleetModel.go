@@ -0,0 +1,459 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"go-installer/common"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sshOptions keep ssh from prompting, which would hang the TUI.
+var sshOptions = []string{"-o", "BatchMode=yes", "-o", "ConnectTimeout=15"}
+
+// unameOS and unameArch map `uname -s` and `uname -m` to release archive
+// names. 32-bit ARM keeps its variant, FindBuild picks armv6l for it.
+var (
+	unameOS   = map[string]string{"Linux": "linux", "Darwin": "darwin", "FreeBSD": "freebsd"}
+	unameArch = map[string]string{
+		"x86_64": "amd64", "amd64": "amd64", "i386": "386", "i686": "386",
+		"aarch64": "arm64", "arm64": "arm64", "riscv64": "riscv64",
+		"ppc64le": "ppc64le", "s390x": "s390x", "loongarch64": "loong64",
+	}
+)
+
+// fleetHost is the progress of one host.
+type fleetHost struct {
+	name     string
+	platform string
+	status   string
+	done     bool
+	err      error
+}
+
+type fleetProbedMsg struct {
+	index      int
+	goos, arch string
+	root       bool
+	err        error
+}
+
+type fleetDoneMsg struct {
+	index  int
+	output string
+	err    error
+}
+
+type fleetModel struct {
+	ctx      context.Context
+	cancel   context.CancelFunc
+	version  string
+	releases []common.GoRelease
+	keep     bool
+	hosts    []fleetHost
+	pending  int
+	archives *archiveFetcher
+	table    table.Model
+	spinner  spinner.Model
+}
+
+// readHosts reads one SSH destination per line, skipping blank lines and
+// # comments. A destination starting with - would be taken for an ssh
+// option and is rejected.
+func readHosts(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var hosts []string
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "-") {
+			return nil, fmt.Errorf("%s:%d: host %q must not start with -", path, n, line)
+		}
+		if line != "" {
+			hosts = append(hosts, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("%s lists no hosts", path)
+	}
+	return hosts, nil
+}
+
+// shellQuote quotes s for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// probeHost detects the platform of host and whether its SSH user is root.
+func probeHost(ctx context.Context, index int, host string) tea.Cmd {
+	return func() tea.Msg {
+		args := append(append([]string{}, sshOptions...), "--", host, "uname -sm; id -u")
+		out, err := common.CommandContext(ctx, "ssh", args...).Output()
+		if err != nil {
+			return fleetProbedMsg{index: index, err: fmt.Errorf("ssh: %w", err)}
+		}
+		lines := strings.Fields(string(out))
+		if len(lines) != 3 {
+			return fleetProbedMsg{index: index, err: fmt.Errorf("unexpected uname output %q", strings.TrimSpace(string(out)))}
+		}
+		goos, arch := unameOS[lines[0]], unameArch[lines[1]]
+		if strings.HasPrefix(lines[1], "armv") {
+			arch = lines[1]
+		}
+		if goos == "" || arch == "" {
+			return fleetProbedMsg{index: index, err: fmt.Errorf("unsupported platform %s %s", lines[0], lines[1])}
+		}
+		return fleetProbedMsg{index: index, goos: goos, arch: arch, root: lines[2] == "0"}
+	}
+}
+
+// archiveFetcher downloads each archive once, however many hosts need it.
+type archiveFetcher struct {
+	mu    sync.Mutex
+	files map[string]*sync.Once
+	paths map[string]string
+	errs  map[string]error
+
+	// tmpDir is the private directory of the archives a run does not
+	// keep.
+	tmpDir string
+}
+
+// get returns a verified local copy of file, from the archive cache when
+// keep is set and a temporary file otherwise.
+func (a *archiveFetcher) get(ctx context.Context, file, sha string, keep bool) (string, error) {
+	a.mu.Lock()
+	once, ok := a.files[file]
+	if !ok {
+		once = &sync.Once{}
+		a.files[file] = once
+	}
+	a.mu.Unlock()
+
+	once.Do(func() {
+		path := common.CachedArchivePath(file, sha)
+		if !keep {
+			dir, err := a.privateDir()
+			if err != nil {
+				a.set(file, "", err)
+				return
+			}
+			path = filepath.Join(dir, file)
+		}
+		if keep && common.VerifyChecksum(path, sha) == nil {
+			a.set(file, path, nil)
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			a.set(file, "", err)
+			return
+		}
+		if err := common.DownloadFile(ctx, file, path, nil); err != nil {
+			a.set(file, "", err)
+			return
+		}
+		if err := common.VerifyChecksum(path, sha); err != nil {
+			os.Remove(path)
+			a.set(file, "", err)
+			return
+		}
+		a.set(file, path, nil)
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.paths[file], a.errs[file]
+}
+
+func (a *archiveFetcher) set(file, path string, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.paths[file], a.errs[file] = path, err
+}
+
+// privateDir returns the temporary directory for archives that are not
+// kept, created on first use. Only the current user can access it, so
+// other local users cannot plant or swap an archive.
+func (a *archiveFetcher) privateDir() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.tmpDir == "" {
+		dir, err := os.MkdirTemp("", "go-install-fleet-")
+		if err != nil {
+			return "", err
+		}
+		a.tmpDir = dir
+	}
+	return a.tmpDir, nil
+}
+
+// cleanup removes the temporary archives of a run that does not keep them.
+func (a *archiveFetcher) cleanup(keep bool) {
+	if keep || a.tmpDir == "" {
+		return
+	}
+	os.RemoveAll(a.tmpDir)
+}
+
+// remoteInstallScript extracts the archive read from stdin next to
+// PREFIX/go and swaps it in, so a failed transfer leaves the old toolchain.
+// Like installer.Replace, the old toolchain is moved aside first and put
+// back when the new one cannot be moved in or does not run.
+const remoteInstallScript = `set -e
+p=%s
+mkdir -p "$p"
+t=$(mktemp -d "$p/.go-install.XXXXXX")
+trap 'rm -rf "$t"' EXIT
+tar -C "$t" -xzf -
+if [ -e "$p/go" ] || [ -L "$p/go" ]; then
+	mv "$p/go" "$t/old"
+fi
+if ! mv "$t/go" "$p/go" || ! "$p/go/bin/go" version; then
+	rm -rf "$p/go"
+	if [ -e "$t/old" ] || [ -L "$t/old" ]; then
+		mv "$t/old" "$p/go"
+	fi
+	exit 1
+fi`
+
+// installHost streams the archive for the probed platform to host and
+// extracts it into the install prefix there.
+func (m fleetModel) installHost(msg fleetProbedMsg) tea.Cmd {
+	host := m.hosts[msg.index].name
+	return func() tea.Msg {
+		_, file, sha, err := common.FindBuild(m.releases, m.version, msg.goos, msg.arch)
+		if err != nil {
+			return fleetDoneMsg{index: msg.index, err: err}
+		}
+		path, err := m.archives.get(m.ctx, file, sha, m.keep)
+		if err != nil {
+			return fleetDoneMsg{index: msg.index, err: err}
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return fleetDoneMsg{index: msg.index, err: err}
+		}
+		defer f.Close()
+		// Check the open file itself, so what is uploaded is exactly
+		// what was verified even if the path is replaced meanwhile.
+		if err := common.VerifyReader(f, sha); err != nil {
+			return fleetDoneMsg{index: msg.index, err: err}
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return fleetDoneMsg{index: msg.index, err: err}
+		}
+
+		remote := "sh -c " + shellQuote(fmt.Sprintf(remoteInstallScript, shellQuote(common.InstallPrefix)))
+		if !msg.root {
+			remote = "sudo -n " + remote
+		}
+		args := append(append([]string{}, sshOptions...), "--", host, remote)
+		cmd := common.CommandContext(m.ctx, "ssh", args...)
+		cmd.Stdin = f
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			last := strings.TrimSpace(string(out))
+			if i := strings.LastIndex(last, "\n"); i >= 0 {
+				last = last[i+1:]
+			}
+			return fleetDoneMsg{index: msg.index, err: fmt.Errorf("%w: %s", err, last)}
+		}
+		return fleetDoneMsg{index: msg.index, output: strings.TrimSpace(string(out))}
+	}
+}
+
+func newFleetModel(version string, releases []common.GoRelease, hosts []string, keep bool) fleetModel {
+	s := newSpinner()
+
+	width := 20
+	for _, h := range hosts {
+		width = max(width, len(h)+2)
+	}
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Host", Width: width},
+			{Title: "Platform", Width: 14},
+			{Title: "Status", Width: 48},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(min(len(hosts)+1, 16)),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m := fleetModel{
+		ctx:      ctx,
+		cancel:   cancel,
+		version:  version,
+		releases: releases,
+		keep:     keep,
+		pending:  len(hosts),
+		archives: &archiveFetcher{files: map[string]*sync.Once{}, paths: map[string]string{}, errs: map[string]error{}},
+		table:    t,
+		spinner:  s,
+	}
+	for _, h := range hosts {
+		m.hosts = append(m.hosts, fleetHost{name: h, status: "connecting..."})
+	}
+	m.table.SetRows(m.rows())
+	return m
+}
+
+func (m fleetModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for i, h := range m.hosts {
+		cmds = append(cmds, probeHost(m.ctx, i, h.name))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m fleetModel) rows() []table.Row {
+	rows := make([]table.Row, 0, len(m.hosts))
+	for _, h := range m.hosts {
+		rows = append(rows, table.Row{h.name, h.platform, h.status})
+	}
+	return rows
+}
+
+func (m fleetModel) finish(index int, status string, err error) (tea.Model, tea.Cmd) {
+	h := &m.hosts[index]
+	h.done, h.err, h.status = true, err, status
+	if err != nil {
+		h.status = "✗ " + err.Error()
+	}
+	m.pending--
+	m.table.SetRows(m.rows())
+	if m.pending == 0 {
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m fleetModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if key := msg.String(); key == "q" || key == "ctrl+c" || key == "esc" {
+			m.cancel()
+			return m, tea.Quit
+		}
+	case interruptMsg:
+		m.cancel()
+		return m, tea.Quit
+	case fleetProbedMsg:
+		if msg.err != nil {
+			return m.finish(msg.index, "", msg.err)
+		}
+		h := &m.hosts[msg.index]
+		h.platform = msg.goos + "/" + msg.arch
+		h.status = "installing..."
+		m.table.SetRows(m.rows())
+		return m, m.installHost(msg)
+	case fleetDoneMsg:
+		return m.finish(msg.index, "✓ "+msg.output, msg.err)
+	case spinner.TickMsg:
+		if m.pending == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m fleetModel) failed() int {
+	n := 0
+	for _, h := range m.hosts {
+		if !h.done || h.err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (m fleetModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(TitleStyle.Render(fmt.Sprintf("Installing %s into %s on %d hosts", m.version, filepath.Join(common.InstallPrefix, "go"), len(m.hosts))) + "\n")
+	sb.WriteString(m.table.View() + "\n")
+	switch {
+	case m.pending > 0:
+		sb.WriteString(fmt.Sprintf("%s %d of %d hosts done\n", m.spinner.View(), len(m.hosts)-m.pending, len(m.hosts)))
+		sb.WriteString(InfoStyle.Render("↑/↓ scroll • q cancel") + "\n")
+	case m.failed() == 0:
+		sb.WriteString(SuccessStyle.Render(fmt.Sprintf("✓ Installed on all %d hosts", len(m.hosts))) + "\n")
+	default:
+		sb.WriteString(ErrorStyle.Render(fmt.Sprintf("✗ %d of %d hosts failed", m.failed(), len(m.hosts))) + "\n")
+	}
+	return sb.String()
+}
+
+// RunFleet installs version, or the latest stable release when empty, on
+// every host listed in hostsFile over SSH. Each host gets the archive of
+// its own platform, downloaded once and streamed from this machine, into
+// the configured prefix. keepArchives keeps the archives in the cache.
+func RunFleet(hostsFile, version string, keepArchives bool) error {
+	hosts, err := readHosts(hostsFile)
+	if err != nil {
+		return err
+	}
+	if _, err := common.Command("ssh", "-V").CombinedOutput(); err != nil {
+		return fmt.Errorf("fleet needs the ssh client: %w", err)
+	}
+	fmt.Println(InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		latest, err := common.LatestStable(releases)
+		if err != nil {
+			return err
+		}
+		version = latest.Version
+	}
+	version = common.NormalizeVersion(version)
+	if !hasVersion(releases, version) {
+		return fmt.Errorf("%w: %s", common.ErrVersionNotFound, version)
+	}
+
+	final, err := NewProgram(newFleetModel(version, releases, hosts, keepArchives)).Run()
+	if err != nil {
+		return err
+	}
+	m := final.(fleetModel)
+	m.archives.cleanup(keepArchives)
+	if m.pending > 0 {
+		return fmt.Errorf("fleet install %w", common.ErrCancelled)
+	}
+	if n := m.failed(); n > 0 {
+		return fmt.Errorf("%d of %d hosts failed", n, len(m.hosts))
+	}
+	return nil
+}
+
+func hasVersion(releases []common.GoRelease, version string) bool {
+	for _, r := range releases {
+		if r.Version == version {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// goArchive returns a toolchain archive whose go command prints version,
+// or fails when version is empty.
+func goArchive(t *testing.T, version string) []byte {
+	t.Helper()
+	script := "#!/bin/sh\nexit 1\n"
+	if version != "" {
+		script = "#!/bin/sh\necho go version " + version + "\n"
+	}
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, h := range []*tar.Header{
+		{Name: "go/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "go/bin/", Typeflag: tar.TypeDir, Mode: 0755},
+		{Name: "go/bin/go", Typeflag: tar.TypeReg, Mode: 0755, Size: int64(len(script))},
+	} {
+		if err := tw.WriteHeader(h); err != nil {
+			t.Fatal(err)
+		}
+		if h.Typeflag == tar.TypeReg {
+			tw.Write([]byte(script))
+		}
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestRemoteInstallScript(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the script runs on unix hosts")
+	}
+	tests := []struct {
+		name    string
+		archive func(t *testing.T) []byte
+		wantErr bool
+		// want is the version the go command in PREFIX reports afterwards.
+		want string
+	}{
+		{"replaces", func(t *testing.T) []byte { return goArchive(t, "go1.22.1") }, false, "go1.22.1"},
+		{"broken toolchain", func(t *testing.T) []byte { return goArchive(t, "") }, true, "go1.21.0"},
+		{"truncated archive", func(t *testing.T) []byte { return goArchive(t, "go1.22.1")[:40] }, true, "go1.21.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := t.TempDir()
+			install := exec.Command("sh", "-c", fmt.Sprintf(remoteInstallScript, shellQuote(prefix)))
+			install.Stdin = bytes.NewReader(goArchive(t, "go1.21.0"))
+			if out, err := install.CombinedOutput(); err != nil {
+				t.Fatalf("first install: %v: %s", err, out)
+			}
+
+			cmd := exec.Command("sh", "-c", fmt.Sprintf(remoteInstallScript, shellQuote(prefix)))
+			cmd.Stdin = bytes.NewReader(tt.archive(t))
+			out, err := cmd.CombinedOutput()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v: %s", err, tt.wantErr, out)
+			}
+			got, err := exec.Command(filepath.Join(prefix, "go", "bin", "go")).Output()
+			if err != nil || string(got) != "go version "+tt.want+"\n" {
+				t.Errorf("go version = %q, %v; want %s", got, err, tt.want)
+			}
+			entries, _ := os.ReadDir(prefix)
+			if len(entries) != 1 {
+				t.Errorf("%s has %d entries, want only go", prefix, len(entries))
+			}
+		})
+	}
+}
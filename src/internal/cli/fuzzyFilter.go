@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/list"
+)
+
+// Match tiers of versionFilter, better matches sort first.
+const (
+	matchFuzzy = iota + 1
+	matchSubstring
+	matchPrefix
+	matchExact
+)
+
+// normalizeVersion reduces a version or search term to its letters and
+// digits without the go prefix, so "go1.22.1", "1.22.1" and "1221" compare
+// equal. It also returns the index in s of every kept byte.
+func normalizeVersion(s string) (string, []int) {
+	s = strings.ToLower(s)
+	start := 0
+	if strings.HasPrefix(s, "go") {
+		start = 2
+	}
+	var sb strings.Builder
+	var idx []int
+	for i := start; i < len(s); i++ {
+		if c := rune(s[i]); unicode.IsLetter(c) || unicode.IsDigit(c) {
+			sb.WriteByte(s[i])
+			idx = append(idx, i)
+		}
+	}
+	return sb.String(), idx
+}
+
+// versionFilter is the list filter of the picker. It ignores the go prefix
+// and dots and ranks exact, prefix, substring and then subsequence matches,
+// keeping the release order within a tier so newer releases come first.
+func versionFilter(term string, targets []string) []list.Rank {
+	t, _ := normalizeVersion(term)
+	if t == "" {
+		return nil
+	}
+	type scored struct {
+		rank list.Rank
+		tier int
+	}
+	var matches []scored
+	for i, target := range targets {
+		n, idx := normalizeVersion(target)
+		if n == "" {
+			continue
+		}
+		switch {
+		case n == t:
+			matches = append(matches, scored{list.Rank{Index: i, MatchedIndexes: idx}, matchExact})
+		case strings.HasPrefix(n, t):
+			matches = append(matches, scored{list.Rank{Index: i, MatchedIndexes: idx[:len(t)]}, matchPrefix})
+		case strings.Contains(n, t):
+			k := strings.Index(n, t)
+			matches = append(matches, scored{list.Rank{Index: i, MatchedIndexes: idx[k : k+len(t)]}, matchSubstring})
+		default:
+			var matched []int
+			for j := 0; j < len(n) && len(matched) < len(t); j++ {
+				if n[j] == t[len(matched)] {
+					matched = append(matched, idx[j])
+				}
+			}
+			if len(matched) == len(t) {
+				matches = append(matches, scored{list.Rank{Index: i, MatchedIndexes: matched}, matchFuzzy})
+			}
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b scored) int { return b.tier - a.tier })
+	ranks := make([]list.Rank, len(matches))
+	for i, m := range matches {
+		ranks[i] = m.rank
+	}
+	return ranks
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultHeartbeat stays well below the 10 minute silence limit common in CI.
+const DefaultHeartbeat = time.Minute
+
+// byteCounter is an io.Writer that only counts what passes through it.
+type byteCounter struct {
+	n atomic.Int64
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.n.Add(int64(len(p)))
+	return len(p), nil
+}
+
+// startHeartbeat prints a status line to w every interval until the returned
+// stop function is called, so CI systems that kill jobs without output see
+// that a long download or extraction is still alive. A zero interval
+// disables it.
+func startHeartbeat(w io.Writer, interval time.Duration, label string, counter *byteCounter) (stop func()) {
+	if interval <= 0 {
+		return func() {}
+	}
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-t.C:
+				elapsed := time.Since(start).Round(time.Second)
+				if counter != nil {
+					fmt.Fprintf(w, "... %s (%s elapsed, %.1f MB)\n", label, elapsed, float64(counter.n.Load())/(1<<20))
+				} else {
+					fmt.Fprintf(w, "... %s (%s elapsed)\n", label, elapsed)
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// NewHeartbeatSink runs a heartbeat on w while a step is in progress, for the
+// --json and --plain install whose sinks otherwise stay silent during a slow
+// step. A zero interval disables it.
+func NewHeartbeatSink(w io.Writer, interval time.Duration) EventSink {
+	var mu sync.Mutex
+	counter := &byteCounter{}
+	stop := func() {}
+	return func(e StepEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch e.Status {
+		case StatusStarted:
+			stop()
+			counter.n.Store(0)
+			stop = startHeartbeat(w, interval, strings.ToLower(stepLabel(e)), counter)
+		case StatusProgress:
+			counter.n.Store(e.Bytes)
+		case StatusDone, StatusFailed:
+			stop()
+			stop = func() {}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"os"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// catalog holds the translated strings for one language.
+type catalog struct {
+	// yes and no are the accepted answers, the first one of each is shown
+	// in prompts.
+	yes, no []string
+}
+
+var catalogs = map[string]catalog{
+	"en": {yes: []string{"y", "yes"}, no: []string{"n", "no"}},
+	"pl": {yes: []string{"t", "tak"}, no: []string{"n", "nie"}},
+	"de": {yes: []string{"j", "ja"}, no: []string{"n", "nein"}},
+	"nl": {yes: []string{"j", "ja"}, no: []string{"n", "nee"}},
+	"sv": {yes: []string{"j", "ja"}, no: []string{"n", "nej"}},
+	"fr": {yes: []string{"o", "oui"}, no: []string{"n", "non"}},
+	"es": {yes: []string{"s", "sí", "si"}, no: []string{"n", "no"}},
+	"it": {yes: []string{"s", "sì", "si"}, no: []string{"n", "no"}},
+	"pt": {yes: []string{"s", "sim"}, no: []string{"n", "não", "nao"}},
+	"ru": {yes: []string{"д", "да"}, no: []string{"н", "нет"}},
+	"uk": {yes: []string{"т", "так"}, no: []string{"н", "ні"}},
+}
+
+// locale returns the language code of the user's message locale, e.g. "pl"
+// for pl_PL.UTF-8.
+func locale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		v := os.Getenv(env)
+		if v == "" || v == "C" || v == "POSIX" {
+			continue
+		}
+		lang, _, _ := strings.Cut(v, "_")
+		lang, _, _ = strings.Cut(lang, ".")
+		return strings.ToLower(lang)
+	}
+	return "en"
+}
+
+func currentCatalog() catalog {
+	if c, ok := catalogs[locale()]; ok {
+		return c
+	}
+	return catalogs["en"]
+}
+
+type answer int
+
+const (
+	answerNone answer = iota
+	answerYes
+	answerNo
+)
+
+// parseAnswer maps a key or typed word to an answer. English y/n always
+// work, localized answers are accepted on top, and an empty line (enter)
+// selects def. Whitespace alone is no answer, so a stray space or tab does
+// not accept the default.
+func parseAnswer(input string, def bool) answer {
+	input = strings.TrimRight(input, "\r\n")
+	if input == "" || input == "enter" {
+		if def {
+			return answerYes
+		}
+		return answerNo
+	}
+	input = strings.ToLower(strings.TrimSpace(input))
+	for _, c := range []catalog{catalogs["en"], currentCatalog()} {
+		for _, y := range c.yes {
+			if input == y {
+				return answerYes
+			}
+		}
+		for _, n := range c.no {
+			if input == n {
+				return answerNo
+			}
+		}
+	}
+	return answerNone
+}
+
+// yesNoHint renders the localized "(Y/n)" hint with the default answer
+// capitalized.
+func yesNoHint(def bool) string {
+	c := currentCatalog()
+	y, n := c.yes[0], c.no[0]
+	if def {
+		y = upperFirst(y)
+	} else {
+		n = upperFirst(n)
+	}
+	return "(" + y + "/" + n + ")"
+}
+
+func upperFirst(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
@@ -0,0 +1,34 @@
+package cli
+
+import "testing"
+
+func TestParseAnswer(t *testing.T) {
+	tests := []struct {
+		lang, input string
+		def         bool
+		want        answer
+	}{
+		{"en", "\n", false, answerNo},
+		{"en", "\r\n", true, answerYes},
+		{"en", "enter", true, answerYes},
+		{"en", "", false, answerNo},
+		{"en", " ", true, answerNone},
+		{"en", "\t\n", false, answerNone},
+		{"en", "y\n", false, answerYes},
+		{"en", " YES \n", false, answerYes},
+		{"en", "n", true, answerNo},
+		{"en", "maybe\n", true, answerNone},
+		{"pl", "tak\n", false, answerYes},
+		{"pl", "nie\n", true, answerNo},
+		{"pl", "y\n", false, answerYes},
+		{"de", "j", false, answerYes},
+		{"ru", "Да\n", false, answerYes},
+		{"ru", "н", true, answerNo},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lang+"_XX.UTF-8")
+		if got := parseAnswer(tt.input, tt.def); got != tt.want {
+			t.Errorf("%s: parseAnswer(%q, %v) = %v, want %v", tt.lang, tt.input, tt.def, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// vscodeSettingsPaths returns the user settings files of VS Code and its
+// common forks that exist for home.
+func vscodeSettingsPaths(home string) []string {
+	base := filepath.Join(home, ".config")
+	if runtime.GOOS == "darwin" {
+		base = filepath.Join(home, "Library", "Application Support")
+	}
+	var paths []string
+	for _, app := range []string{"Code", "Code - OSS", "VSCodium", "Code - Insiders"} {
+		dir := filepath.Join(base, app, "User")
+		if _, err := os.Stat(dir); err == nil {
+			paths = append(paths, filepath.Join(dir, "settings.json"))
+		}
+	}
+	return paths
+}
+
+// setJSONCKey sets a string key in a JSON-with-comments document by editing
+// the text, so comments and formatting in the user's settings survive.
+func setJSONCKey(content, key, value string) string {
+	quotedKey := strconv.Quote(key)
+	quotedValue := strconv.Quote(value)
+	re := regexp.MustCompile(regexp.QuoteMeta(quotedKey) + `\s*:\s*"(?:[^"\\]|\\.)*"`)
+	if re.MatchString(content) {
+		return re.ReplaceAllLiteralString(content, quotedKey+": "+quotedValue)
+	}
+	i := strings.Index(content, "{")
+	if i < 0 {
+		return "{\n    " + quotedKey + ": " + quotedValue + "\n}\n"
+	}
+	entry := "\n    " + quotedKey + ": " + quotedValue
+	// VS Code accepts trailing commas, but skip it for an empty object.
+	if strings.TrimSpace(content[i+1:]) != "}" {
+		entry += ","
+	} else {
+		entry += "\n"
+	}
+	return content[:i+1] + entry + content[i+1:]
+}
+
+// configureVSCode points the Go extension of every VS Code installation at
+// the managed toolchain.
+func configureVSCode(account common.Account) ([]string, error) {
+	paths := vscodeSettingsPaths(account.Home)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no VS Code user settings directory found in %s", account.Home)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if len(content) > 0 {
+			if err := os.WriteFile(path+".go-install.bak", content, 0644); err != nil {
+				return nil, err
+			}
+			if err := account.Chown(path + ".go-install.bak"); err != nil {
+				return nil, err
+			}
+		}
+		updated := setJSONCKey(string(content), "go.goroot", common.GoRoot)
+		updated = setJSONCKey(updated, "go.toolsManagement.go", filepath.Join(common.GoRoot, "bin", "go"))
+		if err := os.WriteFile(path, []byte(updated), 0644); err != nil {
+			return nil, err
+		}
+		if err := account.Chown(path); err != nil {
+			return nil, err
+		}
+		common.RecordFile(path, common.KindIDESettings, "modified")
+	}
+	return paths, nil
+}
+
+func golandInstructions() string {
+	return fmt.Sprintf(`GoLand / IntelliJ IDEA with the Go plugin:
+  1. Open Settings → Go → GOROOT
+  2. Click "+" → "Local..." and select %s
+  3. Apply. New projects use it by default via Settings for New Projects → Go → GOROOT.`, common.GoRoot)
+}
+
+// RunIDE registers the managed toolchain with the given IDE.
+func RunIDE(ide string) error {
+	switch ide {
+	case "vscode", "code":
+		account, err := common.InvokingUser()
+		if err != nil {
+			return err
+		}
+		paths, err := configureVSCode(account)
+		if err != nil {
+			return err
+		}
+		for _, p := range paths {
+			fmt.Println(SuccessStyle.Render("✓ Updated " + p))
+		}
+		fmt.Println(InfoStyle.Render("Reload the VS Code window to pick up the new toolchain."))
+		return nil
+	case "goland", "idea":
+		fmt.Println(golandInstructions())
+		return nil
+	}
+	return fmt.Errorf("unknown IDE %q (supported: vscode, goland)", ide)
+}
+
+// ideHint suggests the ide command after an install when an IDE is present.
+func ideHint() string {
+	account, err := common.InvokingUser()
+	if err != nil || len(vscodeSettingsPaths(account.Home)) == 0 {
+		return ""
+	}
+	return "Run 'go-install ide vscode' to point VS Code at this toolchain."
+}
@@ -1,202 +1,41 @@
 package cli
 
 import (
-	"archive/tar"
-	"compress/gzip"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
+	"errors"
 	"fmt"
 	"go-installer/common"
-	"io"
-	"net/http"
+	"go-installer/pkg/installer"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 )
 
-func downloadFile(name string) error {
-	out, err := os.Create(name)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	resp, err := http.Get("https://go.dev/dl/" + name)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
-
-func verifyChecksum(name, want string) error {
-	f, err := os.Open(name)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
-		return err
-	}
-
-	got := hex.EncodeToString(h.Sum(nil))
-	if got != want {
-		return fmt.Errorf("sha mismatch: want=%s got=%s", want, got)
-	}
-
-	return nil
-}
-
-func setupEnvironment() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	shell := os.Getenv("SHELL")
-	var configFiles []string
-
-	if strings.Contains(shell, "zsh") {
-		configFiles = []string{filepath.Join(homeDir, ".zshrc")}
-	} else if strings.Contains(shell, "bash") {
-		configFiles = []string{
-			filepath.Join(homeDir, ".bashrc"),
-			filepath.Join(homeDir, ".bash_profile"),
-		}
-	} else {
-		configFiles = []string{filepath.Join(homeDir, ".bashrc")}
-	}
-
-	goPath := "export PATH=$PATH:/usr/local/go/bin"
-	goPathComment := "# Added by go-install"
-
-	for _, configFile := range configFiles {
-		if _, err := os.Stat(configFile); os.IsNotExist(err) {
-			continue
-		}
-
-		content, err := os.ReadFile(configFile)
-		if err != nil {
-			continue
-		}
-
-		if strings.Contains(string(content), "/usr/local/go/bin") {
-			return nil
-		}
-
-		f, err := os.OpenFile(configFile, os.O_APPEND|os.O_WRONLY, 0644)
-		if err != nil {
-			continue
-		}
-		defer f.Close()
-
-		if _, err := f.WriteString(fmt.Sprintf("\n%s\n%s\n", goPathComment, goPath)); err != nil {
-			continue
-		}
-
-		return nil
-	}
-
-	return fmt.Errorf("could not find shell config file to update")
-}
-
-func extractTarGz(src, dst string) error {
-	f, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	gz, err := gzip.NewReader(f)
-	if err != nil {
-		return err
-	}
-	defer gz.Close()
-
-	t := tar.NewReader(gz)
-
-	for {
-		h, err := t.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		target := filepath.Join(dst, h.Name)
-
-		switch h.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(h.Mode)); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
-				return err
-			}
-			w, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(h.Mode))
-			if err != nil {
-				return err
-			}
-			if _, err := io.Copy(w, t); err != nil {
-				w.Close()
-				return err
-			}
-			if err := w.Close(); err != nil {
-				return err
-			}
-		case tar.TypeSymlink:
-			if err := os.Symlink(h.Linkname, target); err != nil {
-				return err
-			}
-		}
-	}
-
-	return nil
-}
-
 type installState int
 
 const (
 	installStateDownloading installState = iota
 	installStateVerifying
-	installStateRemoving
 	installStateExtracting
+	installStateReplacing
+	installStateSmokeTesting
+	installStateChooseWorkspace
+	installStateChooseToolchain
+	installStateConfirmShell
 	installStateConfiguring
+	installStatePostInstall
 	installStateDone
 	installStateError
 )
 
-// Osobne typy wiadomości dla każdego kroku
-type downloadedMsg struct {
-	filename string
-	sha256   string
-	err      error
-}
-
-type verifiedMsg struct {
-	err error
-}
-
-type removedMsg struct {
-	err error
-}
-
-type extractedMsg struct {
-	err error
-}
-
-type configuredMsg struct {
-	err error
+// undoAction reverts a completed mutating step when a later step fails.
+type undoAction struct {
+	desc string
+	fn   func() error
 }
 
 type installModel struct {
@@ -209,77 +48,145 @@ type installModel struct {
 	err        error
 	filename   string
 	sha256     string
+	tmpDir     string
+	streamed   bool
+	opts       Options
+	undo       []undoAction
+	rolledBack []string
+	postErr    error
+	configured common.ShellChange
+	configSkip string
+	links      []string
+	// shellChange is the shell configuration edit awaiting confirmation.
+	shellChange common.ShellChange
+	// askToolchain offers pinning GOTOOLCHAIN after the workspace setup,
+	// pinEnv is set when the user chose go env -w.
+	askToolchain bool
+	pinEnv       bool
+	pinnedEnv    bool
+
+	// ctx is cancelled when the user quits or the process is signalled, so
+	// in-flight downloads and extractions stop and clean up after
+	// themselves.
+	ctx        context.Context
+	cancel     context.CancelFunc
+	cancelling bool
+	// checklist shows the progress of every step, transfer the
+	// throughput of the running download or extraction.
+	checklist checklist
+	transfer  *transferStats
 }
 
-func newInstallModel(version, targetOS, targetArch string, releases []common.GoRelease) installModel {
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+func newInstallModel(version, targetOS, targetArch string, releases []common.GoRelease, opts Options) installModel {
+	s := newSpinner()
+
+	ctx, cancel := context.WithCancel(context.Background())
 
-	return installModel{
+	m := installModel{
+		ctx:        ctx,
+		cancel:     cancel,
 		state:      installStateDownloading,
 		spinner:    s,
 		version:    version,
 		targetOS:   targetOS,
 		targetArch: targetArch,
 		releases:   releases,
+		opts:       opts,
+		transfer:   &transferStats{},
 	}
+	m.checklist = newChecklist(m)
+	return m
+}
+
+// publish forwards e to the sinks and shows it in the checklist.
+func (m installModel) publish(e StepEvent) {
+	e.Time = time.Now()
+	m.checklist.mark(e)
+	publish(m.opts.Sinks, e)
 }
 
 func (m installModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if msg.String() == "ctrl+c" || msg.String() == "q" {
-			return m, tea.Quit
+			return m.interrupt()
 		}
-
-	case downloadedMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.state = installStateError
-			return m, tea.Quit
+		if m.state == installStateChooseWorkspace {
+			mode := ""
+			switch msg.String() {
+			case "1", "enter", "y":
+				mode = common.WorkspacePath
+			case "2":
+				mode = common.WorkspaceExport
+			case "n":
+			default:
+				return m, nil
+			}
+			return m.confirmShell(mode)
 		}
-		m.filename = msg.filename
-		m.sha256 = msg.sha256
-		m.state = installStateVerifying
-		return m, m.stepVerify()
-
-	case verifiedMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.state = installStateError
-			return m, tea.Quit
+		if m.state == installStateChooseToolchain {
+			switch msg.String() {
+			case "1", "enter", "y":
+				m.pinEnv = true
+			case "2":
+				pin, err := common.PlanToolchainPin()
+				if err != nil {
+					m.state = installStateConfiguring
+					return m.Update(stepFailed(StepConfigure, err))
+				}
+				m.shellChange = common.MergeShellChanges(m.shellChange, pin)
+			case "n":
+			default:
+				return m, nil
+			}
+			return m.confirmShell("")
 		}
-		m.state = installStateRemoving
-		return m, m.stepRemove()
-
-	case removedMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.state = installStateError
-			return m, tea.Quit
+		if m.state == installStateConfirmShell {
+			switch parseAnswer(msg.String(), true) {
+			case answerYes:
+				m.state = installStateConfiguring
+				return m, m.applyShellChange(m.shellChange)
+			case answerNo:
+				m.state = installStateConfiguring
+				if m.pinEnv {
+					// The go env setting was chosen separately.
+					return m, m.applyShellChange(common.ShellChange{})
+				}
+				return m.Update(stepDone(StepConfigure, stepResult{configSkip: "declined"}))
+			}
 		}
-		m.state = installStateExtracting
-		return m, m.stepExtract()
 
-	case extractedMsg:
-		if msg.err != nil {
-			m.err = msg.err
-			m.state = installStateError
+	case shellPlanMsg:
+		m.shellChange = msg.change
+		m.askToolchain = msg.askToolchain
+		m.pinEnv = msg.pinEnv
+		if msg.askWorkspace {
+			m.state = installStateChooseWorkspace
+			return m, nil
+		}
+		return m.confirmShell("")
+
+	case interruptMsg:
+		return m.interrupt()
+
+	case StepEvent:
+		m.publish(msg)
+		if msg.Step == StepPostInstall {
+			// The toolchain is installed at this point, so a failing
+			// command is reported and reflected in the exit code but not
+			// rolled back.
+			m.postErr = msg.err
+			m.cancel()
+			m.state = installStateDone
 			return m, tea.Quit
 		}
-		m.state = installStateConfiguring
-		return m, m.stepConfigure()
-
-	case configuredMsg:
+		// A failed step may still hand over an undo action for the part
+		// that completed.
+		m.apply(msg.result)
 		if msg.err != nil {
-			m.err = msg.err
-			m.state = installStateError
-			return m, tea.Quit
+			return m.fail(msg.err)
 		}
-		m.state = installStateDone
-
-		return m, tea.Quit
+		return m.next()
 
 	case spinner.TickMsg:
 		if m.state == installStateDone || m.state == installStateError {
@@ -294,39 +201,226 @@ func (m installModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// interrupt cancels the running step. The model quits once that step has
+// reported back, so partial files are removed and completed steps undone.
+func (m installModel) interrupt() (tea.Model, tea.Cmd) {
+	if m.state == installStateDone || m.state == installStateError {
+		return m, tea.Quit
+	}
+	m.cancel()
+	m.cancelling = true
+	if m.state == installStateConfirmShell || m.state == installStateChooseWorkspace || m.state == installStateChooseToolchain {
+		// Nothing is running that would report back.
+		return m.fail(m.ctx.Err())
+	}
+	return m, nil
+}
+
+func (m *installModel) recordUndo(u *undoAction) {
+	if u != nil {
+		m.undo = append(m.undo, *u)
+	}
+}
+
+// apply takes over what a finished step produced.
+func (m *installModel) apply(r stepResult) {
+	if r.archive != "" {
+		m.filename = r.archive
+		m.sha256 = r.sha256
+	}
+	if r.dir != "" {
+		m.tmpDir = r.dir
+	}
+	m.streamed = m.streamed || r.streamed
+	if r.config.File != "" {
+		m.configured = r.config
+	}
+	if r.configSkip != "" {
+		m.configSkip = r.configSkip
+	}
+	m.pinnedEnv = m.pinnedEnv || r.pinnedEnv
+	if r.links != nil {
+		m.links = r.links
+	}
+	m.recordUndo(r.undo)
+}
+
+// fail reverts every completed mutating step, newest first, so a failed
+// install leaves the previous toolchain and shell config in place.
+func (m installModel) fail(err error) (tea.Model, tea.Cmd) {
+	m.cancel()
+	if m.cancelling {
+		err = fmt.Errorf("installation %w", common.ErrCancelled)
+	}
+	m.err = err
+	m.state = installStateError
+	for i := len(m.undo) - 1; i >= 0; i-- {
+		u := m.undo[i]
+		if uerr := u.fn(); uerr != nil {
+			m.rolledBack = append(m.rolledBack, fmt.Sprintf("%s (failed: %v)", u.desc, uerr))
+			continue
+		}
+		m.rolledBack = append(m.rolledBack, u.desc)
+	}
+	m.undo = nil
+	return m, tea.Quit
+}
+
+// pipeline lists the steps in execution order with the state shown while
+// they run. Every step reports back with a single done or failed
+// StepEvent.
+var pipeline = []struct {
+	step  string
+	state installState
+	run   func(installModel) tea.Cmd
+}{
+	{StepDownload, installStateDownloading, installModel.stepDownload},
+	{StepVerify, installStateVerifying, installModel.stepVerify},
+	{StepExtract, installStateExtracting, installModel.stepExtract},
+	{StepReplace, installStateReplacing, installModel.stepReplace},
+	{StepSmokeTest, installStateSmokeTesting, installModel.stepSmokeTest},
+	{StepConfigure, installStateConfiguring, installModel.stepConfigure},
+}
+
+// next advances to the first selected step after the current state.
+func (m installModel) next() (tea.Model, tea.Cmd) {
+	if m.ctx.Err() != nil {
+		return m.fail(m.ctx.Err())
+	}
+	for _, s := range pipeline {
+		if s.state <= m.state || !m.opts.runsPipeline(s.step) {
+			continue
+		}
+		if m.streamed && (s.step == StepVerify || s.step == StepExtract) {
+			continue
+		}
+		m.state = s.state
+		m.publish(stepStarted(s.step))
+		return m, s.run(m)
+	}
+	if m.tmpDir != "" && !m.opts.runs(StepReplace) {
+		os.RemoveAll(m.tmpDir)
+	}
+	if m.opts.runs(StepReplace) {
+		common.RecordFile(common.GoRoot, common.KindGoRoot, "created")
+		common.PruneBackups(m.opts.KeepBackups)
+	}
+	if m.configured.File != "" {
+		common.RecordShellChange(m.configured)
+	}
+	for _, link := range m.links {
+		common.RecordFile(link, common.KindBinLink, "created")
+	}
+	switch {
+	case m.opts.DiscardArchive && strings.HasPrefix(m.filename, common.ArchiveCacheDir()):
+		os.Remove(m.filename)
+	case m.opts.runs(StepDownload):
+		common.RecordFile(common.ArchiveCacheDir(), common.KindCache, "created")
+	}
+	common.GCArchiveCache(common.DefaultCacheMaxSize)
+	if m.opts.PostInstallCmd != "" && m.state < installStatePostInstall {
+		m.state = installStatePostInstall
+		m.publish(stepStarted(StepPostInstall))
+		return m, m.stepPostInstall()
+	}
+	m.cancel()
+	m.state = installStateDone
+	return m, tea.Quit
+}
+
 func (m installModel) View() string {
 	if m.state == installStateError {
-		return ErrorStyle.Render(fmt.Sprintf("\n✗ Error: %v\n\n", m.err))
+		var sb strings.Builder
+		sb.WriteString("\n" + m.checklist.view(m.spinner.View(), m.transfer))
+		sb.WriteString(ErrorStyle.Render(fmt.Sprintf("\n✗ Error: %v\n", m.err)))
+		if len(m.rolledBack) > 0 {
+			sb.WriteString(InfoStyle.Render("\nRolled back:"))
+			for _, r := range m.rolledBack {
+				sb.WriteString(InfoStyle.Render("\n  • " + r))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+		return sb.String()
+	}
+	if m.state == installStateDone && !m.opts.runs(StepReplace) {
+		return "\n" + m.checklist.view(m.spinner.View(), m.transfer) + SuccessStyle.Render(fmt.Sprintf("\n✓ Completed steps for %s: %s\n", m.version, strings.Join(m.opts.Steps, ", ")))
 	}
 	if m.state == installStateDone {
 		var sb strings.Builder
-		sb.WriteString(SuccessStyle.Render(fmt.Sprintf("\n✓ Successfully installed %s to /usr/local/go")))
-		sb.WriteString(InfoStyle.Render("\nPlease restart your terminal or run 'source' on your shell configuration file to apply the changes.\n"))
+		sb.WriteString("\n" + m.checklist.view(m.spinner.View(), m.transfer))
+		sb.WriteString(SuccessStyle.Render(fmt.Sprintf("\n✓ Successfully installed %s to %s", m.version, common.GoRoot)))
+		switch {
+		case m.configSkip != "":
+			sb.WriteString(InfoStyle.Render(fmt.Sprintf("\n! Shell configuration skipped: %s. Add %s to PATH yourself.\n",
+				m.configSkip, filepath.Join(common.GoRoot, "bin"))))
+		case len(m.links) > 0:
+			sb.WriteString(InfoStyle.Render(fmt.Sprintf("\nLinked go and gofmt into %s, no shell configuration was changed.\n", common.BinLinkDir)))
+		case m.opts.runs(StepConfigure):
+			sb.WriteString(InfoStyle.Render("\nPlease restart your terminal or run 'source' on your shell configuration file to apply the changes.\n"))
+		case m.opts.Alternatives:
+			sb.WriteString(InfoStyle.Render(fmt.Sprintf("\ngo and gofmt are linked from %s by update-alternatives.\n", common.AlternativesDir)))
+		default:
+			line := common.ExportLine()
+			if _, ok := common.DetectContainer(); ok {
+				line = common.ContainerEnvLine()
+			}
+			sb.WriteString(InfoStyle.Render("\nShell configuration left untouched, put Go on PATH with:\n  " + line + "\n"))
+		}
+		if m.pinnedEnv {
+			sb.WriteString(InfoStyle.Render("Set " + common.ToolchainLocal + " with go env -w, go will not download other toolchains.\n"))
+		}
+		if hint := ideHint(); hint != "" {
+			sb.WriteString(InfoStyle.Render(hint + "\n"))
+		}
+		if m.postErr != nil {
+			sb.WriteString(ErrorStyle.Render(fmt.Sprintf("\n✗ Post-install command failed: %v\n", m.postErr)))
+		}
 		return sb.String()
 	}
 
-	step := m.getStepDescription()
-	return fmt.Sprintf("\n%s %s\n", m.spinner.View(), step)
-}
+	if m.state == installStateChooseWorkspace {
+		var sb strings.Builder
+		sb.WriteString(InfoStyle.Render("\nTools installed with 'go install' go into ~/go/bin, which is not on PATH yet.\n"))
+		sb.WriteString("  1) create ~/go and add ~/go/bin to PATH\n")
+		sb.WriteString("  2) also export GOPATH and GOBIN\n")
+		sb.WriteString("  n) skip\n")
+		sb.WriteString(TitleStyle.Render("Set up a Go workspace? [1/2/n] (default 1): "))
+		sb.WriteString(helpFooter(choiceKeys("add to PATH", "also export", "skip")))
+		return sb.String()
+	}
 
-func (m installModel) getStepDescription() string {
-	switch m.state {
-	case installStateDownloading:
-		return "Downloading Go archive..."
-	case installStateVerifying:
-		return "Verifying checksum..."
-	case installStateRemoving:
-		return "Removing old installation..."
-	case installStateExtracting:
-		return "Extracting archive..."
-	case installStateConfiguring:
-		return "Configuring environment..."
-	default:
-		return "Installing..."
+	if m.state == installStateChooseToolchain {
+		var sb strings.Builder
+		sb.WriteString(InfoStyle.Render(fmt.Sprintf("\nGo %s may download a different toolchain when a go.mod asks for one.\n", strings.TrimPrefix(m.version, "go"))))
+		sb.WriteString("  1) keep this one with go env -w " + common.ToolchainLocal + "\n")
+		sb.WriteString("  2) export " + common.ToolchainLocal + " in the shell configuration\n")
+		sb.WriteString("  n) allow downloads\n")
+		sb.WriteString(TitleStyle.Render("Pin the installed toolchain? [1/2/n] (default 1): "))
+		sb.WriteString(helpFooter(choiceKeys("go env -w", "shell", "allow downloads")))
+		return sb.String()
 	}
+
+	if m.state == installStateConfirmShell {
+		var sb strings.Builder
+		sb.WriteString(InfoStyle.Render(fmt.Sprintf("\nTo put Go on PATH, these lines would be appended to %s:\n", m.shellChange.File)))
+		for _, l := range m.shellChange.Lines {
+			sb.WriteString(SuccessStyle.Render("  + "+l) + "\n")
+		}
+		sb.WriteString(TitleStyle.Render("Apply this change? " + yesNoHint(true) + ": "))
+		sb.WriteString(helpFooter(answerKeys("apply", "skip", true)))
+		return sb.String()
+	}
+
+	view := "\n" + m.checklist.view(m.spinner.View(), m.transfer)
+	if m.cancelling {
+		return view + InfoStyle.Render("Cancelling...") + "\n"
+	}
+	return view + helpFooter(keyHelp{cancelKey})
 }
 
 func (m installModel) Init() tea.Cmd {
+	m.publish(stepStarted(StepDownload))
 	return tea.Batch(
 		m.spinner.Tick,
 		m.stepDownload(),
@@ -335,56 +429,290 @@ func (m installModel) Init() tea.Cmd {
 
 func (m installModel) stepDownload() tea.Cmd {
 	return func() tea.Msg {
-		_, file, sha, err := common.FindBuild(m.releases, m.version, m.targetOS, m.targetArch)
+		release, file, sha, err := common.FindBuild(m.releases, m.version, m.targetOS, m.targetArch)
 		if err != nil {
-			return downloadedMsg{err: err}
+			return stepFailed(StepDownload, err)
 		}
 
-		if err := downloadFile(file); err != nil {
-			return downloadedMsg{err: err}
+		if !m.opts.runs(StepDownload) {
+			if !m.opts.needsCachedArchive() {
+				// Only configure runs, it works on the installed toolchain.
+				return stepDone(StepDownload, stepResult{})
+			}
+			// Reuse the archive cached by an earlier --only download run.
+			path := common.CachedArchivePath(file, sha)
+			if _, err := os.Stat(path); err != nil {
+				return stepFailed(StepDownload, fmt.Errorf("archive %s not in cache, run with --only download first", file))
+			}
+			return stepDone(StepDownload, stepResult{archive: path, sha256: sha})
+		}
+
+		f, _ := release.File(file)
+		progress := newProgressWriter(StepDownload, f.Size, m.opts.Sinks)
+		progress.stats = m.transfer
+		m.transfer.start(StepDownload, f.Size)
+
+		if m.streams() {
+			path, dir, err := installer.Stream(m.ctx, file, sha, common.InstallPrefix, progress)
+			if err != nil {
+				return stepFailed(StepDownload, err)
+			}
+			return stepDone(StepDownload, stepResult{
+				archive:  path,
+				sha256:   sha,
+				dir:      dir,
+				streamed: true,
+				undo: &undoAction{
+					desc: "removed extracted files",
+					fn:   func() error { return os.RemoveAll(dir) },
+				},
+			})
 		}
 
-		return downloadedMsg{
-			filename: file,
-			sha256:   sha,
-			err:      nil,
+		path, err := installer.FetchArchive(m.ctx, file, sha, progress, m.opts.Connections)
+		if err != nil {
+			return stepFailed(StepDownload, err)
 		}
+		return stepDone(StepDownload, stepResult{archive: path, sha256: sha})
 	}
 }
 
+// streams reports whether download, verify and extract run as one step,
+// see common.StreamArchive. Chunked downloads go through FetchArchive.
+func (m installModel) streams() bool {
+	return m.opts.runs(StepVerify) && m.opts.runs(StepExtract) && m.opts.Connections <= 1
+}
+
 func (m installModel) stepVerify() tea.Cmd {
 	return func() tea.Msg {
-		if err := verifyChecksum(m.filename, m.sha256); err != nil {
-			return verifiedMsg{err: err}
+		if err := installer.Verify(m.filename, m.sha256); err != nil {
+			return stepFailed(StepVerify, err)
 		}
-		return verifiedMsg{err: nil}
+		return stepDone(StepVerify, stepResult{})
 	}
 }
 
-func (m installModel) stepRemove() tea.Cmd {
+func (m installModel) stepExtract() tea.Cmd {
 	return func() tea.Msg {
-		if err := os.RemoveAll("/usr/local/go"); err != nil {
-			return removedMsg{err: err}
+		size, _ := common.UncompressedSize(m.filename)
+		m.transfer.start(StepExtract, size)
+		dir, err := installer.Extract(m.ctx, m.filename, common.InstallPrefix, m.transfer)
+		if err != nil {
+			return stepFailed(StepExtract, err)
 		}
-		return removedMsg{err: nil}
+		return stepDone(StepExtract, stepResult{dir: dir, undo: &undoAction{
+			desc: "removed extracted files",
+			fn:   func() error { return os.RemoveAll(dir) },
+		}})
 	}
 }
 
-func (m installModel) stepExtract() tea.Cmd {
+func (m installModel) stepReplace() tea.Cmd {
 	return func() tea.Msg {
-		if err := extractTarGz(m.filename, "/usr/local"); err != nil {
-			return extractedMsg{err: err}
+		backup, err := installer.Replace(m.tmpDir, m.version)
+		if err != nil {
+			return stepFailed(StepReplace, err)
 		}
-		os.Remove(m.filename)
-		return extractedMsg{err: nil}
+		undo := &undoAction{
+			desc: "removed new " + common.GoRoot,
+			fn:   func() error { return installer.Restore(backup) },
+		}
+		if backup != "" {
+			undo.desc = "restored previous " + common.GoRoot
+		}
+		if m.opts.Alternatives {
+			if err := common.RegisterAlternatives(m.version); err != nil {
+				e := stepFailed(StepReplace, err)
+				e.result = stepResult{undo: undo}
+				return e
+			}
+		}
+		// The backup must survive until the install can no longer be
+		// rolled back, so keep one more than requested for now.
+		if err := common.PruneBackups(m.opts.KeepBackups + 1); err != nil {
+			e := stepFailed(StepReplace, err)
+			e.result = stepResult{undo: undo}
+			return e
+		}
+		return stepDone(StepReplace, stepResult{undo: undo})
 	}
 }
 
+// stepSmokeTest runs the new go command, so a toolchain that does not work
+// on this host is rolled back before anything points at it.
+func (m installModel) stepSmokeTest() tea.Cmd {
+	return func() tea.Msg {
+		if err := installer.SmokeTest(m.ctx, common.GoRoot, m.version); err != nil {
+			return stepFailed(StepSmokeTest, err)
+		}
+		return stepDone(StepSmokeTest, stepResult{})
+	}
+}
+
+// shellPlanMsg asks the user to confirm a shell configuration edit before
+// it is written, after choosing a workspace setup when askWorkspace is set
+// and a way to pin GOTOOLCHAIN when askToolchain is.
+type shellPlanMsg struct {
+	change       common.ShellChange
+	askWorkspace bool
+	askToolchain bool
+	// pinEnv carries a go env pin chosen by --pin-toolchain.
+	pinEnv bool
+}
+
+// confirmShell adds the chosen workspace setup, if any, to the pending
+// shell change, offers pinning the toolchain when still pending and asks
+// to confirm the change.
+func (m installModel) confirmShell(mode string) (tea.Model, tea.Cmd) {
+	if mode != "" {
+		ws, err := common.PlanWorkspace(mode)
+		if err != nil {
+			m.state = installStateConfiguring
+			return m.Update(stepFailed(StepConfigure, err))
+		}
+		m.shellChange = common.MergeShellChanges(m.shellChange, ws)
+	}
+	if m.askToolchain {
+		m.askToolchain = false
+		m.state = installStateChooseToolchain
+		return m, nil
+	}
+	if m.shellChange.File == "" {
+		m.state = installStateConfiguring
+		if m.pinEnv {
+			return m, m.applyShellChange(m.shellChange)
+		}
+		return m.Update(stepDone(StepConfigure, stepResult{}))
+	}
+	m.state = installStateConfirmShell
+	return m, nil
+}
+
 func (m installModel) stepConfigure() tea.Cmd {
 	return func() tea.Msg {
-		if err := setupEnvironment(); err != nil {
-			return configuredMsg{err: err}
+		if m.opts.SymlinkBinaries {
+			return linkBinaries()
+		}
+		change, err := common.PlanShellConfig()
+		if errors.Is(err, common.ErrNoHome) {
+			// Nothing user-level to configure, e.g. a systemd service or
+			// container without HOME. The toolchain itself is usable.
+			return stepDone(StepConfigure, stepResult{configSkip: "no home directory for the current user"})
+		}
+		if err != nil {
+			return stepFailed(StepConfigure, err)
+		}
+		askWorkspace := false
+		switch mode := m.opts.Workspace; {
+		case mode == common.WorkspacePath || mode == common.WorkspaceExport:
+			ws, err := common.PlanWorkspace(mode)
+			if err != nil {
+				return stepFailed(StepConfigure, err)
+			}
+			change = common.MergeShellChanges(change, ws)
+		case mode == WorkspaceAsk && !m.opts.Yes:
+			ws, err := common.PlanWorkspace(common.WorkspacePath)
+			askWorkspace = err == nil && ws.File != ""
+		}
+		askToolchain := false
+		if common.SwitchesToolchains(m.version) {
+			switch m.opts.ToolchainPin {
+			case ToolchainEnv:
+				m.pinEnv = true
+			case ToolchainShell:
+				pin, err := common.PlanToolchainPin()
+				if err != nil {
+					return stepFailed(StepConfigure, err)
+				}
+				change = common.MergeShellChanges(change, pin)
+			case ToolchainAsk:
+				askToolchain = !m.opts.Yes && !m.toolchainPinned()
+			}
+		}
+		if askWorkspace || askToolchain {
+			return shellPlanMsg{change: change, askWorkspace: askWorkspace, askToolchain: askToolchain, pinEnv: m.pinEnv}
+		}
+		if change.File == "" && !m.pinEnv {
+			return stepDone(StepConfigure, stepResult{})
+		}
+		if !m.opts.Yes && !m.opts.Confirmed && change.File != "" {
+			return shellPlanMsg{change: change, pinEnv: m.pinEnv}
+		}
+		return m.applyShellChange(change)()
+	}
+}
+
+// linkBinaries links the go commands into common.BinLinkDir, leaving the
+// dotfiles alone.
+func linkBinaries() tea.Msg {
+	links, err := common.LinkBinaries()
+	undo := &undoAction{
+		desc: "removed the links in " + common.BinLinkDir,
+		fn:   func() error { return common.UnlinkBinaries(links) },
+	}
+	if err != nil {
+		e := stepFailed(StepConfigure, err)
+		e.result = stepResult{undo: undo}
+		return e
+	}
+	return stepDone(StepConfigure, stepResult{links: links, undo: undo})
+}
+
+// toolchainPinned reports whether the invoking user sets GOTOOLCHAIN in
+// their go env file or shell configuration already.
+func (m installModel) toolchainPinned() bool {
+	account, err := common.InvokingUser()
+	if err != nil || toolchainPinned(account) {
+		return true
+	}
+	pin, err := common.PlanToolchainPin()
+	return err != nil || pin.File == ""
+}
+
+// applyShellChange appends change to its file, if any, and writes
+// GOTOOLCHAIN=local with go env -w when that was chosen.
+func (m installModel) applyShellChange(change common.ShellChange) tea.Cmd {
+	return func() tea.Msg {
+		var result stepResult
+		if change.File != "" {
+			if _, err := common.ApplyShellChange(change); err != nil {
+				return stepFailed(StepConfigure, err)
+			}
+			result = stepResult{config: change, undo: &undoAction{
+				desc: "removed the PATH entry from " + change.File,
+				fn: func() error {
+					_, err := common.RemoveShellChange(change)
+					return err
+				},
+			}}
+		}
+		if m.pinEnv {
+			account, err := common.InvokingUser()
+			if err == nil {
+				err = pinToolchain(account)
+			}
+			if err != nil {
+				e := stepFailed(StepConfigure, err)
+				e.result = result
+				return e
+			}
+			result.pinnedEnv = true
 		}
-		return configuredMsg{err: nil}
+		return stepDone(StepConfigure, result)
 	}
 }
+
+// stepPostInstall hands the terminal to the user's command so its output is
+// streamed as is.
+func (m installModel) stepPostInstall() tea.Cmd {
+	cmd := common.HookCommand(common.HookPostInstall, m.opts.PostInstallCmd, m.version)
+	if m.opts.JSON {
+		cmd.Stdout = cmd.Stderr
+	}
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		if err != nil {
+			return stepFailed(StepPostInstall, err)
+		}
+		return stepDone(StepPostInstall, stepResult{})
+	})
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"go-installer/pkg/installer"
+	"slices"
+	"strings"
+)
+
+// Names of the install pipeline steps, in execution order.
+const (
+	StepDownload  = installer.StepDownload
+	StepVerify    = installer.StepVerify
+	StepExtract   = installer.StepExtract
+	StepReplace   = installer.StepReplace
+	StepConfigure = installer.StepConfigure
+)
+
+var allSteps = []string{StepDownload, StepVerify, StepExtract, StepReplace, StepConfigure}
+
+// stepRequires lists steps that only work when another step runs in the
+// same invocation. Steps that need the archive without downloading it use
+// the file left by an earlier --only download run. An archive is never
+// extracted without checking its checksum.
+var stepRequires = map[string]string{
+	StepExtract: StepVerify,
+	StepReplace: StepExtract,
+}
+
+// ParseSteps turns --only/--skip values into the ordered list of steps to
+// run. Empty values select every step.
+func ParseSteps(only, skip string) ([]string, error) {
+	if only != "" && skip != "" {
+		return nil, fmt.Errorf("--only and --skip cannot be combined")
+	}
+
+	parse := func(value string) ([]string, error) {
+		var steps []string
+		for _, s := range strings.Split(value, ",") {
+			s = strings.TrimSpace(s)
+			if s == "" {
+				continue
+			}
+			if !slices.Contains(allSteps, s) {
+				return nil, fmt.Errorf("unknown step %q (valid: %s)", s, strings.Join(allSteps, ", "))
+			}
+			steps = append(steps, s)
+		}
+		return steps, nil
+	}
+
+	selected := slices.Clone(allSteps)
+	switch {
+	case only != "":
+		steps, err := parse(only)
+		if err != nil {
+			return nil, err
+		}
+		selected = slices.DeleteFunc(selected, func(s string) bool { return !slices.Contains(steps, s) })
+	case skip != "":
+		steps, err := parse(skip)
+		if err != nil {
+			return nil, err
+		}
+		selected = slices.DeleteFunc(selected, func(s string) bool { return slices.Contains(steps, s) })
+	}
+
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no steps selected")
+	}
+	for _, s := range selected {
+		if dep, ok := stepRequires[s]; ok && !slices.Contains(selected, dep) {
+			return nil, fmt.Errorf("step %q requires %q", s, dep)
+		}
+	}
+	return selected, nil
+}
+
+// runsPipeline is runs for the steps of the install pipeline, including
+// the smoke test that comes with the replace step.
+func (o Options) runsPipeline(step string) bool {
+	if step == StepSmokeTest {
+		return o.runs(StepReplace) && !common.ArchOverridden()
+	}
+	return o.runs(step)
+}
+
+// runs reports whether step is part of this run. A nil step list means the
+// full pipeline.
+func (o Options) runs(step string) bool {
+	return o.Steps == nil || slices.Contains(o.Steps, step)
+}
+
+// needsCachedArchive reports whether the selected steps use the archive
+// of an earlier --only download run.
+func (o Options) needsCachedArchive() bool {
+	return !o.runs(StepDownload) && o.runs(StepVerify)
+}
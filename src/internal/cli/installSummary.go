@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"strings"
+)
+
+// summaryRow is a line of the pre-install summary.
+type summaryRow struct {
+	label, value string
+}
+
+// installSummary describes what installing the selected version changes,
+// shown for confirmation before anything is touched.
+func (m preInstallModel) installSummary() string {
+	var sb strings.Builder
+	for _, r := range m.summaryRows() {
+		value := r.value
+		if r.label == "Support" {
+			value = warningStyle.Render("⚠️  " + value)
+		}
+		fmt.Fprintf(&sb, "  %-14s %s\n", r.label, value)
+	}
+	return sb.String()
+}
+
+// summaryEvent reports the pre-install summary to the sinks, which is all
+// headless output shows of it.
+func (m preInstallModel) summaryEvent() StepEvent {
+	rows := m.summaryRows()
+	e := StepEvent{Step: StepSummary, Status: StatusDone, Version: m.selectedVer, Summary: map[string]string{}, rows: rows}
+	for _, r := range rows {
+		e.Summary[strings.ReplaceAll(strings.ToLower(r.label), " ", "_")] = r.value
+	}
+	return e
+}
+
+func (m preInstallModel) summaryRows() []summaryRow {
+	var rows []summaryRow
+	row := func(label, value string) {
+		rows = append(rows, summaryRow{label, value})
+	}
+	row("Version", m.selectedVer)
+	if common.EndOfLife(m.releases, m.selectedVer) {
+		v, _ := common.ParseVersion(m.selectedVer)
+		row("Support", fmt.Sprintf("%s is end of life and gets no security fixes", v.Series()))
+	}
+	row("Platform", m.targetOS+"/"+m.targetArch)
+	if release, file, sha, err := common.FindBuild(m.releases, m.selectedVer, m.targetOS, m.targetArch); err == nil {
+		archive := file
+		if f, ok := release.File(file); ok {
+			archive += " (" + common.FormatSize(f.Size) + ")"
+		}
+		if _, err := os.Stat(common.CachedArchivePath(file, sha)); err == nil {
+			archive += ", cached"
+		}
+		row("Archive", archive)
+	}
+	row("Install to", common.GoRoot)
+	row("Replaces", m.replaceSummary())
+	if m.opts.Alternatives {
+		row("Alternatives", "go and gofmt registered with update-alternatives")
+	}
+	row("Shell config", m.shellSummary())
+	return rows
+}
+
+func (m preInstallModel) replaceSummary() string {
+	if !m.opts.runs(StepReplace) {
+		return "nothing, the replace step is skipped"
+	}
+	if _, err := os.Stat(common.GoRoot); err != nil {
+		return "nothing, new installation"
+	}
+	current := "the existing " + common.GoRoot
+	if v, err := common.InstalledVersion(common.GoRoot); err == nil {
+		current = v
+	}
+	if m.opts.KeepBackups > 0 {
+		return current + ", kept as a backup for rollback"
+	}
+	return current + ", removed"
+}
+
+func (m preInstallModel) shellSummary() string {
+	switch {
+	case !m.opts.runs(StepConfigure):
+		return "left untouched"
+	case m.opts.SymlinkBinaries:
+		return "none, go and gofmt are linked into " + common.BinLinkDir
+	}
+	change, err := common.PlanShellConfig()
+	switch {
+	case errors.Is(err, common.ErrNoHome):
+		return "none, no home directory"
+	case err != nil:
+		return "unknown: " + err.Error()
+	case change.File == "":
+		return "none, PATH is set up already"
+	}
+	return "PATH added to " + change.File
+}
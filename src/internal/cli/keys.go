@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// keyHelp is the key.Map of a prompt, listed by the help footer.
+type keyHelp []key.Binding
+
+func (k keyHelp) ShortHelp() []key.Binding  { return k }
+func (k keyHelp) FullHelp() [][]key.Binding { return [][]key.Binding{k} }
+
+var (
+	quitKey   = key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "quit"))
+	cancelKey = key.NewBinding(key.WithKeys("q", "ctrl+c"), key.WithHelp("q", "cancel"))
+	selectKey = key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "select"))
+)
+
+// answerKeys lists the keys of a yes/no prompt, enter picks the default.
+func answerKeys(yes, no string, def bool) keyHelp {
+	c := currentCatalog()
+	enter := no
+	if def {
+		enter = yes
+	}
+	return keyHelp{
+		key.NewBinding(key.WithKeys(c.yes[0]), key.WithHelp(c.yes[0], yes)),
+		key.NewBinding(key.WithKeys(c.no[0]), key.WithHelp(c.no[0], no)),
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", enter)),
+		quitKey,
+	}
+}
+
+// choiceKeys lists the keys of a numbered prompt, the first choice is the
+// default.
+func choiceKeys(first, second, skip string) keyHelp {
+	return keyHelp{
+		key.NewBinding(key.WithKeys("1", "enter"), key.WithHelp("1/enter", first)),
+		key.NewBinding(key.WithKeys("2"), key.WithHelp("2", second)),
+		key.NewBinding(key.WithKeys("n"), key.WithHelp("n", skip)),
+		quitKey,
+	}
+}
+
+// helpFooter renders the help bar below a prompt.
+func helpFooter(keys keyHelp) string {
+	h := help.New()
+	if plain {
+		h.ShortSeparator = " - "
+	}
+	return "\n" + h.View(keys) + "\n"
+}
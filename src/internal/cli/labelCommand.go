@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"slices"
+	"strings"
+)
+
+// RunLabel sets the key=value labels in set and removes the keys in remove.
+// Without either it lists the labels of this host.
+func RunLabel(set, remove []string) error {
+	state, err := common.LoadState()
+	if err != nil {
+		return err
+	}
+
+	if len(set) == 0 && len(remove) == 0 {
+		if len(state.Labels) == 0 {
+			fmt.Println(InfoStyle.Render("No labels set"))
+			return nil
+		}
+		keys := make([]string, 0, len(state.Labels))
+		for k := range state.Labels {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, state.Labels[k])
+		}
+		return nil
+	}
+
+	if err := setLabels(&state, set, remove); err != nil {
+		return err
+	}
+	if err := state.Save(); err != nil {
+		return err
+	}
+	fmt.Println(SuccessStyle.Render("✓ Labels updated"))
+	return nil
+}
+
+// ApplyLabels stores the key=value labels of --label or the label config
+// key along with an install.
+func ApplyLabels(set []string) error {
+	state, err := common.LoadState()
+	if err != nil {
+		return err
+	}
+	if err := setLabels(&state, set, nil); err != nil {
+		return err
+	}
+	return state.Save()
+}
+
+func setLabels(state *common.State, set, remove []string) error {
+	for _, l := range set {
+		key, value, err := common.ParseLabel(strings.TrimSpace(l))
+		if err != nil {
+			return err
+		}
+		state.SetLabel(key, value)
+	}
+	for _, key := range remove {
+		state.SetLabel(strings.TrimSpace(key), "")
+	}
+	return nil
+}
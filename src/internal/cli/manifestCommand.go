@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// manifest returns every recorded file plus the state and lock files
+// themselves, which are never recorded in the state.
+func manifest() ([]common.ManagedFile, error) {
+	state, err := common.LoadState()
+	if err != nil {
+		return nil, err
+	}
+	files := state.Files
+	for _, f := range []struct{ path, kind string }{
+		{common.StatePath, common.KindState},
+		{common.LockPath, common.KindLock},
+	} {
+		if fi, err := os.Stat(f.path); err == nil {
+			files = append(files, common.ManagedFile{Path: f.path, Kind: f.kind, Action: "created", Updated: fi.ModTime()})
+		}
+	}
+	return files, nil
+}
+
+// RunManifest prints the files go-install owns on this host. The JSON form
+// also identifies the host, its labels and the installed version, so an
+// inventory system can aggregate toolchains across a fleet.
+func RunManifest(asJSON bool) error {
+	files, err := manifest()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		state, err := common.LoadState()
+		if err != nil {
+			return err
+		}
+		host, _ := os.Hostname()
+		version, _ := common.InstalledVersion(common.GoRoot)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{
+			"host":    host,
+			"labels":  state.Labels,
+			"version": version,
+			"files":   files,
+		})
+	}
+	if len(files) == 0 {
+		fmt.Println(InfoStyle.Render("No files recorded"))
+		return nil
+	}
+	for _, f := range files {
+		fmt.Printf("  %-13s %-9s %s  %s\n", f.Kind, f.Action, f.Updated.Format("2006-01-02 15:04"), f.Path)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-installer/common"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// mirrorResult is what a mirror serves for one upstream file.
+type mirrorResult struct {
+	index  int
+	size   int64
+	sha256 string
+	err    error
+}
+
+type mirrorCheckedMsg mirrorResult
+
+type mirrorCompareModel struct {
+	mirror  string
+	release common.GoRelease
+	results []*mirrorResult
+	pending int
+	table   table.Model
+	spinner spinner.Model
+}
+
+// mirrorDownloads bounds how many mirrored files are hashed at once.
+var mirrorDownloads = make(chan struct{}, 4)
+
+// mirrorFileTimeout bounds downloading one mirrored file.
+const mirrorFileTimeout = 10 * time.Minute
+
+// checkMirrorFile downloads a mirrored file and hashes it, so it can be
+// compared with the checksum go.dev publishes in its release list. The
+// mirror's own .sha256 files would go stale along with the archive.
+func checkMirrorFile(mirror string, index int, f common.GoFile) tea.Cmd {
+	return func() tea.Msg {
+		mirrorDownloads <- struct{}{}
+		defer func() { <-mirrorDownloads }()
+		ctx, cancel := context.WithTimeout(context.Background(), mirrorFileTimeout)
+		defer cancel()
+
+		res := mirrorResult{index: index, size: -1}
+		url := strings.TrimSuffix(mirror, "/") + "/" + f.Filename
+
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			res.err = err
+			return mirrorCheckedMsg(res)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			res.err = fmt.Errorf("%s", resp.Status)
+			return mirrorCheckedMsg(res)
+		}
+		h := sha256.New()
+		if res.size, err = io.Copy(h, resp.Body); err != nil {
+			res.size, res.err = -1, err
+			return mirrorCheckedMsg(res)
+		}
+		res.sha256 = hex.EncodeToString(h.Sum(nil))
+		return mirrorCheckedMsg(res)
+	}
+}
+
+func newMirrorCompareModel(mirror string, release common.GoRelease) mirrorCompareModel {
+	s := newSpinner()
+
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "Platform", Width: 16},
+			{Title: "Kind", Width: 9},
+			{Title: "Upstream", Width: 10},
+			{Title: "Mirror", Width: 10},
+			{Title: "Status", Width: 24},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(16),
+	)
+
+	m := mirrorCompareModel{
+		mirror:  mirror,
+		release: release,
+		results: make([]*mirrorResult, len(release.Files)),
+		pending: len(release.Files),
+		table:   t,
+		spinner: s,
+	}
+	m.table.SetRows(m.rows())
+	return m
+}
+
+func (m mirrorCompareModel) Init() tea.Cmd {
+	cmds := []tea.Cmd{m.spinner.Tick}
+	for i, f := range m.release.Files {
+		cmds = append(cmds, checkMirrorFile(m.mirror, i, f))
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m mirrorCompareModel) status(i int) string {
+	f := m.release.Files[i]
+	r := m.results[i]
+	switch {
+	case r == nil:
+		return "checking..."
+	case r.err != nil:
+		return "✗ missing (" + r.err.Error() + ")"
+	case r.size >= 0 && f.Size > 0 && r.size != f.Size:
+		return "✗ size differs"
+	case r.sha256 != f.Sha256:
+		return "✗ checksum differs"
+	}
+	return "✓ ok"
+}
+
+func (m mirrorCompareModel) rows() []table.Row {
+	rows := make([]table.Row, 0, len(m.release.Files))
+	for i, f := range m.release.Files {
+		platform := f.OS + "/" + f.Arch
+		if f.OS == "" {
+			platform = "-"
+		}
+		mirrorSize := ""
+		if r := m.results[i]; r != nil && r.size >= 0 {
+			mirrorSize = common.FormatSize(r.size)
+		}
+		rows = append(rows, table.Row{platform, f.Kind, common.FormatSize(f.Size), mirrorSize, m.status(i)})
+	}
+	return rows
+}
+
+func (m mirrorCompareModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "ctrl+c", "esc":
+			return m, tea.Quit
+		case "r":
+			m.results = make([]*mirrorResult, len(m.release.Files))
+			m.pending = len(m.release.Files)
+			m.table.SetRows(m.rows())
+			return m, m.Init()
+		}
+	case interruptMsg:
+		return m, tea.Quit
+	case mirrorCheckedMsg:
+		r := mirrorResult(msg)
+		m.results[r.index] = &r
+		m.pending--
+		m.table.SetRows(m.rows())
+		return m, nil
+	case spinner.TickMsg:
+		if m.pending == 0 {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	return m, cmd
+}
+
+func (m mirrorCompareModel) View() string {
+	var sb strings.Builder
+	sb.WriteString(TitleStyle.Render(fmt.Sprintf("%s: go.dev vs %s", m.release.Version, m.mirror)) + "\n")
+	sb.WriteString(m.table.View() + "\n")
+	if m.pending > 0 {
+		sb.WriteString(fmt.Sprintf("%s Downloading and hashing %d files...\n", m.spinner.View(), m.pending))
+	} else {
+		bad := 0
+		for i := range m.release.Files {
+			if strings.HasPrefix(m.status(i), "✗") {
+				bad++
+			}
+		}
+		if bad == 0 {
+			sb.WriteString(SuccessStyle.Render("✓ Mirror matches upstream") + "\n")
+		} else {
+			sb.WriteString(ErrorStyle.Render(fmt.Sprintf("✗ %d stale or corrupted files", bad)) + "\n")
+		}
+	}
+	sb.WriteString(InfoStyle.Render("↑/↓ scroll • r recheck • q quit") + "\n")
+	return sb.String()
+}
+
+// RunMirrorCompare shows how a mirror's copy of a release differs from
+// go.dev. An empty version compares the latest stable release.
+func RunMirrorCompare(mirror, version string) error {
+	release, err := releaseFor(version)
+	if err != nil {
+		return err
+	}
+	_, err = NewProgram(newMirrorCompareModel(mirror, release)).Run()
+	return err
+}
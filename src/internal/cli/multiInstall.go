@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-installer/common"
+	"go-installer/pkg/installer"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// prefetchDownloads bounds the archives of a multi-selection downloaded at
+// the same time.
+const prefetchDownloads = 3
+
+// multiResult is the outcome of one version of a multi-selection.
+type multiResult struct {
+	version string
+	root    string
+	status  string
+	took    time.Duration
+	err     error
+}
+
+// Marked returns the versions marked in the picker the program ended with,
+// nil when a single version was chosen.
+func Marked(m tea.Model) []string {
+	pm, ok := m.(preInstallModel)
+	if !ok || pm.state != preinstallStateDone {
+		return nil
+	}
+	return pm.marked
+}
+
+// prefetchArchives downloads the archives of versions into the cache in
+// parallel, so the installs that follow only extract. Failures are left to
+// the install of the version to report.
+func prefetchArchives(ctx context.Context, releases []common.GoRelease, versions []string, connections int) {
+	sem := make(chan struct{}, prefetchDownloads)
+	var wg sync.WaitGroup
+	for _, v := range versions {
+		_, file, sha, err := common.FindBuild(releases, v, common.GetOS(), common.GetArch())
+		if err != nil || common.IsStored(v) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if _, err := installer.FetchArchive(ctx, file, sha, nil, connections); err == nil {
+				fmt.Fprintln(os.Stderr, InfoStyle.Render("Downloaded "+file))
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// RunMultiInstall installs several versions into the versions store, the
+// downloads in parallel and the extraction one version after the other,
+// and prints a table of the results. The active toolchain is left alone.
+func RunMultiInstall(versions []string, connections int, heartbeat time.Duration) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, InfoStyle.Render(fmt.Sprintf("Downloading %d versions...", len(versions))))
+	prefetchArchives(ctx, releases, versions, connections)
+
+	results := make([]multiResult, 0, len(versions))
+	for i, v := range versions {
+		prefix := filepath.Join(common.VersionsDir, v)
+		r := multiResult{version: v, root: filepath.Join(prefix, "go")}
+		fmt.Fprintln(os.Stderr, TitleStyle.UnsetMarginBottom().Render(fmt.Sprintf("[%d/%d] %s → %s", i+1, len(versions), v, r.root)))
+		if iv, err := common.InstalledVersion(r.root); err == nil && iv == v {
+			r.status = "already installed"
+			results = append(results, r)
+			continue
+		}
+		start := time.Now()
+		r.err = installInto(ctx, releases, v, prefix, heartbeat)
+		r.took = time.Since(start)
+		if errors.Is(ctx.Err(), context.Canceled) {
+			return fmt.Errorf("install %w after %d of %d versions", common.ErrCancelled, i, len(versions))
+		}
+		r.status = "installed"
+		if r.err != nil {
+			r.status = "failed"
+			fmt.Fprintln(os.Stderr, ErrorStyle.Render(fmt.Sprintf("✗ %s: %v", v, r.err)))
+		}
+		results = append(results, r)
+	}
+
+	printMultiResults(results)
+	var failed []string
+	for _, r := range results {
+		if r.err != nil {
+			failed = append(failed, r.version)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d versions failed: %s", len(failed), len(versions), strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Switch with: go-install use VERSION"))
+	return nil
+}
+
+func printMultiResults(results []multiResult) {
+	fmt.Fprintln(os.Stderr, TitleStyle.UnsetMarginBottom().Render("\nSummary"))
+	fmt.Fprintf(os.Stderr, "  %-14s %-18s %8s  %s\n", "VERSION", "RESULT", "TIME", "PATH")
+	for _, r := range results {
+		took := "-"
+		if r.took > 0 {
+			took = r.took.Round(100 * time.Millisecond).String()
+		}
+		line := fmt.Sprintf("  %-14s %-18s %8s  %s", r.version, r.status, took, r.root)
+		switch {
+		case r.err != nil:
+			fmt.Fprintln(os.Stderr, ErrorStyle.Render(line))
+		case r.status == "installed":
+			fmt.Fprintln(os.Stderr, SuccessStyle.Render(line))
+		default:
+			fmt.Fprintln(os.Stderr, line)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"go-installer/common"
+	"go-installer/pkg/installer"
+)
+
+// Options holds the settings collected from the command line.
+type Options struct {
+	Version string
+	// Yes answers every prompt with its default and never shows the picker.
+	Yes bool
+	// Force replaces an existing toolchain without asking for confirmation,
+	// an end-of-life release included.
+	Force bool
+	// Confirmed is set once the user accepted the install summary, which
+	// names the shell file to edit, so the change is applied without
+	// asking again.
+	Confirmed bool
+	// AllowEOL installs releases that no longer get security fixes
+	// without refusing in non-interactive mode.
+	AllowEOL bool
+	// AllowSystemChanges permits installing missing system packages when
+	// running with Yes. Without it missing dependencies are only reported.
+	AllowSystemChanges bool
+	// Deps is the dependency policy, one of the Deps constants.
+	Deps string
+	// NoCgoDeps skips the C toolchain (gcc, make) for pure Go development.
+	NoCgoDeps bool
+	// Dependencies limits the check to these DependencyIDs, nil checks
+	// all and DependencyNone none.
+	Dependencies []string
+	// Workspace sets up ~/go during the configure step: WorkspaceAsk,
+	// common.WorkspacePath, common.WorkspaceExport or WorkspaceSkip.
+	Workspace string
+	// ToolchainPin sets GOTOOLCHAIN=local during the configure step, one of
+	// the Toolchain constants.
+	ToolchainPin string
+	// SymlinkBinaries makes the configure step link go and gofmt into
+	// common.BinLinkDir instead of editing shell configuration files.
+	SymlinkBinaries bool
+	// PackagedGo deals with a Go installed by the package manager, one of
+	// the PackagedGo constants.
+	PackagedGo string
+	// Alternatives registers go and gofmt with update-alternatives after
+	// replacing the toolchain.
+	Alternatives bool
+	// SkipDeps fetches releases right away and only reports missing
+	// dependencies.
+	SkipDeps bool
+	// DiscardArchive removes the archive from the cache after installing
+	// instead of keeping it for reuse.
+	DiscardArchive bool
+	// KeepBackups is how many replaced toolchains are kept for rollback.
+	KeepBackups int
+	// Steps limits the install pipeline to the given steps, nil runs all.
+	Steps []string
+	// PostInstallCmd is a shell command run after a successful install with
+	// the new toolchain first on PATH.
+	PostInstallCmd string
+	// Connections is the number of parallel ranged requests used to
+	// download the archive.
+	Connections int
+	// Prerelease lists release candidates and betas in the picker, which
+	// hides them by default.
+	Prerelease bool
+	// AllReleases lists the full release history in the picker right away
+	// instead of loading it on demand.
+	AllReleases bool
+	// Sinks receive every StepEvent of the install pipeline.
+	Sinks []EventSink
+	// JSON reserves stdout for events, output of the post-install command
+	// goes to stderr instead.
+	JSON bool
+	// AltScreen shows the version picker on the alternate screen.
+	AltScreen bool
+}
+
+const DefaultKeepBackups = installer.DefaultKeepBackups
+
+// Dependency policies for missing system packages.
+const (
+	// DepsAsk prompts before installing them, --yes only reports them.
+	DepsAsk = "ask"
+	// DepsInstall installs them without asking.
+	DepsInstall = "install"
+	// DepsReport never installs them, only reports what is missing.
+	DepsReport = "report"
+)
+
+// Workspace setups besides common.WorkspacePath and WorkspaceExport. Ask
+// offers the setups interactively and skips with --yes.
+const (
+	WorkspaceAsk  = "ask"
+	WorkspaceSkip = "skip"
+)
+
+// WorkspaceModes lists the valid values of Options.Workspace.
+var WorkspaceModes = []string{WorkspaceAsk, common.WorkspacePath, common.WorkspaceExport, WorkspaceSkip}
+
+// Ways to pin GOTOOLCHAIN=local for Go 1.21 and later. Ask offers them
+// interactively and skips with --yes.
+const (
+	ToolchainAsk = "ask"
+	// ToolchainEnv writes it to the user's go env file with go env -w.
+	ToolchainEnv = "env"
+	// ToolchainShell exports it in the shell configuration.
+	ToolchainShell = "shell"
+	ToolchainSkip  = "skip"
+)
+
+// ToolchainPins lists the valid values of Options.ToolchainPin.
+var ToolchainPins = []string{ToolchainAsk, ToolchainEnv, ToolchainShell, ToolchainSkip}
+
+// DepsPolicies lists the valid values of Options.Deps.
+var DepsPolicies = []string{DepsAsk, DepsInstall, DepsReport}
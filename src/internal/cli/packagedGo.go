@@ -0,0 +1,224 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Ways of dealing with another Go distribution, such as the package
+// manager's, snap's or Homebrew's, that comes first on PATH. Ask offers them
+// interactively and only warns with --yes.
+const (
+	PackagedGoAsk = "ask"
+	// PackagedGoRemove removes the ones that can be removed automatically.
+	PackagedGoRemove = "remove"
+	// PackagedGoPrefer puts the new toolchain before them on PATH.
+	PackagedGoPrefer = "prefer"
+	PackagedGoIgnore = "ignore"
+)
+
+// PackagedGoModes lists the valid values of Options.PackagedGo.
+var PackagedGoModes = []string{PackagedGoAsk, PackagedGoRemove, PackagedGoPrefer, PackagedGoIgnore}
+
+// goPackage is how a distribution base packages Go.
+type goPackage struct {
+	name string
+	// query succeeds when the package is installed.
+	query string
+	// bin is the go command the package puts on PATH.
+	bin string
+}
+
+var goPackages = map[string]goPackage{
+	"debian": {name: "golang-go", query: "dpkg-query -W -f=${db:Status-Abbrev} golang-go", bin: "/usr/bin/go"},
+	"ubuntu": {name: "golang-go", query: "dpkg-query -W -f=${db:Status-Abbrev} golang-go", bin: "/usr/bin/go"},
+	"fedora": {name: "golang", query: "rpm -q golang", bin: "/usr/bin/go"},
+	"rhel":   {name: "golang", query: "rpm -q golang", bin: "/usr/bin/go"},
+	"suse":   {name: "go", query: "rpm -q go", bin: "/usr/bin/go"},
+	"arch":   {name: "go", query: "pacman -Q go", bin: "/usr/bin/go"},
+	"alpine": {name: "go", query: "apk info -e go", bin: "/usr/bin/go"},
+	"void":   {name: "go", query: "xbps-query go", bin: "/usr/bin/go"},
+	// Packages live in /usr/local on FreeBSD, as does go-install.
+	"freebsd": {name: "go", query: "pkg info -e go", bin: "/usr/local/bin/go"},
+	"termux":  {name: "golang", query: "dpkg-query -W -f=${db:Status-Abbrev} golang", bin: "/data/data/com.termux/files/usr/bin/go"},
+}
+
+// otherGo is a Go distribution besides go-install's that shadows the new
+// toolchain on PATH.
+type otherGo struct {
+	// source names where it comes from, e.g. "golang-go (apt-get)".
+	source string
+	bin    string
+	// remove removes it, run as the invoking user when asUser is set.
+	// Without it hint says how to get rid of it.
+	remove []string
+	asUser bool
+	hint   string
+}
+
+// findOtherGos returns the go commands on the PATH of the invoking user
+// that come before the new toolchain, in PATH order, so the first one wins.
+// Those of the distribution's package manager, snap, Homebrew and version
+// manager shims are named and can be removed.
+func findOtherGos(distro distroInfo) []otherGo {
+	known := knownGos(distro)
+	own := filepath.Join(common.GoRoot, "bin", "go")
+	var found []otherGo
+	for _, bin := range goBinariesIn(invokingPath()) {
+		if common.SamePath(bin, own) {
+			break
+		}
+		i := slices.IndexFunc(known, func(o otherGo) bool { return common.SamePath(o.bin, bin) })
+		if i < 0 {
+			found = append(found, otherGo{source: "an unknown installation", bin: bin, hint: "remove " + filepath.Dir(bin) + " from PATH or uninstall it"})
+			continue
+		}
+		found = append(found, known[i])
+	}
+	return found
+}
+
+// invokingPath returns the PATH of the invoking user. sudo resets PATH, so
+// it asks their login shell then.
+func invokingPath() []string {
+	path := os.Getenv("PATH")
+	account, err := common.InvokingUser()
+	if err == nil && os.Geteuid() == 0 && account.UID != 0 && account.Shell != "" {
+		var out strings.Builder
+		cmd := account.Command(account.Shell, "-lc", `printf %s "$PATH"`)
+		cmd.Stdout = &out
+		if cmd.Start() == nil {
+			timer := time.AfterFunc(depCheckTimeout, func() { cmd.Process.Kill() })
+			if cmd.Wait() == nil && out.Len() > 0 {
+				path = out.String()
+			}
+			timer.Stop()
+		}
+	}
+	return filepath.SplitList(path)
+}
+
+// knownGos looks for the Go of the distribution's package manager, snap
+// and Homebrew, and the shims of version managers.
+func knownGos(distro distroInfo) []otherGo {
+	var found []otherGo
+	if pkg, ok := findPackagedGo(distro); ok {
+		found = append(found, otherGo{
+			source: fmt.Sprintf("%s (%s)", pkg.name, distro.packageManager),
+			bin:    pkg.bin,
+			remove: append(strings.Fields(distro.removeCmd), pkg.name),
+		})
+	}
+	if isExecutable("/snap/bin/go") {
+		found = append(found, otherGo{source: "snap", bin: "/snap/bin/go", remove: []string{"snap", "remove", "go"}})
+	}
+	brewBins := []string{"/home/linuxbrew/.linuxbrew/bin/go"}
+	if runtime.GOOS == "darwin" {
+		brewBins = []string{"/opt/homebrew/bin/go", "/usr/local/bin/go"}
+	}
+	for _, bin := range brewBins {
+		// Homebrew links its kegs from the Cellar.
+		if target, err := filepath.EvalSymlinks(bin); err == nil && strings.Contains(target, "/Cellar/") {
+			found = append(found, otherGo{
+				source: "Homebrew",
+				bin:    bin,
+				remove: []string{filepath.Join(filepath.Dir(bin), "brew"), "uninstall", "go"},
+				asUser: true,
+			})
+		}
+	}
+	if account, err := common.InvokingUser(); err == nil {
+		shims := []struct{ source, bin, hint string }{
+			{"asdf", filepath.Join(account.Home, ".asdf", "shims", "go"), "asdf uninstall golang, or remove golang from ~/.tool-versions"},
+			{"mise", filepath.Join(account.Home, ".local", "share", "mise", "shims", "go"), "mise uninstall go, or remove go from the mise config"},
+			{"goenv", filepath.Join(account.Home, ".goenv", "shims", "go"), "goenv uninstall, or remove goenv init from your shell configuration"},
+		}
+		for _, s := range shims {
+			if isExecutable(s.bin) {
+				found = append(found, otherGo{source: s.source, bin: s.bin, hint: s.hint})
+			}
+		}
+	}
+	return found
+}
+
+func isExecutable(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir() && fi.Mode()&0111 != 0
+}
+
+// findPackagedGo reports the Go package installed on distro, if any.
+func findPackagedGo(distro distroInfo) (goPackage, bool) {
+	pkg, ok := goPackages[distro.name]
+	if !ok || distro.removeCmd == "" {
+		return goPackage{}, false
+	}
+	parts := strings.Fields(pkg.query)
+	ctx, cancel := context.WithTimeout(context.Background(), depCheckTimeout)
+	defer cancel()
+	out, err := common.CommandContext(ctx, parts[0], parts[1:]...).Output()
+	if err != nil {
+		return goPackage{}, false
+	}
+	// dpkg also knows removed packages whose configuration is left.
+	if strings.HasPrefix(parts[0], "dpkg") && !strings.HasPrefix(string(out), "ii") {
+		return goPackage{}, false
+	}
+	return pkg, true
+}
+
+// otherGoNotice explains which Go wins over the new toolchain.
+func otherGoNotice(others []otherGo) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("! Other Go installations come before %s on PATH:", filepath.Join(common.GoRoot, "bin")))
+	for _, o := range others {
+		sb.WriteString(fmt.Sprintf("\n  • %s from %s", o.bin, o.source))
+	}
+	sb.WriteString(fmt.Sprintf("\n  %s comes first on PATH and wins in new shells unless the new toolchain goes first.", others[0].bin))
+	return sb.String()
+}
+
+type otherGosRemovedMsg struct {
+	// hints say how to remove the ones that were left in place.
+	hints []string
+	err   error
+}
+
+// removeOtherGos removes those of others that have a remove command.
+func removeOtherGos(others []otherGo) tea.Cmd {
+	return func() tea.Msg {
+		var msg otherGosRemovedMsg
+		for _, o := range others {
+			if o.remove == nil {
+				msg.hints = append(msg.hints, fmt.Sprintf("! %s from %s was left in place: %s", o.bin, o.source, o.hint))
+				continue
+			}
+			var cmd *exec.Cmd
+			if o.asUser {
+				// Homebrew refuses to run as root.
+				account, err := common.InvokingUser()
+				if err != nil {
+					return otherGosRemovedMsg{err: err}
+				}
+				cmd = account.Command(o.remove[0], o.remove[1:]...)
+			} else {
+				cmd = common.Privileged(o.remove[0], o.remove[1:]...)
+			}
+			if out, err := cmd.CombinedOutput(); err != nil {
+				common.Log.Error("removing other Go failed", "source", o.source, "output", string(out))
+				return otherGosRemovedMsg{err: fmt.Errorf("failed to remove %s from %s: %w", o.bin, o.source, err)}
+			}
+		}
+		return msg
+	}
+}
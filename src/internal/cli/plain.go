@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// plain is set by SetPlain.
+var plain bool
+
+// plainSymbols spells out the symbols and emoji of messages in plain mode.
+var plainSymbols = strings.NewReplacer(
+	"⚠️", "[warning]",
+	"✓", "[ok]",
+	"✗", "[error]",
+	"⬆", "[update]",
+	"•", "-",
+	"·", "-",
+	"→", "->",
+	"←", "<-",
+	"↑", "up",
+	"↓", "down",
+)
+
+// stepLabels name the steps in plain output.
+var stepLabels = map[string]string{
+	StepDependencies: "Check dependencies",
+	StepReleases:     "Fetch releases",
+	StepDownload:     "Download archive",
+	StepVerify:       "Verify checksum",
+	StepExtract:      "Extract archive",
+	StepReplace:      "Replace previous installation",
+	StepSmokeTest:    "Smoke test go version",
+	StepConfigure:    "Configure environment",
+	StepPostInstall:  "Run post-install command",
+	StepInstall:      "Install",
+}
+
+// stepLabel names the step of e in plain output.
+func stepLabel(e StepEvent) string {
+	switch {
+	case e.Tool != "":
+		return "Install " + e.Tool
+	case stepLabels[e.Step] != "":
+		return stepLabels[e.Step]
+	}
+	return e.Step
+}
+
+// PlainRequested reports whether the terminal cannot render the TUI, TERM
+// is dumb.
+func PlainRequested() bool {
+	return os.Getenv("TERM") == "dumb"
+}
+
+// SetPlain renders every message as plain ASCII without colors, spinners
+// or emoji, for screen readers and dumb terminals.
+func SetPlain() {
+	plain = true
+	applyPalette()
+}
+
+// NewPlainSink writes a line to w whenever a step starts, finishes or
+// fails, and one for every tenth of a transfer, instead of redrawing a
+// view.
+func NewPlainSink(w io.Writer) EventSink {
+	var mu sync.Mutex
+	started := map[string]time.Time{}
+	tenths := map[string]int{}
+	return func(e StepEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+		label := stepLabel(e)
+		switch e.Status {
+		case StatusStarted:
+			started[e.Step] = e.Time
+			fmt.Fprintf(w, "%s...\n", label)
+		case StatusProgress:
+			if e.Total <= 0 {
+				return
+			}
+			if n := int(e.Bytes * 10 / e.Total); n > tenths[e.Step] {
+				tenths[e.Step] = n
+				fmt.Fprintf(w, "%s: %d%% (%s of %s)\n", label, n*10, common.FormatSize(e.Bytes), common.FormatSize(e.Total))
+			}
+		case StatusDone:
+			switch {
+			case e.Step == StepSummary:
+				fmt.Fprintln(w, "Summary:")
+				for _, r := range e.rows {
+					fmt.Fprintf(w, "  %-14s %s\n", r.label, plainSymbols.Replace(r.value))
+				}
+			case e.Step == StepInstall && e.Version != "":
+				fmt.Fprintf(w, "[ok] Installed %s\n", e.Version)
+			case !started[e.Step].IsZero():
+				fmt.Fprintf(w, "[ok] %s done in %s\n", label, elapsed(e.Time.Sub(started[e.Step])))
+			default:
+				fmt.Fprintf(w, "[ok] %s done\n", label)
+			}
+		case StatusFailed:
+			fmt.Fprintf(w, "[error] %s failed: %s\n", label, e.Error)
+		}
+	}
+}
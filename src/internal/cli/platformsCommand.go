@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"cmp"
+	"fmt"
+	"go-installer/common"
+	"slices"
+)
+
+// releaseFor returns the release of version, or the latest stable one when
+// version is empty.
+func releaseFor(version string) (common.GoRelease, error) {
+	releases, err := getReleases()
+	if err != nil {
+		return common.GoRelease{}, err
+	}
+	if version == "" {
+		return common.LatestStable(releases)
+	}
+	version = common.NormalizeVersion(version)
+	for _, r := range releases {
+		if r.Version == version {
+			return r, nil
+		}
+	}
+	return common.GoRelease{}, fmt.Errorf("%w: %s", common.ErrVersionNotFound, version)
+}
+
+// RunPlatforms lists every OS/arch archive published for version, marking
+// the one that would be installed here.
+func RunPlatforms(version string) error {
+	release, err := releaseFor(version)
+	if err != nil {
+		return err
+	}
+	var archives []common.GoFile
+	for _, f := range release.Files {
+		if f.Kind == "archive" {
+			archives = append(archives, f)
+		}
+	}
+	slices.SortFunc(archives, func(a, b common.GoFile) int {
+		return cmp.Or(cmp.Compare(a.OS, b.OS), cmp.Compare(a.Arch, b.Arch))
+	})
+	_, local, _, _ := common.FindBuild([]common.GoRelease{release}, release.Version, common.GetOS(), common.GetArch())
+
+	fmt.Println(InfoStyle.Render(fmt.Sprintf("Archives of %s:", release.Version)))
+	for _, f := range archives {
+		line := fmt.Sprintf("  %-18s %-40s %10s", f.OS+"/"+f.Arch, f.Filename, common.FormatSize(f.Size))
+		if f.Filename == local {
+			line = SuccessStyle.Render(line + "  ← this system")
+		}
+		fmt.Println(line)
+	}
+	if local == "" {
+		fmt.Println(InfoStyle.Render(fmt.Sprintf("\nNone of them runs on this system (%s/%s).", common.GetOS(), common.GetArch())))
+	}
+	return nil
+}
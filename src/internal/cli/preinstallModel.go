@@ -1,47 +1,108 @@
 package cli
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"go-installer/common"
-	"net/http"
 	"os"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
 
-func fetchReleases() tea.Msg {
-	resp, err := http.Get("https://go.dev/dl/?mode=json&include=all")
-	if err != nil {
-		return fetchedMsg{err: err}
-	}
-	defer resp.Body.Close()
+// getReleases returns the complete release history.
+func getReleases() ([]common.GoRelease, error) {
+	return common.FetchReleases(true)
+}
 
-	var releases []common.GoRelease
-	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
-		return fetchedMsg{err: err}
+func fetchReleases(all bool) tea.Cmd {
+	return func() tea.Msg {
+		releases, err := common.FetchReleases(all)
+		if err != nil {
+			return fetchedMsg{all: all, err: err}
+		}
+		return fetchedMsg{all: all, releases: releases}
 	}
-
-	return fetchedMsg{releases: releases}
 }
 
 type item struct {
 	title, desc string
+	// version is the release the item installs, title may carry a badge.
+	version string
 }
 
 func (i item) Title() string       { return i.title }
 func (i item) Description() string { return i.desc }
-func (i item) FilterValue() string { return i.title }
+func (i item) FilterValue() string { return i.version }
+
+var (
+	togglePrerelease = key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "toggle rc/beta"))
+	toggleMark       = key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "mark"))
+)
+
+// moreItem ends the version list until the full history is loaded.
+type moreItem struct{}
+
+func (moreItem) Title() string       { return "Older releases..." }
+func (moreItem) Description() string { return "Load the full release history" }
+func (moreItem) FilterValue() string { return "" }
 
 type fetchedMsg struct {
 	releases []common.GoRelease
+	all      bool
 	err      error
 }
 
+// datesMsg carries the publication dates of release archives by version.
+type datesMsg map[string]time.Time
+
+// datedReleases bounds how many releases get a date, each costs a HEAD
+// request.
+const datedReleases = 30
+
+// fetchDates looks up when the archives of releases for goos/arch were
+// published. Releases whose date cannot be found are left out.
+func fetchDates(releases []common.GoRelease, goos, arch string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		dates := datesMsg{}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, 8)
+		for _, r := range releases {
+			_, file, _, err := common.FindBuild([]common.GoRelease{r}, r.Version, goos, arch)
+			if err != nil {
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				date, err := common.ReleaseDate(ctx, file)
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				dates[r.Version] = date
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		return dates
+	}
+}
+
 type installCompleteMsg struct {
 	err error
 }
@@ -54,7 +115,12 @@ const (
 	preinstallStateInstallingDeps
 	preinstallStateFetching
 	preinstallStateSelectVersion
-	preinstallStateConfirmOverride
+	preinstallStateDetails
+	preinstallStateNotes
+	preinstallStateConfirmSummary
+	preinstallStateConfirmFallback
+	preinstallStateConfirmOtherGo
+	preinstallStateRemovingOtherGo
 	preinstallStateInstalling
 	preinstallStateDone
 	preinstallStateError
@@ -64,141 +130,429 @@ type preInstallModel struct {
 	state       preinstallState
 	releases    []common.GoRelease
 	list        list.Model
+	details     table.Model
+	notes       viewport.Model
 	spinner     spinner.Model
 	selectedVer string
 	targetOS    string
 	targetArch  string
 	err         error
+	// aborted is set when the user quit without installing.
+	aborted     bool
+	opts        Options
+	fallbackVer string
+	banner      string
+	// allLoaded is set once the full release history replaced the
+	// default set of supported releases.
+	allLoaded  bool
+	loadingAll bool
+	// width and height of the terminal, filled by the picker on the
+	// alternate screen.
+	width, height int
+	// browserErr is why the release notes could not be opened in a
+	// browser.
+	browserErr error
+
+	// dates are the publication dates of releases, filled in the
+	// background once the picker is shown.
+	dates map[string]time.Time
+	// installed lists the toolchains on this host, active the version of
+	// the go command on PATH.
+	installed []string
+	active    string
+	// marked are the versions picked with space, in marking order, to be
+	// installed together into the versions store.
+	marked []string
+
+	// summary is what the install changes, confirmed before starting.
+	summary string
 
 	missingDeps []dependency
 	distro      distroInfo
+	// otherGos shadow the new toolchain on PATH, othersChecked is set
+	// once they were looked for.
+	otherGos      []otherGo
+	othersChecked bool
 }
 
-func NewPreInstallModel(version string) preInstallModel {
-	s := spinner.New()
-	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(lipgloss.Color("205"))
+func NewPreInstallModel(opts Options) preInstallModel {
+	s := newSpinner()
 
+	state := preinstallStateCheckingDeps
+	if opts.SkipDeps {
+		state = preinstallStateFetching
+	}
 	return preInstallModel{
-		state:       preinstallStateCheckingDeps,
+		state:       state,
 		targetOS:    common.GetOS(),
 		targetArch:  common.GetArch(),
 		spinner:     s,
-		selectedVer: common.NormalizeVersion(version),
+		selectedVer: common.NormalizeVersion(opts.Version),
+		opts:        opts,
 	}
 }
 
 func (m preInstallModel) Init() tea.Cmd {
-	return tea.Batch(
-		m.spinner.Tick,
-		checkDependencies,
-	)
+	publish(m.opts.Sinks, stepStarted(StepDependencies))
+	cmds := []tea.Cmd{m.spinner.Tick, checkDependencies(m.opts)}
+	if m.opts.SkipDeps {
+		publish(m.opts.Sinks, stepDone(StepDependencies, stepResult{}))
+		publish(m.opts.Sinks, stepStarted(StepReleases))
+		cmds = []tea.Cmd{m.spinner.Tick, fetchReleases(m.opts.AllReleases), skipDependencies(m.opts)}
+	}
+	for _, notice := range []string{rosettaNotice(), wslNotice(), amd64Notice()} {
+		if notice != "" {
+			cmds = append(cmds, tea.Println(InfoStyle.Render(notice)))
+		}
+	}
+	return tea.Batch(cmds...)
 }
 
+// Update shows the picker on the alternate screen when enabled, so the
+// scrollback only keeps the install progress and its summary.
 func (m preInstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	if !m.opts.AltScreen {
+		return next, cmd
+	}
+	nm, ok := next.(preInstallModel)
+	wasPicker := m.onPicker()
+	isPicker := ok && nm.onPicker()
+	switch {
+	case !wasPicker && isPicker:
+		return next, tea.Batch(tea.EnterAltScreen, cmd)
+	case wasPicker && !isPicker:
+		return next, tea.Sequence(tea.ExitAltScreen, cmd)
+	}
+	return next, cmd
+}
+
+// onPicker reports whether the model shows the picker or the details of
+// one of its versions.
+func (m preInstallModel) onPicker() bool {
+	return m.state == preinstallStateSelectVersion || m.state == preinstallStateDetails || m.state == preinstallStateNotes
+}
+
+func (m preInstallModel) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		if m.onPicker() {
+			m.list.SetSize(m.listSize())
+		}
+		if m.state == preinstallStateDetails {
+			m.details.SetHeight(m.detailsHeight())
+		}
+		if m.state == preinstallStateNotes {
+			m.notes.Width, m.notes.Height = m.notesSize()
+		}
+		return m, nil
+
+	case interruptMsg:
+		return m.abort()
+
 	case tea.KeyMsg:
 		switch m.state {
 		case preinstallStateConfirmInstallDeps:
-			switch msg.String() {
-			case "y", "Y":
+			if key := msg.String(); key == "q" || key == "ctrl+c" {
+				return m.abort()
+			}
+			switch parseAnswer(msg.String(), !m.distro.slow) {
+			case answerYes:
 				m.state = preinstallStateInstallingDeps
 				return m, tea.Batch(
 					m.spinner.Tick,
 					installDependencies(m.distro, m.missingDeps),
 				)
-			case "n", "N":
+			case answerNo:
+				if m.distro.slow || !anyRequired(m.missingDeps) {
+					next, cmd := m.fetch()
+					return next, tea.Batch(tea.Println(m.missingDepsReport()), cmd)
+				}
 				m.state = preinstallStateError
 				m.err = fmt.Errorf("dependencies are required for Go installation")
 				return m, tea.Quit
-			case "q", "ctrl+c":
-				return m, tea.Quit
 			}
 
 		case preinstallStateSelectVersion:
 			switch msg.String() {
 			case "ctrl+c", "q":
-				return m, tea.Quit
-			case "enter":
+				return m.abort()
+			case "x":
+				if m.banner != "" && m.list.FilterState() != list.Filtering {
+					m.banner = ""
+					return m, nil
+				}
+			case "p":
+				if m.list.FilterState() != list.Filtering {
+					m.opts.Prerelease = !m.opts.Prerelease
+					return m, m.refreshItems()
+				}
+			case " ":
 				i, ok := m.list.SelectedItem().(item)
-				if ok {
-					m.selectedVer = i.title
-					if _, err := os.Stat("/usr/local/go"); err == nil {
-						m.state = preinstallStateConfirmOverride
-						return m, nil
+				if ok && m.list.FilterState() != list.Filtering {
+					if n := slices.Index(m.marked, i.version); n >= 0 {
+						m.marked = slices.Delete(m.marked, n, n+1)
+					} else {
+						m.marked = append(m.marked, i.version)
 					}
-					return m.startInstallation()
+					return m, m.refreshItems()
+				}
+			case "enter", "tab":
+				if msg.String() == "enter" && len(m.marked) > 0 && m.list.FilterState() != list.Filtering {
+					// The installs run after the program, see RunMultiInstall.
+					m.state = preinstallStateDone
+					return m, tea.Quit
+				}
+				i, ok := m.list.SelectedItem().(item)
+				if ok {
+					r, _ := m.release(i.version)
+					m.selectedVer = i.version
+					m.details = newDetailsTable(r, m.targetOS, m.targetArch, m.detailsHeight())
+					m.state = preinstallStateDetails
+					return m, nil
 				}
 			}
 
-		case preinstallStateConfirmOverride:
+		case preinstallStateDetails:
 			switch msg.String() {
-			case "y", "Y":
+			case "ctrl+c", "q":
+				return m.abort()
+			case "esc", "backspace", "tab":
+				m.state = preinstallStateSelectVersion
+				return m, nil
+			case "r":
+				m.notes = m.newNotesViewport()
+				m.browserErr = nil
+				m.state = preinstallStateNotes
+				return m, fetchReleaseNotes(m.selectedVer, m.active)
+			case "o":
+				return m, openURL(releaseNotesURL(m.selectedVer))
+			case "enter":
+				return m.installSelected()
+			}
+			var cmd tea.Cmd
+			m.details, cmd = m.details.Update(msg)
+			return m, cmd
+
+		case preinstallStateNotes:
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m.abort()
+			case "esc", "backspace", "r":
+				m.state = preinstallStateDetails
+				return m, nil
+			case "o":
+				return m, openURL(releaseNotesURL(m.selectedVer))
+			case "enter":
+				return m.installSelected()
+			}
+			var cmd tea.Cmd
+			m.notes, cmd = m.notes.Update(msg)
+			return m, cmd
+
+		case preinstallStateConfirmSummary:
+			if key := msg.String(); key == "q" || key == "ctrl+c" {
+				return m.abort()
+			}
+			switch parseAnswer(msg.String(), true) {
+			case answerYes:
+				m.opts.Confirmed = true
 				return m.startInstallation()
-			case "n", "N", "q", "ctrl+c":
-				return m, tea.Quit
+			case answerNo:
+				return m.abort()
+			}
+
+		case preinstallStateConfirmOtherGo:
+			switch msg.String() {
+			case "q", "ctrl+c":
+				return m.abort()
+			case "r":
+				return m.removeOtherGos()
+			case "p", "enter", "y":
+				common.PreferGoRoot = true
+				return m.install()
+			case "i", "n":
+				return m.install()
+			}
+
+		case preinstallStateConfirmFallback:
+			if key := msg.String(); key == "q" || key == "ctrl+c" {
+				return m.abort()
 			}
+			switch parseAnswer(msg.String(), true) {
+			case answerYes:
+				m.selectedVer = m.fallbackVer
+				return m.startInstallation()
+			case answerNo:
+				return m.abort()
+			}
+		}
+
+	case depsSkippedMsg:
+		if notice := timeoutNotice(depsCheckMsg(msg)); notice != "" {
+			msg.timedOut = nil
+			next, cmd := m.Update(msg)
+			return next, tea.Batch(tea.Println(notice), cmd)
+		}
+		if len(msg.missing) == 0 {
+			return m, nil
+		}
+		names := make([]string, 0, len(msg.missing))
+		for _, dep := range msg.missing {
+			names = append(names, dep.name)
 		}
+		return m, tea.Println(InfoStyle.Render(fmt.Sprintf(
+			"! Missing dependencies: %s (skipped with --skip-deps)", strings.Join(names, ", "))))
 
 	case depsCheckMsg:
 		if msg.err != nil {
+			publish(m.opts.Sinks, stepFailed(StepDependencies, msg.err))
 			m.err = msg.err
 			m.state = preinstallStateError
 			return m, tea.Quit
 		}
+		if notice := timeoutNotice(msg); notice != "" {
+			msg.timedOut = nil
+			next, cmd := m.Update(msg)
+			return next, tea.Batch(tea.Println(notice), cmd)
+		}
 
 		m.distro = msg.distro
 
 		if len(msg.missing) == 0 {
 			// All dependencies satisfied, proceed to fetching releases
-			m.state = preinstallStateFetching
-			return m, tea.Batch(
-				m.spinner.Tick,
-				fetchReleases,
-			)
+			return m.fetch()
 		}
 
 		// Some dependencies are missing
 		m.missingDeps = msg.missing
+		if m.distro.guidance != nil {
+			// Nothing may be installed here, explain how to get them.
+			next, cmd := m.fetch()
+			return next, tea.Batch(tea.Println(m.missingDepsGuidance()), cmd)
+		}
+		if m.opts.Deps == DepsInstall || (m.opts.Yes && m.opts.AllowSystemChanges) {
+			m.state = preinstallStateInstallingDeps
+			return m, tea.Batch(
+				m.spinner.Tick,
+				installDependencies(m.distro, m.missingDeps),
+			)
+		}
+		if m.opts.Yes || m.opts.Deps == DepsReport {
+			// Silent mode must not touch system packages without an explicit
+			// acknowledgment, so only report what is missing.
+			next, cmd := m.fetch()
+			return next, tea.Batch(tea.Println(m.missingDepsReport()), cmd)
+		}
 		m.state = preinstallStateConfirmInstallDeps
 		return m, nil
 
 	case fetchedMsg:
+		if m.onPicker() {
+			// Lazily loaded history, keep the picker usable if it failed.
+			m.loadingAll = false
+			if msg.err != nil {
+				m.banner = "Could not load older releases: " + msg.err.Error()
+				return m, nil
+			}
+			m.releases = msg.releases
+			m.allLoaded = true
+			cmd := m.refreshItems()
+			return m, tea.Batch(cmd, m.fetchDates())
+		}
+
 		if msg.err != nil {
+			publish(m.opts.Sinks, stepFailed(StepReleases, msg.err))
 			m.err = msg.err
 			m.state = preinstallStateError
 			return m, tea.Quit
 		}
 
+		publish(m.opts.Sinks, stepDone(StepReleases, stepResult{}))
 		m.releases = msg.releases
+		m.allLoaded = msg.all
+
+		if m.selectedVer != "" && !m.allLoaded && !m.versionExists(m.selectedVer) {
+			// Only supported releases are listed by default.
+			publish(m.opts.Sinks, stepStarted(StepReleases))
+			return m, fetchReleases(true)
+		}
+
+		if m.selectedVer == "" && m.opts.Yes {
+			latest, err := common.LatestStable(m.releases)
+			if err != nil {
+				m.err = err
+				m.state = preinstallStateError
+				return m, tea.Quit
+			}
+			m.selectedVer = latest.Version
+		}
 
 		if m.selectedVer != "" {
-			if _, _, _, err := common.FindBuild(m.releases, m.selectedVer, m.targetOS, m.targetArch); err == nil {
-				if _, err := os.Stat("/usr/local/go"); err == nil {
-					m.state = preinstallStateConfirmOverride
-					return m, nil
-				}
+			_, _, _, err := common.FindBuild(m.releases, m.selectedVer, m.targetOS, m.targetArch)
+			if err == nil {
 				return m.startInstallation()
 			}
+			if m.versionExists(m.selectedVer) {
+				return m.offerFallback(err)
+			}
+			if m.opts.Yes {
+				m.err = err
+				m.state = preinstallStateError
+				return m, tea.Quit
+			}
 		}
 
-		items := make([]list.Item, 0, len(m.releases))
-		for i, r := range m.releases {
-			desc := "Go release"
-			if i == 0 {
-				desc = "Latest stable release"
+		if installed, err := common.ActiveGoVersion(); err == nil {
+			if latest, ok := common.NewerStable(m.releases, installed); ok {
+				m.banner = updateNotice(latest.Version, installed)
 			}
-			items = append(items, item{title: r.Version, desc: desc})
 		}
 
-		l := list.New(items, list.NewDefaultDelegate(), 60, 14)
+		m.installed, m.active = installedVersions()
+		w, h := m.listSize()
+		l := list.New(m.releaseItems(), list.NewDefaultDelegate(), w, h)
 		l.Title = "Select Go Version"
 		l.SetShowStatusBar(false)
 		l.SetFilteringEnabled(true)
+		l.Filter = versionFilter
 		l.Styles.Title = TitleStyle
+		l.AdditionalShortHelpKeys = func() []key.Binding { return []key.Binding{selectKey, showDetails, toggleMark, togglePrerelease} }
 
 		m.list = l
 		m.state = preinstallStateSelectVersion
+		return m, m.fetchDates()
+
+	case datesMsg:
+		if m.dates == nil {
+			m.dates = map[string]time.Time{}
+		}
+		for v, d := range msg {
+			m.dates[v] = d
+		}
+		if m.onPicker() {
+			return m, m.refreshItems()
+		}
+		return m, nil
+
+	case releaseNotesMsg:
+		if m.state == preinstallStateNotes && msg.version == m.selectedVer {
+			m = m.setNotes(msg)
+		}
+		return m, nil
+
+	case browserOpenedMsg:
+		m.browserErr = msg.err
+		return m, nil
+
+	case reselectMsg:
+		for i, it := range m.list.VisibleItems() {
+			if it, ok := it.(item); ok && it.version == string(msg) {
+				m.list.Select(i)
+				break
+			}
+		}
 		return m, nil
 
 	case installCompleteMsg:
@@ -209,33 +563,52 @@ func (m preInstallModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = preinstallStateDone
 		}
 		return m, tea.Quit
+	case otherGosRemovedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.state = preinstallStateError
+			return m, tea.Quit
+		}
+		next, cmd := m.install()
+		var cmds []tea.Cmd
+		for _, hint := range msg.hints {
+			cmds = append(cmds, tea.Println(InfoStyle.Render(hint)))
+		}
+		return next, tea.Sequence(append(cmds, cmd)...)
+
 	case depsInstallMsg:
 		if msg.err != nil {
+			publish(m.opts.Sinks, stepFailed(StepDependencies, msg.err))
 			m.err = msg.err
 			m.state = preinstallStateError
 			return m, tea.Quit
 		}
 
 		// Dependencies installed successfully, proceed to fetching releases
-		m.state = preinstallStateFetching
-		return m, tea.Batch(
-			m.spinner.Tick,
-			fetchReleases,
-		)
+		return m.fetch()
 
 	case spinner.TickMsg:
 		if m.state == preinstallStateCheckingDeps ||
 			m.state == preinstallStateInstallingDeps ||
-			m.state == preinstallStateFetching {
+			m.state == preinstallStateFetching ||
+			m.state == preinstallStateRemovingOtherGo ||
+			m.loadingAll {
 			var cmd tea.Cmd
 			m.spinner, cmd = m.spinner.Update(msg)
 			return m, cmd
 		}
 	}
 
-	if m.state == preinstallStateSelectVersion {
+	if m.onPicker() {
 		var cmd tea.Cmd
 		m.list, cmd = m.list.Update(msg)
+		// Load the full history once the user scrolls past the supported
+		// releases or starts searching for a version.
+		_, atEnd := m.list.SelectedItem().(moreItem)
+		if !m.allLoaded && !m.loadingAll && (atEnd || m.list.FilterState() != list.Unfiltered) {
+			m.loadingAll = true
+			return m, tea.Batch(cmd, m.spinner.Tick, fetchReleases(true))
+		}
 		return m, cmd
 	}
 
@@ -250,7 +623,7 @@ func (m preInstallModel) View() string {
 	case preinstallStateConfirmInstallDeps:
 		var sb strings.Builder
 		sb.WriteString(TitleStyle.Render("⚠️  Missing Dependencies") + "\n\n")
-		sb.WriteString("The following dependencies are required:\n\n")
+		sb.WriteString("The following dependencies are missing:\n\n")
 
 		for _, dep := range m.missingDeps {
 			status := "recommended"
@@ -261,35 +634,36 @@ func (m preInstallModel) View() string {
 		}
 
 		sb.WriteString("\nDetected system: ")
-		sb.WriteString(lipgloss.NewStyle().Bold(true).Render(m.distro.name))
+		sb.WriteString(lipgloss.NewStyle().Bold(true).Render(m.distro.displayName()))
 		sb.WriteString(" (")
 		sb.WriteString(m.distro.packageManager)
 		sb.WriteString(")\n\n")
 
-		// Show install command
-		packages := make(map[string]bool)
-		for _, dep := range m.missingDeps {
-			if pkgName, ok := dep.packageName[m.distro.name]; ok {
-				for _, pkg := range strings.Fields(pkgName) {
-					packages[pkg] = true
-				}
-			}
-		}
-		var pkgList []string
-		for pkg := range packages {
-			pkgList = append(pkgList, pkg)
-		}
-
-		installCommand := fmt.Sprintf("sudo %s %s",
+		installCommand := fmt.Sprintf("%s %s",
 			m.distro.installCmd,
-			strings.Join(pkgList, " "))
+			strings.Join(m.distro.packages(m.missingDeps), " "))
+		if common.NeedsRoot() {
+			installCommand = common.Elevator() + " " + installCommand
+		}
 
 		sb.WriteString("Install command:\n")
-		sb.WriteString(lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240")).
-			Render(fmt.Sprintf("  %s", installCommand)))
+		sb.WriteString(mutedStyle.Render(fmt.Sprintf("  %s", installCommand)))
 		sb.WriteString("\n\n")
-		sb.WriteString("Install dependencies now? (y/n): ")
+		if !m.distro.slow && !anyRequired(m.missingDeps) {
+			sb.WriteString(InfoStyle.Render("Declining continues without them; pure Go code builds fine, cgo and make need them.") + "\n\n")
+		}
+		if m.distro.slow {
+			sb.WriteString(InfoStyle.Render(m.distro.packageManager+" builds packages from source, which can take hours.\nDeclining continues without them; Go installs fine but cgo and make need them.") + "\n\n")
+			sb.WriteString("Install dependencies now? " + yesNoHint(false) + ": \n")
+			sb.WriteString(helpFooter(answerKeys("install", "continue", false)))
+			return sb.String()
+		}
+		sb.WriteString("Install dependencies now? " + yesNoHint(true) + ": \n")
+		no := "continue"
+		if anyRequired(m.missingDeps) {
+			no = "abort"
+		}
+		sb.WriteString(helpFooter(answerKeys("install", no, true)))
 
 		return sb.String()
 
@@ -300,10 +674,61 @@ func (m preInstallModel) View() string {
 		return fmt.Sprintf("\n%s Fetching Go releases metadata...\n", m.spinner.View())
 
 	case preinstallStateSelectVersion:
-		return "\n" + m.list.View()
+		view := "\n" + m.list.View()
+		if m.banner != "" {
+			view = "\n" + TitleStyle.Render("⬆  "+m.banner) + InfoStyle.Render("  (x to dismiss)") + view
+		}
+		if m.loadingAll {
+			view += fmt.Sprintf("\n%s Loading older releases...", m.spinner.View())
+		}
+		if len(m.marked) > 0 {
+			view += "\n" + InfoStyle.Render(fmt.Sprintf("%d marked (%s), enter installs them side by side",
+				len(m.marked), strings.Join(m.marked, ", ")))
+		}
+		return view
+
+	case preinstallStateDetails:
+		return "\n" + m.detailsView()
 
-	case preinstallStateConfirmOverride:
-		return TitleStyle.Render("⚠️  /usr/local/go already exists. Override? (y/n): ")
+	case preinstallStateNotes:
+		return "\n" + m.notesView()
+
+	case preinstallStateConfirmSummary:
+		return TitleStyle.Render("Ready to install") + "\n" + m.summary +
+			TitleStyle.Render("Proceed? "+yesNoHint(true)+": ") +
+			helpFooter(answerKeys("install", "abort", true))
+
+	case preinstallStateConfirmFallback:
+		var sb strings.Builder
+		sb.WriteString(TitleStyle.Render(fmt.Sprintf("⚠️  %s has no %s/%s archive", m.selectedVer, m.targetOS, m.targetArch)) + "\n")
+		sb.WriteString(fmt.Sprintf("The last release published for %s/%s is %s.\n\n", m.targetOS, m.targetArch, m.fallbackVer))
+		sb.WriteString(fmt.Sprintf("Install %s instead? %s: \n", m.fallbackVer, yesNoHint(true)))
+		sb.WriteString(helpFooter(answerKeys("install "+m.fallbackVer, "abort", true)))
+		return sb.String()
+
+	case preinstallStateConfirmOtherGo:
+		var sb strings.Builder
+		sb.WriteString(TitleStyle.Render("⚠️  Go is installed elsewhere too") + "\n")
+		sb.WriteString(InfoStyle.Render(strings.TrimPrefix(otherGoNotice(m.otherGos), "! ")) + "\n\n")
+		sb.WriteString("  r) remove them")
+		for _, o := range m.otherGos {
+			if o.remove == nil {
+				sb.WriteString(" (" + o.source + " only explained)")
+			}
+		}
+		sb.WriteString("\n  p) put the new toolchain first on PATH\n")
+		sb.WriteString("  i) ignore\n")
+		sb.WriteString(TitleStyle.Render("How to proceed? [r/p/i] (default p): "))
+		sb.WriteString(helpFooter(keyHelp{
+			key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "remove")),
+			key.NewBinding(key.WithKeys("p", "enter"), key.WithHelp("p/enter", "put first")),
+			key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "ignore")),
+			quitKey,
+		}))
+		return sb.String()
+
+	case preinstallStateRemovingOtherGo:
+		return fmt.Sprintf("\n%s Removing other Go installations...\n", m.spinner.View())
 
 	case preinstallStateInstalling:
 		return "" // Install model handles its own view
@@ -312,14 +737,288 @@ func (m preInstallModel) View() string {
 		return ErrorStyle.Render(fmt.Sprintf("\n✗ Error: %v\n\n", m.err))
 
 	case preinstallStateDone:
-		return SuccessStyle.Render(fmt.Sprintf("\n✓ Successfully installed %s to /usr/local/go\n\n", m.selectedVer))
+		if len(m.marked) > 0 {
+			return ""
+		}
+		return SuccessStyle.Render(fmt.Sprintf("\n✓ Successfully installed %s to %s\n\n", m.selectedVer, common.GoRoot))
 	}
 
 	return ""
 }
 
+func (m preInstallModel) listSize() (int, int) {
+	if !m.opts.AltScreen || m.height == 0 {
+		return 60, 14
+	}
+	// Leave room for the banner, the loading line and the marks.
+	return m.width, m.height - 4
+}
+
+func (m preInstallModel) releaseItems() []list.Item {
+	items := make([]list.Item, 0, len(m.releases)+1)
+	latest, _ := common.LatestStable(m.releases)
+	for _, r := range m.releases {
+		if !r.Stable && !m.opts.Prerelease {
+			continue
+		}
+		title := r.Version
+		parts := []string{releaseStatus(r, latest)}
+		switch v, _ := common.ParseVersion(r.Version); {
+		case strings.HasPrefix(v.Pre, "rc"):
+			title += " " + prereleaseBadge.Render("[rc]")
+		case strings.HasPrefix(v.Pre, "beta"):
+			title += " " + prereleaseBadge.Render("[beta]")
+		case common.EndOfLife(m.releases, r.Version):
+			title += " " + eolBadge.Render("[eol]")
+		}
+		if date, ok := m.dates[r.Version]; ok {
+			parts = append(parts, date.Format("2006-01-02"))
+		}
+		if release, file, _, err := common.FindBuild(m.releases, r.Version, m.targetOS, m.targetArch); err != nil {
+			parts = append(parts, fmt.Sprintf("no %s/%s archive", m.targetOS, m.targetArch))
+		} else if f, ok := release.File(file); ok {
+			parts = append(parts, common.FormatSize(f.Size))
+		}
+		if slices.Contains(m.marked, r.Version) {
+			title = markBadge.Render("[x]") + " " + title
+		}
+		switch {
+		case r.Version == m.active:
+			title += " " + installedBadge.Render("[active]")
+		case slices.Contains(m.installed, r.Version):
+			title += " " + installedBadge.Render("[installed]")
+		}
+		items = append(items, item{title: title, desc: strings.Join(parts, " · "), version: r.Version})
+	}
+	if !m.allLoaded {
+		items = append(items, moreItem{})
+	}
+	return items
+}
+
+// reselectMsg highlights a version again once the picker items were
+// rebuilt and filtered.
+type reselectMsg string
+
+// refreshItems rebuilds the picker items. The list filters them again in
+// the background, so the highlighted version is restored after that.
+func (m *preInstallModel) refreshItems() tea.Cmd {
+	i, ok := m.list.SelectedItem().(item)
+	cmd := m.list.SetItems(m.releaseItems())
+	if !ok {
+		return cmd
+	}
+	reselect := func() tea.Msg { return reselectMsg(i.version) }
+	if cmd == nil {
+		return reselect
+	}
+	return tea.Sequence(cmd, reselect)
+}
+
+// installedVersions returns the toolchains in GoRoot and the versions
+// store, and the version of the go command on PATH.
+func installedVersions() (installed []string, active string) {
+	installed = common.StoredVersions()
+	if v, err := common.InstalledVersion(common.GoRoot); err == nil {
+		installed = append(installed, v)
+	}
+	active, _ = common.ActiveGoVersion()
+	return installed, active
+}
+
+// fetchDates dates the first releases of the picker that have no date yet.
+func (m preInstallModel) fetchDates() tea.Cmd {
+	var undated []common.GoRelease
+	for _, r := range m.releases {
+		if len(undated) == datedReleases {
+			break
+		}
+		if _, ok := m.dates[r.Version]; !ok {
+			undated = append(undated, r)
+		}
+	}
+	if len(undated) == 0 {
+		return nil
+	}
+	return fetchDates(undated, m.targetOS, m.targetArch)
+}
+
+// release returns the release of version from the fetched releases.
+func (m preInstallModel) release(version string) (common.GoRelease, bool) {
+	for _, r := range m.releases {
+		if r.Version == version {
+			return r, true
+		}
+	}
+	return common.GoRelease{}, false
+}
+
+func (m preInstallModel) versionExists(version string) bool {
+	_, ok := m.release(version)
+	return ok
+}
+
+// abort quits without installing anything.
+func (m preInstallModel) abort() (tea.Model, tea.Cmd) {
+	m.aborted = true
+	return m, tea.Quit
+}
+
+// offerFallback handles a version that exists but was not published for the
+// host platform, e.g. after a port like linux/386 was dropped. It suggests the
+// newest release that still ships an archive for it.
+func (m preInstallModel) offerFallback(findErr error) (tea.Model, tea.Cmd) {
+	fallback, ok := common.LatestWithBuild(m.releases, m.targetOS, m.targetArch)
+	if !ok {
+		m.err = fmt.Errorf("%w; no release publishes an archive for %s/%s", findErr, m.targetOS, m.targetArch)
+		m.state = preinstallStateError
+		return m, tea.Quit
+	}
+	m.fallbackVer = fallback.Version
+	if m.opts.Yes {
+		m.err = fmt.Errorf("%w; the last release for %s/%s is %s, pass --version %s to install it",
+			findErr, m.targetOS, m.targetArch, fallback.Version, fallback.Version)
+		m.state = preinstallStateError
+		return m, tea.Quit
+	}
+	m.state = preinstallStateConfirmFallback
+	return m, nil
+}
+
+// fetch finishes the dependency step and loads the release metadata.
+func (m preInstallModel) fetch() (tea.Model, tea.Cmd) {
+	publish(m.opts.Sinks, stepDone(StepDependencies, stepResult{}))
+	publish(m.opts.Sinks, stepStarted(StepReleases))
+	m.state = preinstallStateFetching
+	return m, tea.Batch(
+		m.spinner.Tick,
+		fetchReleases(m.opts.AllReleases),
+	)
+}
+
+// preflight makes sure the archive cache and the install prefix can take
+// the selected release before the pipeline changes anything.
+func (m preInstallModel) preflight() error {
+	release, file, sha, err := common.FindBuild(m.releases, m.selectedVer, m.targetOS, m.targetArch)
+	if err != nil {
+		return err
+	}
+	if m.opts.needsCachedArchive() {
+		if _, err := os.Stat(common.CachedArchivePath(file, sha)); err != nil {
+			return fmt.Errorf("archive %s is not in the cache, run with --only download first", file)
+		}
+	}
+	f, _ := release.File(file)
+	var reqs []common.Requirement
+	if m.opts.runs(StepDownload) {
+		reqs = append(reqs, common.Requirement{Dir: common.ArchiveCacheDir(), Bytes: f.Size})
+	}
+	if m.opts.runs(StepExtract) {
+		reqs = append(reqs, common.Requirement{
+			Dir:   common.InstallPrefix,
+			Bytes: f.Size * common.ExtractedSizeFactor,
+			Exec:  true,
+		})
+	}
+	return common.Preflight(reqs...)
+}
+
+func (m preInstallModel) missingDepsReport() string {
+	names := make([]string, 0, len(m.missingDeps))
+	for _, dep := range m.missingDeps {
+		names = append(names, dep.name)
+	}
+	return InfoStyle.Render(fmt.Sprintf(
+		"! Missing dependencies: %s (not installed, pass --allow-system-changes to install them)",
+		strings.Join(names, ", ")))
+}
+
+// missingDepsGuidance explains how to get missing dependencies on systems
+// where go-install does not run the package manager.
+func (m preInstallModel) missingDepsGuidance() string {
+	names := make([]string, 0, len(m.missingDeps))
+	for _, dep := range m.missingDeps {
+		names = append(names, dep.name)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("! Missing dependencies: %s. %s is image based, so they are not installed:",
+		strings.Join(names, ", "), m.distro.pretty))
+	for _, g := range m.distro.guidance {
+		sb.WriteString("\n  → " + g)
+	}
+	return InfoStyle.Render(sb.String())
+}
+
+// installSelected installs the version of the details pane, offering the
+// last one published for the platform when it has no archive.
+func (m preInstallModel) installSelected() (tea.Model, tea.Cmd) {
+	if _, _, _, err := common.FindBuild(m.releases, m.selectedVer, m.targetOS, m.targetArch); err != nil {
+		return m.offerFallback(err)
+	}
+	return m.startInstallation()
+}
+
 func (m preInstallModel) startInstallation() (tea.Model, tea.Cmd) {
+	if m.summary == "" {
+		if err := m.preflight(); err != nil {
+			m.err = err
+			m.state = preinstallStateError
+			return m, tea.Quit
+		}
+		// --force installs an EOL release like --allow-eol, --yes alone
+		// refuses it.
+		eol := !m.opts.AllowEOL && common.EndOfLife(m.releases, m.selectedVer)
+		if eol && m.opts.Yes && !m.opts.Force {
+			m.err = common.EndOfLifeError(m.selectedVer)
+			m.state = preinstallStateError
+			return m, tea.Quit
+		}
+		m.summary = m.installSummary()
+		publish(m.opts.Sinks, m.summaryEvent())
+		if !m.opts.Yes && !m.opts.Force {
+			m.state = preinstallStateConfirmSummary
+			return m, nil
+		}
+		next, cmd := m.startInstallation()
+		return next, tea.Sequence(tea.Println(TitleStyle.Render("Installing")+"\n"+m.summary), cmd)
+	}
+	// Links in /usr/local/bin come before the package's /usr/bin/go.
+	linked := m.opts.SymlinkBinaries || m.opts.Alternatives
+	if m.othersChecked || linked || m.opts.PackagedGo == PackagedGoIgnore || m.opts.PackagedGo == "" {
+		return m.install()
+	}
+	m.othersChecked = true
+	if m.distro.name == "" {
+		m.distro = detectDistro()
+	}
+	m.otherGos = findOtherGos(m.distro)
+	if len(m.otherGos) == 0 {
+		return m.install()
+	}
+	switch {
+	case m.opts.PackagedGo == PackagedGoRemove:
+		return m.removeOtherGos()
+	case m.opts.PackagedGo == PackagedGoPrefer:
+		common.PreferGoRoot = true
+		return m.install()
+	case m.opts.Yes:
+		next, cmd := m.install()
+		notice := otherGoNotice(m.otherGos) + "\n  Pass --packaged-go remove or prefer."
+		return next, tea.Sequence(tea.Println(InfoStyle.Render(notice)), cmd)
+	}
+	m.state = preinstallStateConfirmOtherGo
+	return m, nil
+}
+
+// removeOtherGos removes the other Go installations before installing.
+func (m preInstallModel) removeOtherGos() (tea.Model, tea.Cmd) {
+	m.state = preinstallStateRemovingOtherGo
+	return m, tea.Batch(m.spinner.Tick, removeOtherGos(m.otherGos))
+}
+
+// install hands over to the install pipeline.
+func (m preInstallModel) install() (tea.Model, tea.Cmd) {
 	m.state = preinstallStateInstalling
-	installMod := newInstallModel(m.selectedVer, m.targetOS, m.targetArch, m.releases)
+	installMod := newInstallModel(m.selectedVer, m.targetOS, m.targetArch, m.releases, m.opts)
 	return installMod, installMod.Init()
 }
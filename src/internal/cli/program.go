@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// interruptMsg is sent to the model on SIGINT or SIGTERM so it can cancel
+// running work and clean up before quitting.
+type interruptMsg struct{}
+
+// Headless are program options that keep the TUI off the terminal, for
+// when stdout carries machine-readable output.
+var Headless = []tea.ProgramOption{tea.WithOutput(io.Discard), tea.WithInput(nil)}
+
+// NewProgram creates a program whose signals are delivered to the model
+// instead of terminating it immediately.
+func NewProgram(m tea.Model, opts ...tea.ProgramOption) *tea.Program {
+	p := tea.NewProgram(m, append([]tea.ProgramOption{tea.WithoutSignalHandler()}, opts...)...)
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		for range sig {
+			p.Send(interruptMsg{})
+		}
+	}()
+	return p
+}
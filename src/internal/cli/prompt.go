@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// confirm asks a yes/no question on stdin for commands that run without the
+// TUI. Pressing enter answers no.
+func confirm(question string) bool {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(question + " " + yesNoHint(false) + ": ")
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return false
+		}
+		switch parseAnswer(line, false) {
+		case answerYes:
+			return true
+		case answerNo:
+			return false
+		}
+	}
+}
+
+// ConfirmInstall asks before an install on a dumb terminal, where the TUI
+// and its prompts are replaced by plain lines.
+func ConfirmInstall(version string) bool {
+	target := "the latest stable Go"
+	if version != "" {
+		target = "Go " + common.NormalizeVersion(version)
+	}
+	return confirm(fmt.Sprintf("Install %s into %s?", target, common.GoRoot))
+}
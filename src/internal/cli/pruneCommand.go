@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+)
+
+// DefaultPruneKeep is how many toolchains and backups prune keeps.
+const DefaultPruneKeep = 2
+
+// RunPrune removes kept toolchains and backups beyond the newest keep and
+// the cached archives of removed versions. The active toolchain and the
+// protected versions are always kept.
+func RunPrune(keep int, protected []string, yes bool) error {
+	if keep < 0 {
+		return fmt.Errorf("--keep must not be negative")
+	}
+	items, err := common.PlanPrune(keep, protected)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 {
+		fmt.Println(SuccessStyle.Render("✓ Nothing to prune"))
+		return nil
+	}
+
+	var total int64
+	for _, item := range items {
+		total += item.Size
+		fmt.Printf("  %-10s %-40s %10s\n", item.Kind, item.Version, common.FormatSize(item.Size))
+	}
+	question := fmt.Sprintf("Remove %d items and reclaim %s?", len(items), common.FormatSize(total))
+	if !yes && !confirm(question) {
+		return fmt.Errorf("prune %w", common.ErrCancelled)
+	}
+
+	var freed int64
+	for _, item := range items {
+		if err := common.Prune(item); err != nil {
+			return err
+		}
+		freed += item.Size
+		fmt.Println(InfoStyle.Render("Removed " + item.Path))
+	}
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Reclaimed %s", common.FormatSize(freed))))
+	return nil
+}
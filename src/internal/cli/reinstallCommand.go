@@ -0,0 +1,36 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+)
+
+// RunReinstall replaces the toolchain in GoRoot with a fresh copy of the
+// same version, for when the tree is suspected to be corrupted. A verified
+// cached archive is reused. The shell configuration is left alone and the
+// replaced tree is kept as a backup.
+func RunReinstall(yes bool) error {
+	version, err := common.InstalledVersion(common.GoRoot)
+	if err != nil {
+		return fmt.Errorf("cannot tell which version is installed in %s: %w; install one with --version", common.GoRoot, err)
+	}
+
+	fmt.Println(InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+	if _, _, _, err := common.FindBuild(releases, version, common.GetOS(), common.GetArch()); err != nil {
+		return err
+	}
+
+	if !yes && !confirm(fmt.Sprintf("Reinstall %s in %s?", version, common.GoRoot)) {
+		return fmt.Errorf("reinstall %w", common.ErrCancelled)
+	}
+
+	steps, err := ParseSteps("", StepConfigure)
+	if err != nil {
+		return err
+	}
+	return runInstall(version, releases, Options{KeepBackups: DefaultKeepBackups, Steps: steps})
+}
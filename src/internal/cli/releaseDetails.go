@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+)
+
+var showDetails = key.NewBinding(key.WithKeys("tab"), key.WithHelp("tab", "details"))
+
+// releaseStatus describes where a release stands, e.g. "Latest stable
+// release" or "Release candidate".
+func releaseStatus(r, latest common.GoRelease) string {
+	v, _ := common.ParseVersion(r.Version)
+	switch {
+	case r.Version == latest.Version:
+		return "Latest stable release"
+	case strings.HasPrefix(v.Pre, "rc"):
+		return "Release candidate"
+	case strings.HasPrefix(v.Pre, "beta"):
+		return "Beta release"
+	case !r.Stable:
+		return "Unstable release"
+	}
+	return "Go release"
+}
+
+// releaseNotesURL links the notes of a release: the release notes page for
+// the first release of a series and prereleases, the release history entry
+// for point releases.
+func releaseNotesURL(version string) string {
+	v, err := common.ParseVersion(version)
+	if err != nil {
+		return releaseHistoryURL
+	}
+	switch {
+	case v.Pre != "":
+		return fmt.Sprintf("https://tip.golang.org/doc/go%d.%d", v.Major, v.Minor)
+	case v.Patch == 0:
+		return fmt.Sprintf("https://go.dev/doc/go%d.%d", v.Major, v.Minor)
+	}
+	return releaseHistoryURL + "#" + version
+}
+
+// newDetailsTable lists every file of a release with the cursor on the
+// build for the target platform.
+func newDetailsTable(r common.GoRelease, goos, arch string, height int) table.Model {
+	t := table.New(
+		table.WithColumns([]table.Column{
+			{Title: "OS", Width: 9},
+			{Title: "Arch", Width: 9},
+			{Title: "Kind", Width: 9},
+			{Title: "Size", Width: 9},
+			{Title: "SHA256", Width: 64},
+		}),
+		table.WithFocused(true),
+		table.WithHeight(min(len(r.Files)+1, max(height, 4))),
+	)
+	rows := make([]table.Row, 0, len(r.Files))
+	cursor := 0
+	for i, f := range r.Files {
+		if f.OS == goos && f.Arch == arch && f.Kind == "archive" {
+			cursor = i
+		}
+		fileOS, fileArch := f.OS, f.Arch
+		if fileOS == "" {
+			fileOS, fileArch = "-", "-"
+		}
+		rows = append(rows, table.Row{fileOS, fileArch, f.Kind, common.FormatSize(f.Size), f.Sha256})
+	}
+	t.SetRows(rows)
+	t.SetCursor(cursor)
+	return t
+}
+
+// detailsHeight is the number of table rows the details view has room for.
+func (m preInstallModel) detailsHeight() int {
+	if !m.opts.AltScreen || m.height == 0 {
+		return 16
+	}
+	// Title, status lines, notes link and the key hints.
+	return m.height - 9
+}
+
+func (m preInstallModel) detailsView() string {
+	r, _ := m.release(m.selectedVer)
+	latest, _ := common.LatestStable(m.releases)
+	var sb strings.Builder
+	sb.WriteString(TitleStyle.Render(r.Version) + "\n")
+	status := releaseStatus(r, latest)
+	if common.EndOfLife(m.releases, r.Version) {
+		status += " · " + eolBadge.Render("end of life, no security fixes")
+	}
+	if date, ok := m.dates[r.Version]; ok {
+		status += " · published " + date.Format("2006-01-02")
+	}
+	sb.WriteString(status + "\n")
+	sb.WriteString("Release notes: " + releaseNotesURL(r.Version) + "\n\n")
+	sb.WriteString(m.details.View() + "\n\n")
+	if _, _, _, err := common.FindBuild(m.releases, r.Version, m.targetOS, m.targetArch); err != nil {
+		sb.WriteString(InfoStyle.Render(fmt.Sprintf("! no %s/%s archive, enter offers the last release with one", m.targetOS, m.targetArch)) + "\n")
+	}
+	if m.browserErr != nil {
+		sb.WriteString(InfoStyle.Render("! could not open a browser: "+m.browserErr.Error()) + "\n")
+	}
+	sb.WriteString(helpFooter(keyHelp{
+		key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑/↓", "scroll")),
+		showNotes,
+		openBrowser,
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "install")),
+		key.NewBinding(key.WithKeys("esc", "tab"), key.WithHelp("esc", "back")),
+		quitKey,
+	}))
+	return sb.String()
+}
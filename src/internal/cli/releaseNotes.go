@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	showNotes   = key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "release notes"))
+	openBrowser = key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open in browser"))
+)
+
+type releaseNotesMsg struct {
+	version string
+	text    string
+	err     error
+}
+
+type browserOpenedMsg struct {
+	err error
+}
+
+// fetchReleaseNotes loads the release history entries of version. When
+// active is an older release of the same series, the entries of the
+// releases in between are included, newest first, so an upgrade shows
+// everything it brings.
+func fetchReleaseNotes(version, active string) tea.Cmd {
+	return func() tea.Msg {
+		page, err := releaseHistory()
+		if err != nil {
+			return releaseNotesMsg{version: version, err: err}
+		}
+		to, err := common.ParseVersion(version)
+		if err != nil {
+			return releaseNotesMsg{version: version, err: err}
+		}
+		from, err := common.ParseVersion(active)
+		if err != nil || from.Series() != to.Series() || !from.Less(to) {
+			from = to
+		}
+		var notes []releaseNote
+		for _, n := range parseReleaseNotes(page) {
+			if n.version == to || (from.Less(n.version) && n.version.Less(to)) {
+				notes = append(notes, n)
+			}
+		}
+		slices.SortFunc(notes, func(a, b releaseNote) int {
+			if b.version.Less(a.version) {
+				return -1
+			}
+			return 1
+		})
+		if len(notes) == 0 {
+			return releaseNotesMsg{version: version, text: "The release history has no entry for " + version + " yet."}
+		}
+		var sb strings.Builder
+		for _, n := range notes {
+			sb.WriteString(SuccessStyle.Render(n.version.String()) + "\n" + n.text + "\n\n")
+		}
+		return releaseNotesMsg{version: version, text: strings.TrimSpace(sb.String())}
+	}
+}
+
+// openURL opens url in the browser of the user who invoked sudo.
+func openURL(url string) tea.Cmd {
+	return func() tea.Msg {
+		name := "xdg-open"
+		if runtime.GOOS == "darwin" {
+			name = "open"
+		}
+		account, err := common.InvokingUser()
+		if err != nil {
+			return browserOpenedMsg{err: err}
+		}
+		cmd := account.Command(name, url)
+		if err := cmd.Start(); err != nil {
+			return browserOpenedMsg{err: err}
+		}
+		go cmd.Wait()
+		return browserOpenedMsg{}
+	}
+}
+
+// notesSize is the size of the release notes viewport.
+func (m preInstallModel) notesSize() (int, int) {
+	if !m.opts.AltScreen || m.height == 0 {
+		return 80, 16
+	}
+	// Title, notes link, status and the key hints.
+	return m.width, m.height - 7
+}
+
+// newNotesViewport returns the viewport the notes of the selected version
+// are shown in once loaded.
+func (m preInstallModel) newNotesViewport() viewport.Model {
+	w, h := m.notesSize()
+	vp := viewport.New(w, h)
+	vp.SetContent(fmt.Sprintf("%s Loading release notes...", m.spinner.View()))
+	return vp
+}
+
+func (m preInstallModel) setNotes(msg releaseNotesMsg) preInstallModel {
+	text := msg.text
+	if msg.err != nil {
+		text = ErrorStyle.Render("Could not load the release history: "+msg.err.Error()) +
+			"\n\nPress o to read the notes in a browser."
+	}
+	w, _ := m.notesSize()
+	m.notes.SetContent(lipgloss.NewStyle().Width(w).Render(text))
+	return m
+}
+
+func (m preInstallModel) notesView() string {
+	var sb strings.Builder
+	sb.WriteString(TitleStyle.Render("Release notes for "+m.selectedVer) + "\n")
+	sb.WriteString(m.notes.View() + "\n")
+	sb.WriteString(InfoStyle.Render(releaseNotesURL(m.selectedVer)))
+	if m.browserErr != nil {
+		sb.WriteString("\n" + InfoStyle.Render("! could not open a browser: "+m.browserErr.Error()))
+	}
+	sb.WriteString("\n" + helpFooter(keyHelp{
+		key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑/↓", "scroll")),
+		openBrowser,
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "install")),
+		key.NewBinding(key.WithKeys("esc", "r"), key.WithHelp("esc", "back")),
+		quitKey,
+	}))
+	return sb.String()
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// RunRollback restores the most recent backup as the active toolchain. The
+// toolchain being replaced is backed up as well, so a rollback can itself be
+// undone by running it again.
+func RunRollback(yes bool) error {
+	backups, err := common.ListBackups()
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		return fmt.Errorf("no backups found in %s", common.BackupsDir)
+	}
+	b := backups[0]
+
+	question := fmt.Sprintf("Restore %s (backed up %s)?", b.Version, b.Created.Format("2006-01-02 15:04"))
+	if !yes && !confirm(question) {
+		return fmt.Errorf("rollback %w", common.ErrCancelled)
+	}
+
+	if _, err := common.BackupGoRoot(); err != nil {
+		return fmt.Errorf("could not back up current installation: %w", err)
+	}
+	if err := os.Rename(b.GoRoot(), common.GoRoot); err != nil {
+		return err
+	}
+	os.Remove(b.Path)
+
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Restored %s to %s", b.Version, common.GoRoot)))
+	return nil
+}
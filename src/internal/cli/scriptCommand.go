@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// installScript downloads, verifies and extracts one archive with nothing
+// but a POSIX shell, curl or wget and tar.
+var installScript = template.Must(template.New("script").Funcs(template.FuncMap{"quote": shellQuote}).Parse(`#!/bin/sh
+# Installs {{.Version}} for {{.Platform}} into {{printf "%q" .Prefix}}/go.
+# Generated by go-install {{.AppVersion}} with --print-script.
+set -eu
+
+url={{quote .URL}}
+sha256={{quote .Sha256}}
+prefix={{quote .Prefix}}
+
+tmp=$(mktemp)
+trap 'rm -f "$tmp"' EXIT
+
+if command -v curl >/dev/null 2>&1; then
+	curl -fsSL -o "$tmp" "$url"
+else
+	wget -qO "$tmp" "$url"
+fi
+
+if command -v sha256sum >/dev/null 2>&1; then
+	actual=$(sha256sum "$tmp" | cut -d ' ' -f 1)
+elif command -v shasum >/dev/null 2>&1; then
+	actual=$(shasum -a 256 "$tmp" | cut -d ' ' -f 1)
+else
+	actual=$(sha256 -q "$tmp")
+fi
+if [ "$actual" != "$sha256" ]; then
+	echo "checksum mismatch for $url: got $actual, want $sha256" >&2
+	exit 1
+fi
+
+mkdir -p "$prefix"
+rm -rf "$prefix/go"
+tar -C "$prefix" -xzf "$tmp"
+
+# In a Dockerfile use instead: {{.EnvLine}}
+export PATH="$PATH:$prefix/go/bin"
+echo "Installed $("$prefix/go/bin/go" version)"
+`))
+
+// RunPrintScript writes a shell script to w that installs version, or the
+// latest stable release when it is empty, for goos, empty for this one, the
+// configured architecture and prefix without go-install.
+func RunPrintScript(w io.Writer, version, goos string) error {
+	if goos == "" {
+		goos = common.GetOS()
+	}
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		latest, err := common.LatestStable(releases)
+		if err != nil {
+			return err
+		}
+		version = latest.Version
+	}
+	version = common.NormalizeVersion(version)
+	_, file, sha, err := common.FindBuild(releases, version, goos, common.GetArch())
+	if err != nil {
+		return err
+	}
+	if !strings.HasSuffix(file, ".tar.gz") {
+		return fmt.Errorf("%s is not a tar.gz archive, a shell script cannot install it", file)
+	}
+	return installScript.Execute(w, map[string]string{
+		"Version":    version,
+		"Platform":   goos + "/" + common.GetArch(),
+		"Prefix":     common.InstallPrefix,
+		"AppVersion": common.AppVersion,
+		"URL":        common.DownloadBase + file,
+		"Sha256":     sha,
+		"EnvLine":    common.ContainerEnvLine(),
+	})
+}
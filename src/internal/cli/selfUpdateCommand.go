@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-installer/common"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const releasesAPI = "https://api.github.com/repos/pecet3/go-install/releases/latest"
+
+type githubRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  []struct {
+		Name string `json:"name"`
+		URL  string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+func latestSelfRelease() (githubRelease, error) {
+	var rel githubRelease
+	resp, err := http.Get(releasesAPI)
+	if err != nil {
+		return rel, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return rel, fmt.Errorf("github releases: %s", resp.Status)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&rel)
+	return rel, err
+}
+
+// assetFor returns the binary asset built for goos/arch and the checksum
+// file published alongside it.
+func (r githubRelease) assetFor(goos, arch string) (name, url, sumsURL string) {
+	for _, a := range r.Assets {
+		lower := strings.ToLower(a.Name)
+		switch {
+		case strings.Contains(lower, "checksums"):
+			sumsURL = a.URL
+		case builtFor(lower, goos, arch) && url == "":
+			name, url = a.Name, a.URL
+		}
+	}
+	return name, url, sumsURL
+}
+
+// builtFor reports whether the asset name is the raw binary for goos/arch,
+// e.g. go-install_linux_arm64, or go-install_windows_amd64.exe. Archives
+// such as go-install_linux_arm64.tar.gz do not match, they cannot replace
+// the executable as they are, and neither does arm match arm64.
+func builtFor(name, goos, arch string) bool {
+	_, rest, ok := strings.Cut(name, "_"+goos+"_"+arch)
+	return ok && (rest == "" || rest == ".exe")
+}
+
+// selfVersion parses a go-install release tag such as v1.4.0 or
+// v1.5.0-rc1.
+func selfVersion(tag string) (common.Version, error) {
+	return common.ParseVersion(strings.ReplaceAll(strings.TrimPrefix(tag, "v"), "-", ""))
+}
+
+// checksumFor looks up name in a sha256sum formatted file.
+func checksumFor(sumsPath, name string) (string, error) {
+	f, err := os.Open(sumsPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return fields[0], nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum for %s", name)
+}
+
+// RunSelfUpdate replaces the running go-install binary with the latest
+// GitHub release for this platform.
+func RunSelfUpdate(yes bool) error {
+	rel, err := latestSelfRelease()
+	if err != nil {
+		return err
+	}
+	current, err := selfVersion(common.AppVersion)
+	if err != nil {
+		return fmt.Errorf("go-install %s is a development build, install a release to update it", common.AppVersion)
+	}
+	latest, err := selfVersion(rel.TagName)
+	if err != nil {
+		return fmt.Errorf("latest release: %w", err)
+	}
+	if !current.Less(latest) {
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ go-install %s is up to date", common.AppVersion)))
+		return nil
+	}
+
+	// go-install's own assets use GOARCH names, not go.dev's armv6l.
+	name, url, sumsURL := rel.assetFor(common.GetOS(), runtime.GOARCH)
+	if url == "" {
+		return fmt.Errorf("release %s has no binary for %s/%s", rel.TagName, common.GetOS(), runtime.GOARCH)
+	}
+	if sumsURL == "" {
+		return fmt.Errorf("release %s has no checksums file", rel.TagName)
+	}
+
+	if !yes && !confirm(fmt.Sprintf("Update go-install %s → %s?", common.AppVersion, rel.TagName)) {
+		return fmt.Errorf("update %w", common.ErrCancelled)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if self, err = filepath.EvalSymlinks(self); err != nil {
+		return err
+	}
+
+	// Download next to the executable so the final rename stays on one
+	// filesystem and is atomic.
+	dir := filepath.Dir(self)
+	tmp, err := os.CreateTemp(dir, ".go-install-update-")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	sums := tmp.Name() + ".sums"
+	defer os.Remove(sums)
+
+	if err := common.DownloadURL(context.Background(), url, tmp.Name(), nil); err != nil {
+		return err
+	}
+	if err := common.DownloadURL(context.Background(), sumsURL, sums, nil); err != nil {
+		return err
+	}
+	want, err := checksumFor(sums, name)
+	if err != nil {
+		return err
+	}
+	if err := common.VerifyChecksum(tmp.Name(), want); err != nil {
+		return err
+	}
+
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), self); err != nil {
+		return err
+	}
+
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Updated go-install to %s", rel.TagName)))
+	return nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltFor(t *testing.T) {
+	tests := []struct {
+		name, goos, arch string
+		want             bool
+	}{
+		{"go-install_linux_arm64", "linux", "arm64", true},
+		{"go-install_linux_arm", "linux", "arm", true},
+		{"go-install_windows_amd64.exe", "windows", "amd64", true},
+		{"go-install_linux_arm64", "linux", "arm", false},
+		{"go-install_linux_arm64.tar.gz", "linux", "arm64", false},
+		{"go-install_linux_amd64.sha256", "linux", "amd64", false},
+		{"go-install_darwin_arm64", "linux", "arm64", false},
+		{"checksums.txt", "linux", "amd64", false},
+	}
+	for _, tt := range tests {
+		if got := builtFor(tt.name, tt.goos, tt.arch); got != tt.want {
+			t.Errorf("builtFor(%q, %q, %q) = %v, want %v", tt.name, tt.goos, tt.arch, got, tt.want)
+		}
+	}
+}
+
+func TestAssetFor(t *testing.T) {
+	var rel githubRelease
+	for _, name := range []string{"go-install_linux_arm64.tar.gz", "go-install_linux_arm64", "go-install_linux_arm", "checksums.txt"} {
+		rel.Assets = append(rel.Assets, struct {
+			Name string `json:"name"`
+			URL  string `json:"browser_download_url"`
+		}{name, "https://example.com/" + name})
+	}
+	tests := []struct {
+		arch, want string
+	}{
+		{"arm64", "go-install_linux_arm64"},
+		{"arm", "go-install_linux_arm"},
+		{"amd64", ""},
+	}
+	for _, tt := range tests {
+		name, _, sums := rel.assetFor("linux", tt.arch)
+		if name != tt.want {
+			t.Errorf("assetFor(linux, %s) = %q, want %q", tt.arch, name, tt.want)
+		}
+		if sums != "https://example.com/checksums.txt" {
+			t.Errorf("assetFor(linux, %s) checksums = %q", tt.arch, sums)
+		}
+	}
+}
+
+func TestSelfVersion(t *testing.T) {
+	tests := []struct {
+		current, latest string
+		update          bool
+	}{
+		{"v1.2.0", "v1.3.0", true},
+		{"v1.3.0", "v1.3.0", false},
+		{"v1.4.0", "v1.3.0", false},
+		{"v1.3.0-rc1", "v1.3.0", true},
+		{"v1.3.0", "v1.4.0-rc1", true},
+	}
+	for _, tt := range tests {
+		current, err := selfVersion(tt.current)
+		if err != nil {
+			t.Fatal(err)
+		}
+		latest, err := selfVersion(tt.latest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := current.Less(latest); got != tt.update {
+			t.Errorf("%s → %s: update = %v, want %v", tt.current, tt.latest, got, tt.update)
+		}
+	}
+	if _, err := selfVersion("dev"); err == nil {
+		t.Error("selfVersion(dev) succeeded, a development build must not update")
+	}
+}
+
+func TestChecksumFor(t *testing.T) {
+	sums := filepath.Join(t.TempDir(), "checksums.txt")
+	content := "aaaa  go-install_linux_amd64\nbbbb *go-install_linux_arm64\ncccc  go-install_linux_arm64.tar.gz\n"
+	if err := os.WriteFile(sums, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name, want string
+		wantErr    bool
+	}{
+		{"go-install_linux_amd64", "aaaa", false},
+		{"go-install_linux_arm64", "bbbb", false},
+		{"go-install_linux_arm64.tar.gz", "cccc", false},
+		{"go-install_linux_arm", "", true},
+	}
+	for _, tt := range tests {
+		got, err := checksumFor(sums, tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("checksumFor(%q) = %q, %v; want %q, error %v", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -1,22 +1,130 @@
 package cli
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"fmt"
+	"go-installer/common"
+	"maps"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// palette holds the colors of the UI by theme name. Every color has a
+// variant for light and dark terminal backgrounds. lipgloss drops colors
+// altogether when NO_COLOR is set or CLICOLOR is 0, and keeps them for
+// redirected output when CLICOLOR_FORCE is set.
+var palette = map[string]lipgloss.AdaptiveColor{
+	"title":   {Light: "127", Dark: "170"},
+	"error":   {Light: "160", Dark: "196"},
+	"success": {Light: "28", Dark: "42"},
+	"info":    {Light: "243", Dark: "241"},
+	"accent":  {Light: "162", Dark: "205"},
+	"warning": {Light: "130", Dark: "214"},
+	"muted":   {Light: "247", Dark: "240"},
+}
+
+var hexColorRe = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
 
 var (
-	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("170")).
-			MarginTop(1).
-			MarginBottom(1)
+	TitleStyle   lipgloss.Style
+	ErrorStyle   lipgloss.Style
+	SuccessStyle lipgloss.Style
+	InfoStyle    lipgloss.Style
 
-	ErrorStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("196"))
+	// accentStyle colors spinners and marks, mutedStyle pending and
+	// secondary text.
+	accentStyle  lipgloss.Style
+	warningStyle lipgloss.Style
+	mutedStyle   lipgloss.Style
 
-	SuccessStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("42"))
+	prereleaseBadge lipgloss.Style
+	installedBadge  lipgloss.Style
+	markBadge       lipgloss.Style
+	eolBadge        lipgloss.Style
+)
+
+func init() {
+	applyPalette()
+}
 
+func applyPalette() {
+	if plain {
+		// No colors, no bold, symbols spelled out.
+		base := lipgloss.NewStyle().Transform(plainSymbols.Replace)
+		TitleStyle = base.MarginTop(1).MarginBottom(1)
+		ErrorStyle, SuccessStyle, InfoStyle = base, base, base
+		accentStyle, warningStyle, mutedStyle = base, base, base
+		prereleaseBadge, installedBadge, markBadge, eolBadge = base, base, base, base
+		return
+	}
+	TitleStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(palette["title"]).
+		MarginTop(1).
+		MarginBottom(1)
+	ErrorStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(palette["error"])
+	SuccessStyle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(palette["success"])
 	InfoStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("241"))
-)
+		Foreground(palette["info"])
+
+	accentStyle = lipgloss.NewStyle().Foreground(palette["accent"])
+	warningStyle = lipgloss.NewStyle().Foreground(palette["warning"])
+	mutedStyle = lipgloss.NewStyle().Foreground(palette["muted"])
+
+	prereleaseBadge = warningStyle.Bold(true)
+	installedBadge = lipgloss.NewStyle().Foreground(palette["success"]).Bold(true)
+	markBadge = accentStyle.Bold(true)
+	eolBadge = lipgloss.NewStyle().Foreground(palette["error"])
+}
+
+// newSpinner returns the spinner of the TUI models, a static ellipsis in
+// plain mode.
+func newSpinner() spinner.Model {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	if plain {
+		s.Spinner = spinner.Spinner{Frames: []string{"..."}, FPS: time.Second}
+	}
+	s.Style = accentStyle
+	return s
+}
+
+// validColor reports whether c is an ANSI color number or a hex color.
+func validColor(c string) bool {
+	if n, err := strconv.Atoi(c); err == nil {
+		return n >= 0 && n <= 255
+	}
+	return hexColorRe.MatchString(c)
+}
+
+// ApplyTheme overrides colors of the palette with those of the [theme]
+// config table. Unknown names and invalid colors are returned as problems
+// and leave the default in place.
+func ApplyTheme(theme map[string]common.ThemeColor) []string {
+	var problems []string
+	names := slices.Sorted(maps.Keys(palette))
+	for name, c := range theme {
+		current, ok := palette[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("theme: unknown color %q (valid: %s)", name, strings.Join(names, ", ")))
+			continue
+		}
+		if !validColor(c.Light) || !validColor(c.Dark) {
+			problems = append(problems, fmt.Sprintf("theme: invalid color for %s, use 0-255 or #rrggbb", name))
+			continue
+		}
+		current.Light, current.Dark = c.Light, c.Dark
+		palette[name] = current
+	}
+	applyPalette()
+	return problems
+}
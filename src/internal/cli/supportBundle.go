@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+const issuesURL = "https://github.com/pecet3/go-install/issues/new"
+
+var (
+	// Credentials embedded in URLs, such as proxy settings.
+	urlUserinfoRe = regexp.MustCompile(`://[^/@\s]+@`)
+	secretNameRe  = regexp.MustCompile(`(?i)token|secret|passw|key|auth|cookie`)
+)
+
+// redact strips what identifies the user or grants access from s before it
+// ends up in a bundle attached to a public issue.
+func redact(s string) string {
+	s = urlUserinfoRe.ReplaceAllString(s, "://<redacted>@")
+	if home, err := common.HomeDir(); err == nil {
+		s = strings.ReplaceAll(s, home, "~")
+	}
+	return s
+}
+
+// environmentSummary describes the host and the relevant environment.
+func environmentSummary() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "go-install: %s\n", common.AppVersion)
+	fmt.Fprintf(&sb, "platform:   %s/%s (built with %s)\n", common.GetOS(), common.GetArch(), runtime.Version())
+	if runtime.GOARCH == "amd64" {
+		fmt.Fprintf(&sb, "amd64 level: v%d\n", common.AMD64Level())
+	}
+	distro := detectDistro()
+	fmt.Fprintf(&sb, "distro:     %s (%s)\n", distro.displayName(), distro.packageManager)
+	if rel, err := common.ReadOSRelease(); err == nil {
+		fmt.Fprintf(&sb, "os-release: %s (ID=%s ID_LIKE=%s)\n", rel.PrettyName, rel.ID, strings.Join(rel.IDLike, " "))
+	}
+	if out, err := exec.Command("uname", "-srm").Output(); err == nil {
+		fmt.Fprintf(&sb, "kernel:     %s", out)
+	}
+	fmt.Fprintf(&sb, "euid:       %d\n", os.Geteuid())
+	if v, err := common.InstalledVersion(common.GoRoot); err == nil {
+		fmt.Fprintf(&sb, "goroot:     %s %s\n", common.GoRoot, v)
+	} else {
+		fmt.Fprintf(&sb, "goroot:     %s (%v)\n", common.GoRoot, err)
+	}
+	if v, err := common.ActiveGoVersion(); err == nil {
+		fmt.Fprintf(&sb, "active go:  %s\n", v)
+	}
+
+	sb.WriteString("\nenvironment:\n")
+	var env []string
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		switch {
+		case strings.HasPrefix(name, "GO"), strings.HasSuffix(strings.ToLower(name), "_proxy"),
+			name == "PATH", name == "SHELL", name == "HOME", name == "SUDO_USER", name == "DOAS_USER",
+			name == "LANG", name == "TERM", name == "XDG_CACHE_HOME":
+		default:
+			continue
+		}
+		if secretNameRe.MatchString(name) {
+			value = "<redacted>"
+		}
+		env = append(env, fmt.Sprintf("  %s=%s", name, value))
+	}
+	sort.Strings(env)
+	sb.WriteString(strings.Join(env, "\n") + "\n")
+	return redact(sb.String())
+}
+
+// shellConfigExcerpt returns the lines go-install manages in the shell
+// configuration files recorded in the state.
+func shellConfigExcerpt(state common.State) string {
+	var sb strings.Builder
+	for _, f := range state.Files {
+		if f.Kind != common.KindShellConfig {
+			continue
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			fmt.Fprintf(&sb, "%s: %v\n", f.Path, err)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s:\n", f.Path)
+		for _, line := range strings.Split(string(data), "\n") {
+			if strings.Contains(line, "go-install") || strings.Contains(line, "PATH") || strings.Contains(line, "GO") {
+				fmt.Fprintf(&sb, "  %s\n", line)
+			}
+		}
+	}
+	if sb.Len() == 0 {
+		return "no shell configuration recorded\n"
+	}
+	return redact(sb.String())
+}
+
+// WriteSupportBundle collects diagnostics into a tar.gz archive in the
+// temporary directory and returns its path. reason, if set, describes the
+// crash that triggered it.
+func WriteSupportBundle(reason string) (string, error) {
+	files := map[string]string{"environment.txt": environmentSummary()}
+	state, err := common.LoadState()
+	if err != nil {
+		files["state.json"] = fmt.Sprintf("error reading %s: %v\n", common.StatePath, err)
+	} else if data, err := os.ReadFile(common.StatePath); err == nil {
+		files["state.json"] = redact(string(data))
+	}
+	files["shell-config.txt"] = shellConfigExcerpt(state)
+	if reason != "" {
+		files["crash.txt"] = redact(reason)
+	}
+	if common.LogPath != "" {
+		if data, err := os.ReadFile(common.LogPath); err == nil {
+			files["go-install.log"] = redact(logTail(string(data)))
+		}
+	}
+
+	f, err := os.CreateTemp("", fmt.Sprintf("go-install-support-%s-*.tar.gz", time.Now().Format("20060102-150405")))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		h := &tar.Header{
+			Name:    filepath.Join("go-install-support", name),
+			Mode:    0644,
+			Size:    int64(len(files[name])),
+			ModTime: time.Now(),
+		}
+		if err := tw.WriteHeader(h); err != nil {
+			return "", err
+		}
+		if _, err := tw.Write([]byte(files[name])); err != nil {
+			return "", err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return "", err
+	}
+	if err := gz.Close(); err != nil {
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// logTailLines is how much of the log a bundle includes, enough for the
+// last few runs.
+const logTailLines = 500
+
+func logTail(log string) string {
+	lines := strings.SplitAfter(log, "\n")
+	if len(lines) > logTailLines {
+		lines = lines[len(lines)-logTailLines:]
+	}
+	return strings.Join(lines, "")
+}
+
+func printBundleInstructions(path string) {
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Diagnostics written to "+path))
+	fmt.Fprintln(os.Stderr, InfoStyle.Render("Review it, then attach it to an issue at "+issuesURL))
+}
+
+// ReportCrash writes a support bundle for a panic or fatal error and tells
+// the user how to report it. Failing to write it is only reported.
+func ReportCrash(reason string) {
+	path, err := WriteSupportBundle(reason)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, ErrorStyle.Render(fmt.Sprintf("✗ Could not write diagnostics: %v", err)))
+		return
+	}
+	printBundleInstructions(path)
+}
+
+// RunSupportBundle writes a support bundle on demand.
+func RunSupportBundle() error {
+	path, err := WriteSupportBundle("")
+	if err != nil {
+		return err
+	}
+	printBundleInstructions(path)
+	return nil
+}
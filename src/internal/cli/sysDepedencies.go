@@ -1,36 +1,74 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"go-installer/common"
 	"os/exec"
+	"runtime"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type dependency struct {
-	name        string
-	checkCmd    string
+	// id names the dependency in the dependencies setting.
+	id       string
+	name     string
+	checkCmd string
+	// checkCmds replaces checkCmd on the given bases.
+	checkCmds   map[string]string
 	packageName map[string]string
-	required    bool
+	// required blocks the installation when declined, the others only
+	// limit what the installed toolchain can build.
+	required bool
+	// cgo marks the C toolchain, which pure Go code never needs.
+	cgo bool
+}
+
+// check returns the command that succeeds when dep is present on distro.
+func (dep dependency) check(distro distroInfo) string {
+	if cmd, ok := dep.checkCmds[distro.name]; ok {
+		return cmd
+	}
+	return dep.checkCmd
+}
+
+// anyRequired reports whether deps contains a hard requirement.
+func anyRequired(deps []dependency) bool {
+	return slices.ContainsFunc(deps, func(dep dependency) bool { return dep.required })
 }
 
 var requiredDeps = []dependency{
 	{
-		name:     "CA Certificates",
-		checkCmd: "test -f /etc/ssl/certs/ca-certificates.crt",
+		id:   "ca-certificates",
+		name: "CA Certificates",
+		// Debian and Arch, Fedora and RHEL, openSUSE and SLES bundle paths.
+		checkCmd: "test -f /etc/ssl/certs/ca-certificates.crt -o -f /etc/pki/tls/certs/ca-bundle.crt -o -f /etc/ssl/ca-bundle.pem",
 		packageName: map[string]string{
-			"debian": "ca-certificates",
-			"ubuntu": "ca-certificates",
-			"fedora": "ca-certificates",
-			"rhel":   "ca-certificates",
-			"arch":   "ca-certificates",
-			"alpine": "ca-certificates",
+			"debian":  "ca-certificates",
+			"ubuntu":  "ca-certificates",
+			"fedora":  "ca-certificates",
+			"rhel":    "ca-certificates",
+			"arch":    "ca-certificates",
+			"alpine":  "ca-certificates",
+			"suse":    "ca-certificates ca-certificates-mozilla",
+			"gentoo":  "app-misc/ca-certificates",
+			"void":    "ca-certificates",
+			"freebsd": "ca_root_nss",
+			"termux":  "ca-certificates",
+		},
+		checkCmds: map[string]string{
+			"freebsd": "test -f /etc/ssl/cert.pem -o -f /usr/local/share/certs/ca-root-nss.crt",
+			"termux":  "test -f /data/data/com.termux/files/usr/etc/tls/cert.pem",
 		},
 		required: true,
 	},
 	{
+		id:       "gcc",
 		name:     "GCC",
 		checkCmd: "gcc --version",
 		packageName: map[string]string{
@@ -40,101 +78,237 @@ var requiredDeps = []dependency{
 			"rhel":   "gcc gcc-c++ make",
 			"arch":   "base-devel",
 			"alpine": "build-base",
+			"suse":   "gcc gcc-c++ make",
+			"gentoo": "sys-devel/gcc",
+			"void":   "gcc",
+			// cgo uses the base system clang as cc on FreeBSD.
+			"freebsd": "llvm",
+			"termux":  "clang",
 		},
-		required: true,
+		checkCmds: map[string]string{
+			"freebsd": "cc --version",
+			"termux":  "clang --version",
+		},
+		cgo: true,
 	},
 	{
+		id:       "make",
 		name:     "Make",
 		checkCmd: "make --version",
 		packageName: map[string]string{
-			"debian": "build-essential",
-			"ubuntu": "build-essential",
-			"fedora": "make",
-			"rhel":   "make",
-			"arch":   "base-devel",
-			"alpine": "build-base",
+			"debian":  "build-essential",
+			"ubuntu":  "build-essential",
+			"fedora":  "make",
+			"rhel":    "make",
+			"arch":    "base-devel",
+			"alpine":  "build-base",
+			"suse":    "make",
+			"gentoo":  "dev-build/make",
+			"void":    "make",
+			"freebsd": "gmake",
+			"termux":  "make",
 		},
-		required: true,
+		checkCmds: map[string]string{
+			// make is BSD make, which has no --version.
+			"freebsd": "test -x /usr/bin/make",
+		},
+		cgo: true,
 	},
 	{
+		id:       "git",
 		name:     "Git",
 		checkCmd: "git --version",
 		packageName: map[string]string{
-			"debian": "git",
-			"ubuntu": "git",
-			"fedora": "git",
-			"rhel":   "git",
-			"arch":   "git",
-			"alpine": "git",
+			"debian":  "git",
+			"ubuntu":  "git",
+			"fedora":  "git",
+			"rhel":    "git",
+			"arch":    "git",
+			"alpine":  "git",
+			"suse":    "git",
+			"gentoo":  "dev-vcs/git",
+			"void":    "git",
+			"freebsd": "git",
+			"termux":  "git",
 		},
 		required: false,
 	},
 }
 
 type distroInfo struct {
-	name           string
+	// name is the distribution base that selects package names, such as
+	// ubuntu for Linux Mint.
+	name string
+	// pretty names the actual distribution for display, derived is set
+	// when it is not the base itself.
+	pretty  string
+	derived bool
+	// slow is set where installing builds from source and can take hours,
+	// so declining to install continues without the packages.
+	slow bool
+	// guidance replaces installing on immutable systems, where the package
+	// manager must not be used.
+	guidance       []string
 	packageManager string
 	installCmd     string
+	removeCmd      string
 	updateCmd      string
 }
 
-func detectDistro() distroInfo {
-	// Check for package managers
-	if _, err := exec.LookPath("apt-get"); err == nil {
-		// Debian/Ubuntu
-		data, _ := exec.Command("lsb_release", "-is").Output()
-		distro := strings.ToLower(strings.TrimSpace(string(data)))
-		if distro == "" {
-			distro = "debian"
-		}
-		return distroInfo{
-			name:           distro,
-			packageManager: "apt-get",
-			installCmd:     "apt-get install -y",
-			updateCmd:      "apt-get update",
-		}
+// displayName names the distribution and the base it was matched to.
+func (d distroInfo) displayName() string {
+	switch {
+	case d.pretty == "":
+		return d.name
+	case !d.derived:
+		return d.pretty
 	}
+	return fmt.Sprintf("%s (%s based)", d.pretty, d.name)
+}
 
-	if _, err := exec.LookPath("dnf"); err == nil {
-		// Fedora/RHEL 8+
-		return distroInfo{
-			name:           "fedora",
-			packageManager: "dnf",
-			installCmd:     "dnf install -y",
-			updateCmd:      "dnf check-update",
+// packageManagers maps the distribution bases go-install knows package
+// names for to the way packages are installed there.
+var packageManagers = map[string]distroInfo{
+	"debian": {name: "debian", packageManager: "apt-get", installCmd: "apt-get install -y", removeCmd: "apt-get remove -y", updateCmd: "apt-get update"},
+	"ubuntu": {name: "ubuntu", packageManager: "apt-get", installCmd: "apt-get install -y", removeCmd: "apt-get remove -y", updateCmd: "apt-get update"},
+	"fedora": {name: "fedora", packageManager: "dnf", installCmd: "dnf install -y", removeCmd: "dnf remove -y", updateCmd: "dnf check-update"},
+	"rhel":   {name: "rhel", packageManager: "dnf", installCmd: "dnf install -y", removeCmd: "dnf remove -y", updateCmd: "dnf check-update"},
+	"arch":   {name: "arch", packageManager: "pacman", installCmd: "pacman -S --noconfirm", removeCmd: "pacman -R --noconfirm", updateCmd: "pacman -Sy"},
+	"alpine": {name: "alpine", packageManager: "apk", installCmd: "apk add", removeCmd: "apk del", updateCmd: "apk update"},
+	"suse":   {name: "suse", packageManager: "zypper", installCmd: "zypper --non-interactive install", removeCmd: "zypper --non-interactive remove", updateCmd: "zypper --non-interactive refresh"},
+	// Syncing the portage tree is left to the user, it is slow and
+	// usually scheduled.
+	"gentoo":  {name: "gentoo", packageManager: "emerge", installCmd: "emerge --noreplace", slow: true},
+	"void":    {name: "void", packageManager: "xbps-install", installCmd: "xbps-install -y", removeCmd: "xbps-remove -y", updateCmd: "xbps-install -S"},
+	"freebsd": {name: "freebsd", packageManager: "pkg", installCmd: "pkg install -y", removeCmd: "pkg delete -y", updateCmd: "pkg update"},
+	"termux":  {name: "termux", pretty: "Termux", packageManager: "pkg", installCmd: "pkg install -y", removeCmd: "pkg uninstall -y", updateCmd: "pkg update"},
+}
+
+// derivatives maps distributions whose os-release has no or an incomplete
+// ID_LIKE to the base they take packages from.
+var derivatives = map[string]string{
+	"linuxmint":   "ubuntu",
+	"pop":         "ubuntu",
+	"zorin":       "ubuntu",
+	"elementary":  "ubuntu",
+	"neon":        "ubuntu",
+	"kali":        "debian",
+	"raspbian":    "debian",
+	"manjaro":     "arch",
+	"manjaro-arm": "arch",
+	"endeavouros": "arch",
+	"centos":      "rhel",
+	"rocky":       "rhel",
+	"almalinux":   "rhel",
+	// Amazon Linux 2 and Oracle Linux 7 only have yum, Amazon Linux 2023
+	// and Oracle Linux 8+ have dnf; rhel picks whichever is installed.
+	"amzn":   "rhel",
+	"ol":     "rhel",
+	"nobara": "fedora",
+	// openSUSE and SLES share zypper and package names.
+	"opensuse":            "suse",
+	"opensuse-leap":       "suse",
+	"opensuse-tumbleweed": "suse",
+	"opensuse-microos":    "suse",
+	"sles":                "suse",
+	"sled":                "suse",
+	"funtoo":              "gentoo",
+	"calculate":           "gentoo",
+}
+
+// packageBases is consulted when a dependency names no package for a base,
+// Ubuntu packages are Debian's and RHEL's are Fedora's.
+var packageBases = map[string]string{
+	"ubuntu": "debian",
+	"rhel":   "fedora",
+}
+
+// packages returns the sorted, deduplicated packages that provide deps.
+func (d distroInfo) packages(deps []dependency) []string {
+	var pkgs []string
+	for _, dep := range deps {
+		for base := d.name; base != ""; base = packageBases[base] {
+			if name, ok := dep.packageName[base]; ok {
+				pkgs = append(pkgs, strings.Fields(name)...)
+				break
+			}
 		}
 	}
+	slices.Sort(pkgs)
+	return slices.Compact(pkgs)
+}
 
-	if _, err := exec.LookPath("yum"); err == nil {
-		// RHEL/CentOS 7
-		return distroInfo{
-			name:           "rhel",
-			packageManager: "yum",
-			installCmd:     "yum install -y",
-			updateCmd:      "yum check-update",
+// yum installs packages on RHEL and CentOS 7, which predate dnf.
+var yum = distroInfo{name: "rhel", packageManager: "yum", installCmd: "yum install -y", removeCmd: "yum remove -y", updateCmd: "yum check-update"}
+
+// detectDistro identifies the distribution from os-release, which is present
+// on every systemd-era distribution, and falls back to looking for a known
+// package manager.
+func detectDistro() distroInfo {
+	if _, ok := common.TermuxPrefix(); ok {
+		return packageManagers["termux"]
+	}
+	if runtime.GOOS == "freebsd" {
+		d := packageManagers["freebsd"]
+		if rel, err := common.ReadOSRelease(); err == nil {
+			d.pretty = rel.PrettyName
 		}
+		return d
 	}
-
-	if _, err := exec.LookPath("pacman"); err == nil {
-		// Arch Linux
-		return distroInfo{
-			name:           "arch",
-			packageManager: "pacman",
-			installCmd:     "pacman -S --noconfirm",
-			updateCmd:      "pacman -Sy",
+	if rel, err := common.ReadOSRelease(); err == nil {
+		if d, ok := distroFromOSRelease(rel); ok {
+			return d
 		}
 	}
+	return detectByPackageManager()
+}
 
-	if _, err := exec.LookPath("apk"); err == nil {
-		// Alpine Linux
-		return distroInfo{
-			name:           "alpine",
-			packageManager: "apk",
-			installCmd:     "apk add",
-			updateCmd:      "apk update",
+// distroFromOSRelease picks the first known base among the ID and ID_LIKE
+// entries, and the bases of known derivatives, whose package manager is
+// installed.
+func distroFromOSRelease(rel common.OSRelease) (distroInfo, bool) {
+	var ids []string
+	for _, id := range append([]string{rel.ID}, rel.IDLike...) {
+		ids = append(ids, id)
+		if base, ok := derivatives[id]; ok {
+			ids = append(ids, base)
+		}
+	}
+	pretty := rel.PrettyName
+	if pretty == "" {
+		pretty = rel.ID
+	}
+	for _, id := range ids {
+		d, ok := packageManagers[id]
+		if !ok {
+			continue
+		}
+		if _, err := exec.LookPath(d.packageManager); err != nil {
+			if d.name != "rhel" {
+				continue
+			}
+			if _, err := exec.LookPath(yum.packageManager); err != nil {
+				continue
+			}
+			d = yum
 		}
+		d.pretty = pretty
+		d.derived = d.name != rel.ID
+		return d, true
 	}
+	return distroInfo{}, false
+}
 
+func detectByPackageManager() distroInfo {
+	for _, name := range []string{"debian", "fedora", "arch", "alpine", "suse", "gentoo", "void"} {
+		d := packageManagers[name]
+		if _, err := exec.LookPath(d.packageManager); err == nil {
+			return d
+		}
+	}
+	if _, err := exec.LookPath(yum.packageManager); err == nil {
+		return yum
+	}
 	return distroInfo{
 		name:           "unknown",
 		packageManager: "unknown",
@@ -143,16 +317,63 @@ func detectDistro() distroInfo {
 
 type depsCheckMsg struct {
 	missing []dependency
-	distro  distroInfo
-	err     error
+	// timedOut lists the dependencies whose check did not finish.
+	timedOut []dependency
+	distro   distroInfo
+	err      error
 }
 
 type depsInstallMsg struct {
 	err error
 }
 
-func checkDependencies() tea.Msg {
+// depsSkippedMsg carries what is missing when --skip-deps fetches releases
+// without waiting for the check.
+type depsSkippedMsg depsCheckMsg
+
+// DependencyNone disables the dependency check in Options.Dependencies.
+const DependencyNone = "none"
+
+// DependencyIDs lists the valid entries of Options.Dependencies.
+func DependencyIDs() []string {
+	ids := make([]string, 0, len(requiredDeps))
+	for _, dep := range requiredDeps {
+		ids = append(ids, dep.id)
+	}
+	return ids
+}
+
+// checkDependencies looks for the missing dependencies selected by opts.
+func checkDependencies(opts Options) tea.Cmd {
+	return func() tea.Msg {
+		return findMissing(opts)
+	}
+}
+
+// skipDependencies checks in the background of --skip-deps, so what is
+// missing can still be reported.
+func skipDependencies(opts Options) tea.Cmd {
+	return func() tea.Msg {
+		return depsSkippedMsg(findMissing(opts))
+	}
+}
+
+// checked reports whether opts selects dep for the check.
+func (o Options) checked(dep dependency) bool {
+	if o.NoCgoDeps && dep.cgo {
+		return false
+	}
+	return len(o.Dependencies) == 0 || slices.Contains(o.Dependencies, dep.id)
+}
+
+func findMissing(opts Options) depsCheckMsg {
+	if slices.Contains(opts.Dependencies, DependencyNone) {
+		return depsCheckMsg{}
+	}
 	distro := detectDistro()
+	if sys, ok := common.DetectImmutable(); ok {
+		distro = distroInfo{name: "immutable", pretty: sys.Name, packageManager: "none", guidance: sys.Guidance}
+	}
 
 	if distro.packageManager == "unknown" {
 		return depsCheckMsg{
@@ -160,18 +381,47 @@ func checkDependencies() tea.Msg {
 		}
 	}
 
-	var missing []dependency
+	var missing, timedOut []dependency
 	for _, dep := range requiredDeps {
-		parts := strings.Fields(dep.checkCmd)
-		cmd := exec.Command(parts[0], parts[1:]...)
-		if err := cmd.Run(); err != nil {
+		if !opts.checked(dep) {
+			continue
+		}
+		parts := strings.Fields(dep.check(distro))
+		ctx, cancel := context.WithTimeout(context.Background(), depCheckTimeout)
+		err := common.CommandContext(ctx, parts[0], parts[1:]...).Run()
+		cancel()
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			// A hung check says nothing about the package, installing
+			// it would not help either.
+			common.Log.Warn("dependency check timed out", "dep", dep.id, "cmd", parts)
+			timedOut = append(timedOut, dep)
+		case err != nil:
 			missing = append(missing, dep)
 		}
 	}
 	return depsCheckMsg{
-		missing: missing,
-		distro:  distro,
+		missing:  missing,
+		timedOut: timedOut,
+		distro:   distro,
+	}
+}
+
+// depCheckTimeout bounds each check command, which can hang on e.g. a
+// network-mounted PATH.
+const depCheckTimeout = 10 * time.Second
+
+// timeoutNotice names the checks that timed out, empty when none did.
+func timeoutNotice(msg depsCheckMsg) string {
+	if len(msg.timedOut) == 0 {
+		return ""
 	}
+	checks := make([]string, 0, len(msg.timedOut))
+	for _, dep := range msg.timedOut {
+		checks = append(checks, fmt.Sprintf("%s (%s)", dep.name, dep.check(msg.distro)))
+	}
+	return InfoStyle.Render(fmt.Sprintf("! Dependency checks timed out after %s, assuming present: %s",
+		depCheckTimeout, strings.Join(checks, ", ")))
 }
 
 func installDependencies(distro distroInfo, deps []dependency) tea.Cmd {
@@ -179,31 +429,17 @@ func installDependencies(distro distroInfo, deps []dependency) tea.Cmd {
 		// Update package lists
 		if distro.updateCmd != "" {
 			updateParts := strings.Fields(distro.updateCmd)
-			updateCmd := exec.Command(updateParts[0], updateParts[1:]...)
+			updateCmd := common.Privileged(updateParts[0], updateParts[1:]...)
 			_ = updateCmd.Run() // Ignore errors for update
 		}
 
-		// Collect unique package names
-		packages := make(map[string]bool)
-		for _, dep := range deps {
-			if pkgName, ok := dep.packageName[distro.name]; ok {
-				for _, pkg := range strings.Fields(pkgName) {
-					packages[pkg] = true
-				}
-			}
-		}
-
-		// Install packages
-		var pkgList []string
-		for pkg := range packages {
-			pkgList = append(pkgList, pkg)
-		}
-
+		pkgList := distro.packages(deps)
 		installParts := strings.Fields(distro.installCmd)
 		installParts = append(installParts, pkgList...)
-		installCmd := exec.Command(installParts[0], installParts[1:]...)
+		installCmd := common.Privileged(installParts[0], installParts[1:]...)
 
-		if err := installCmd.Run(); err != nil {
+		if out, err := installCmd.CombinedOutput(); err != nil {
+			common.Log.Error("installing packages failed", "output", string(out))
 			return depsInstallMsg{err: fmt.Errorf("failed to install packages: %w", err)}
 		}
 
@@ -0,0 +1,35 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// AltScreenSupported reports whether the picker can use the alternate
+// screen and leave the scrollback untouched. GNU screen disables the
+// alternate screen unless configured to, which leaves the picker behind
+// as garbage, and tmux can be configured the same way.
+func AltScreenSupported() bool {
+	if !term.IsTerminal(os.Stdout.Fd()) || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	if os.Getenv("STY") != "" {
+		return false
+	}
+	if os.Getenv("TMUX") != "" {
+		out, err := exec.Command("tmux", "show-options", "-gwv", "alternate-screen").Output()
+		if err == nil && strings.TrimSpace(string(out)) == "off" {
+			return false
+		}
+	}
+	return true
+}
+
+// Interactive reports whether someone can answer the picker and prompts,
+// that is whether stdin or stdout is a terminal.
+func Interactive() bool {
+	return term.IsTerminal(os.Stdin.Fd()) || term.IsTerminal(os.Stdout.Fd())
+}
@@ -0,0 +1,275 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// devTool is a tool offered after the install, installed with go install.
+type devTool struct {
+	name string
+	pkg  string
+	desc string
+}
+
+// devTools are the tools most editors and CI setups expect.
+var devTools = []devTool{
+	{"gopls", "golang.org/x/tools/gopls@latest", "language server for editors"},
+	{"dlv", "github.com/go-delve/delve/cmd/dlv@latest", "debugger"},
+	{"staticcheck", "honnef.co/go/tools/cmd/staticcheck@latest", "static analysis"},
+	{"golangci-lint", "github.com/golangci/golangci-lint/v2/cmd/golangci-lint@latest", "linter runner"},
+	{"goimports", "golang.org/x/tools/cmd/goimports@latest", "formatter that fixes imports"},
+}
+
+// ToolNone disables the tools prompt in the tools setting.
+const ToolNone = "none"
+
+// DevToolNames lists the valid entries of the tools setting.
+func DevToolNames() []string {
+	names := make([]string, 0, len(devTools))
+	for _, t := range devTools {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+type toolsState int
+
+const (
+	toolsStateSelect toolsState = iota
+	toolsStateInstalling
+	toolsStateDone
+)
+
+type toolDoneMsg struct {
+	index int
+	err   error
+}
+
+type toolsModel struct {
+	state    toolsState
+	cursor   int
+	selected []bool
+	// done and errs hold the outcome per tool, current is the one being
+	// installed.
+	errs    []error
+	done    []bool
+	current int
+	spinner spinner.Model
+}
+
+func newToolsModel(preselect []string) toolsModel {
+	s := newSpinner()
+	m := toolsModel{
+		selected: make([]bool, len(devTools)),
+		errs:     make([]error, len(devTools)),
+		done:     make([]bool, len(devTools)),
+		current:  -1,
+		spinner:  s,
+	}
+	for i, t := range devTools {
+		m.selected[i] = slices.Contains(preselect, t.name)
+	}
+	return m
+}
+
+// installTool runs go install for tool as the invoking user, so the binary
+// lands in their GOBIN and the module cache stays theirs.
+func installTool(index int) tea.Cmd {
+	return func() tea.Msg {
+		return toolDoneMsg{index: index, err: installDevTool(devTools[index])}
+	}
+}
+
+func installDevTool(t devTool) error {
+	account, err := common.InvokingUser()
+	if err != nil {
+		return err
+	}
+	if out, err := goCommand(account, "install", t.pkg).CombinedOutput(); err != nil {
+		common.Log.Error("go install failed", "tool", t.name, "output", string(out))
+		lines := strings.Split(strings.TrimSpace(string(out)), "\n")
+		return fmt.Errorf("%w: %s", err, lines[len(lines)-1])
+	}
+	return nil
+}
+
+// next starts the next selected tool, or finishes when none is left.
+func (m toolsModel) next() (tea.Model, tea.Cmd) {
+	for i := m.current + 1; i < len(devTools); i++ {
+		if m.selected[i] {
+			m.current = i
+			m.state = toolsStateInstalling
+			return m, tea.Batch(m.spinner.Tick, installTool(i))
+		}
+	}
+	m.state = toolsStateDone
+	return m, tea.Quit
+}
+
+func (m toolsModel) Init() tea.Cmd {
+	switch m.state {
+	case toolsStateInstalling:
+		return tea.Batch(m.spinner.Tick, installTool(m.current))
+	case toolsStateDone:
+		return tea.Quit
+	}
+	return nil
+}
+
+func (m toolsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case interruptMsg:
+		m.state = toolsStateDone
+		return m, tea.Quit
+	case tea.KeyMsg:
+		if m.state != toolsStateSelect {
+			if msg.String() == "ctrl+c" {
+				m.state = toolsStateDone
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "up", "k":
+			m.cursor = max(m.cursor-1, 0)
+		case "down", "j":
+			m.cursor = min(m.cursor+1, len(devTools)-1)
+		case " ", "x":
+			m.selected[m.cursor] = !m.selected[m.cursor]
+		case "a":
+			all := !slices.Contains(m.selected, false)
+			for i := range m.selected {
+				m.selected[i] = !all
+			}
+		case "enter":
+			return m.next()
+		case "q", "esc", "ctrl+c":
+			m.selected = make([]bool, len(devTools))
+			m.state = toolsStateDone
+			return m, tea.Quit
+		}
+	case toolDoneMsg:
+		m.done[msg.index], m.errs[msg.index] = true, msg.err
+		return m.next()
+	case spinner.TickMsg:
+		if m.state != toolsStateInstalling {
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+func (m toolsModel) View() string {
+	var sb strings.Builder
+	if m.state == toolsStateSelect {
+		sb.WriteString(TitleStyle.Render("Install developer tools?") + "\n")
+		for i, t := range devTools {
+			cursor, box := "  ", "[ ]"
+			if i == m.cursor {
+				cursor = "> "
+			}
+			if m.selected[i] {
+				box = "[x]"
+			}
+			sb.WriteString(fmt.Sprintf("%s%s %-14s %s\n", cursor, box, t.name, InfoStyle.Render(t.desc)))
+		}
+		sb.WriteString(helpFooter(keyHelp{
+			key.NewBinding(key.WithKeys("up", "k", "down", "j"), key.WithHelp("↑/↓", "move")),
+			key.NewBinding(key.WithKeys(" ", "x"), key.WithHelp("space", "toggle")),
+			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "all")),
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "install")),
+			key.NewBinding(key.WithKeys("q", "esc"), key.WithHelp("q", "skip")),
+		}))
+		return sb.String()
+	}
+
+	if !slices.Contains(m.selected, true) {
+		return ""
+	}
+	sb.WriteString("\n")
+	for i, t := range devTools {
+		switch {
+		case !m.selected[i]:
+		case m.done[i] && m.errs[i] != nil:
+			sb.WriteString(ErrorStyle.Render(fmt.Sprintf("✗ %s: %v", t.name, m.errs[i])) + "\n")
+		case m.done[i]:
+			sb.WriteString(SuccessStyle.Render("✓ "+t.name) + "\n")
+		case i == m.current && m.state == toolsStateInstalling:
+			sb.WriteString(fmt.Sprintf("%s Installing %s...\n", m.spinner.View(), t.name))
+		default:
+			sb.WriteString(InfoStyle.Render("  "+t.name+" (skipped)") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// failed returns the tools whose installation failed.
+func (m toolsModel) failed() []string {
+	var names []string
+	for i, t := range devTools {
+		if m.errs[i] != nil {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
+
+// RunTools installs developer tools with the new toolchain. With ask a
+// multi-select list offers them, preselecting names, otherwise names are
+// installed right away. A failing tool does not stop the others.
+func RunTools(names []string, ask bool) error {
+	var m tea.Model = newToolsModel(names)
+	if !ask {
+		if len(names) == 0 {
+			return nil
+		}
+		m, _ = m.(toolsModel).next()
+	}
+	final, err := NewProgram(m).Run()
+	if err != nil {
+		return err
+	}
+	if failed := final.(toolsModel).failed(); len(failed) > 0 {
+		return fmt.Errorf("could not install %s, see the log for details", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// RunToolsHeadless installs the tools names without the TUI, for --json and
+// --plain, reporting each as a tool step to sinks.
+func RunToolsHeadless(names []string, sinks []EventSink) error {
+	var failed []string
+	for _, t := range devTools {
+		if !slices.Contains(names, t.name) {
+			continue
+		}
+		publish(sinks, StepEvent{Step: StepTool, Status: StatusStarted, Tool: t.name})
+		if err := installDevTool(t); err != nil {
+			publish(sinks, StepEvent{Step: StepTool, Status: StatusFailed, Tool: t.name, err: err})
+			failed = append(failed, t.name)
+			continue
+		}
+		publish(sinks, StepEvent{Step: StepTool, Status: StatusDone, Tool: t.name})
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("could not install %s, see the log for details", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Installed reports whether the final model of the install program put a
+// toolchain in place.
+func Installed(m tea.Model) bool {
+	im, ok := m.(installModel)
+	return ok && im.state == installStateDone && im.err == nil && im.opts.runs(StepExtract)
+}
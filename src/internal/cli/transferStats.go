@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"sync"
+	"time"
+)
+
+// transferStats tracks the throughput of the running transfer, the download
+// or the extraction, for the installing view. Steps update it from their
+// goroutines while View reads it.
+type transferStats struct {
+	mu      sync.Mutex
+	step    string
+	total   int64
+	n       int64
+	started time.Time
+	// current is a moving average of recent throughput in bytes per
+	// second, sampled every progressInterval.
+	current    float64
+	lastSample time.Time
+	lastN      int64
+}
+
+// start resets the stats for a new transfer of total bytes, 0 if unknown.
+func (s *transferStats) start(step string, total int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.step, s.total, s.n, s.current = step, total, 0, 0
+	s.started, s.lastSample, s.lastN = now, now, 0
+}
+
+// add counts n more bytes.
+func (s *transferStats) add(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.n += int64(n)
+	now := time.Now()
+	if dt := now.Sub(s.lastSample); dt >= progressInterval {
+		rate := float64(s.n-s.lastN) / dt.Seconds()
+		if s.current == 0 {
+			s.current = rate
+		} else {
+			s.current = 0.3*rate + 0.7*s.current
+		}
+		s.lastSample, s.lastN = now, s.n
+	}
+}
+
+// line describes the transfer of step, empty when another one runs or it
+// has not made progress yet.
+func (s *transferStats) line(step string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.step != step || s.n == 0 || s.current == 0 {
+		return ""
+	}
+	avg := float64(s.n) / time.Since(s.started).Seconds()
+	line := fmt.Sprintf("%s/s (avg %s/s)", common.FormatSize(int64(s.current)), common.FormatSize(int64(avg)))
+	if s.total <= 0 {
+		return fmt.Sprintf("%s  %s", common.FormatSize(s.n), line)
+	}
+	line = fmt.Sprintf("%s / %s  %s", common.FormatSize(s.n), common.FormatSize(s.total), line)
+	if remaining := s.total - s.n; remaining > 0 {
+		eta := time.Duration(float64(remaining) / s.current * float64(time.Second))
+		line += "  ETA " + eta.Round(time.Second).String()
+	}
+	return line
+}
+
+// Write counts p, so the stats can sit behind an io.Writer.
+func (s *transferStats) Write(p []byte) (int, error) {
+	s.add(len(p))
+	return len(p), nil
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// RunUninstall removes the active toolchain, the shell completions
+// installed with 'go-install completion install' and the PATH entries
+// go-install added to shell configuration files. purge also removes the side-by-side
+// toolchains, backups, archive cache and the state itself. A failing
+// preRemoveCmd hook leaves everything in place.
+func RunUninstall(yes, purge bool, preRemoveCmd string) error {
+	state, err := common.LoadState()
+	if err != nil {
+		return err
+	}
+
+	question := fmt.Sprintf("Remove %s, the shell completions and the PATH entries go-install added?", common.GoRoot)
+	if purge {
+		question = fmt.Sprintf("Remove %s, all kept toolchains, backups, the archive cache, the shell completions and the PATH entries go-install added?", common.GoRoot)
+	}
+	if !yes && !confirm(question) {
+		return fmt.Errorf("uninstall %w", common.ErrCancelled)
+	}
+
+	if _, err := os.Stat(common.GoRoot); err == nil {
+		if preRemoveCmd != "" {
+			version, _ := common.InstalledVersion(common.GoRoot)
+			if err := common.HookCommand(common.HookPreRemove, preRemoveCmd, version).Run(); err != nil {
+				return fmt.Errorf("pre-remove hook failed, nothing was removed: %w", err)
+			}
+		}
+		if err := os.RemoveAll(common.GoRoot); err != nil {
+			return err
+		}
+		fmt.Println(SuccessStyle.Render("✓ Removed " + common.GoRoot))
+	}
+	state.RemoveFile(common.GoRoot)
+
+	// The PATH entries are edited as the user who owns the files.
+	owner, _ := common.InvokingUser()
+	for _, f := range append([]common.ManagedFile(nil), state.Files...) {
+		if f.Kind == common.KindBinLink {
+			if err := common.UnlinkBinaries([]string{f.Path}); err != nil {
+				return err
+			}
+			fmt.Println(SuccessStyle.Render("✓ Removed the link " + f.Path))
+			state.RemoveFile(f.Path)
+			continue
+		}
+		if f.Kind == common.KindAlternatives {
+			for _, target := range f.Added {
+				if err := common.RemoveAlternatives(target); err != nil {
+					return err
+				}
+			}
+			fmt.Println(SuccessStyle.Render("✓ Removed the update-alternatives link " + f.Path))
+			state.RemoveFile(f.Path)
+			continue
+		}
+		if f.Kind == common.KindCompletion {
+			if isCompletionFile(f.Path) {
+				if err := os.Remove(f.Path); err != nil && !os.IsNotExist(err) {
+					return err
+				}
+				fmt.Println(SuccessStyle.Render("✓ Removed the completion " + f.Path))
+			}
+			state.RemoveFile(f.Path)
+			continue
+		}
+		if f.Kind != common.KindShellConfig {
+			continue
+		}
+		lines := f.Added
+		if len(lines) == 0 {
+			lines = common.DefaultShellLines()
+		}
+		removed, err := common.RemoveShellChange(common.ShellChange{File: f.Path, Lines: lines, Owner: owner})
+		switch {
+		case os.IsNotExist(err):
+		case err != nil:
+			return err
+		case removed:
+			fmt.Println(SuccessStyle.Render("✓ Removed the PATH entry from " + f.Path))
+		default:
+			fmt.Println(InfoStyle.Render("! The PATH entry in " + f.Path + " was changed since, remove it yourself"))
+		}
+		state.RemoveFile(f.Path)
+	}
+
+	if !purge {
+		return state.Save()
+	}
+	for _, dir := range []string{common.VersionsDir, common.BackupsDir, common.ArchiveCacheDir(), common.ManifestsDir} {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	fmt.Println(SuccessStyle.Render("✓ Removed kept toolchains, backups and cached archives"))
+	if err := os.Remove(common.StatePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"go-installer/common"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunUninstallRemovesCompletions(t *testing.T) {
+	dir := t.TempDir()
+	prevRoot, prevState, prevPaths := common.GoRoot, common.StatePath, completionPaths
+	defer func() { common.GoRoot, common.StatePath, completionPaths = prevRoot, prevState, prevPaths }()
+	common.GoRoot = filepath.Join(dir, "go")
+	common.StatePath = filepath.Join(dir, "state.json")
+	completion := filepath.Join(dir, "completions", "go-install")
+	completionPaths = map[string][]string{"bash": {completion}}
+
+	if err := os.MkdirAll(common.GoRoot, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := installCompletion("bash"); err != nil {
+		t.Fatal(err)
+	}
+	// A recorded path that is not a completion location is left alone.
+	other := filepath.Join(dir, "other")
+	if err := os.WriteFile(other, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := common.RecordFile(other, common.KindCompletion, "created"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RunUninstall(true, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(completion); !os.IsNotExist(err) {
+		t.Errorf("completion %s not removed: %v", completion, err)
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("%s removed: %v", other, err)
+	}
+	state, err := common.LoadState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range state.Files {
+		if f.Kind == common.KindCompletion {
+			t.Errorf("state still records the completion %s", f.Path)
+		}
+	}
+}
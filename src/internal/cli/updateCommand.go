@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+)
+
+// RunUpdate upgrades the active toolchain to the newest patch of its minor
+// series, or to the newest stable release when major is set. The replace
+// step backs up the old toolchain, so 'go-install rollback' restores it.
+func RunUpdate(major, yes bool) error {
+	current, err := common.InstalledVersion(common.GoRoot)
+	if err != nil {
+		return fmt.Errorf("no Go installation found in %s: %w", common.GoRoot, err)
+	}
+
+	fmt.Println(InfoStyle.Render("Fetching Go releases metadata..."))
+	releases, err := getReleases()
+	if err != nil {
+		return err
+	}
+
+	var target common.GoRelease
+	if major {
+		target, err = common.LatestStable(releases)
+	} else {
+		target, err = common.LatestPatch(releases, current)
+	}
+	if err != nil {
+		return err
+	}
+
+	curVer, err := common.ParseVersion(current)
+	if err != nil {
+		return err
+	}
+	targetVer, err := common.ParseVersion(target.Version)
+	if err != nil {
+		return err
+	}
+	if !curVer.Less(targetVer) {
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ %s is up to date", current)))
+		return nil
+	}
+
+	if !yes && !confirm(fmt.Sprintf("Update %s → %s?", current, target.Version)) {
+		return fmt.Errorf("update %w", common.ErrCancelled)
+	}
+
+	return runInstall(target.Version, releases, Options{KeepBackups: DefaultKeepBackups, Yes: yes})
+}
+
+// runInstall runs the install steps for version in the TUI and reports the
+// error the install model ended with.
+func runInstall(version string, releases []common.GoRelease, opts Options) error {
+	m := newInstallModel(version, common.GetOS(), common.GetArch(), releases, opts)
+	final, err := NewProgram(m).Run()
+	if err != nil {
+		return err
+	}
+	if im, ok := final.(installModel); ok && im.err != nil {
+		return im.err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"time"
+)
+
+// ensureMultiVersion migrates a single-directory install into the versions
+// store after asking the user, so enabling multi-version mode keeps the
+// existing toolchain.
+func ensureMultiVersion(yes bool) error {
+	legacy, ok := common.LegacyInstall()
+	if !ok {
+		return nil
+	}
+	question := fmt.Sprintf("%s contains %s. Move it into the versions store (%s) and replace it with a symlink?",
+		common.GoRoot, legacy, common.VersionsDir)
+	if !yes && !confirm(question) {
+		return fmt.Errorf("multi-version mode needs %s migrated into the versions store", common.GoRoot)
+	}
+	version, err := common.MigrateLegacy()
+	if err != nil {
+		return fmt.Errorf("migration failed: %w", err)
+	}
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ Migrated %s to %s", version, common.VersionRoot(version))))
+	return nil
+}
+
+// RunUse makes version the active toolchain, installing it into the
+// versions store when needed.
+func RunUse(version string, yes bool, heartbeat time.Duration) error {
+	version = common.NormalizeVersion(version)
+	if err := ensureMultiVersion(yes); err != nil {
+		return err
+	}
+	if !common.IsStored(version) {
+		if err := installVersion(version, heartbeat); err != nil {
+			return err
+		}
+	}
+	if err := common.UseVersion(version); err != nil {
+		return err
+	}
+	fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ %s is now active (%s → %s)", version, common.GoRoot, common.VersionRoot(version))))
+	return nil
+}
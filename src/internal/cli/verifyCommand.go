@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"fmt"
+	"go-installer/common"
+	"os"
+)
+
+// RunVerify re-hashes an installed toolchain and compares it with the
+// manifest stored when it was installed. An empty version verifies GoRoot.
+func RunVerify(version string) error {
+	root := common.GoRoot
+	installed, _ := common.InstalledVersion(common.GoRoot)
+	switch {
+	case version == "":
+		if installed == "" {
+			return fmt.Errorf("no Go installation found in %s", common.GoRoot)
+		}
+		version = installed
+	case common.NormalizeVersion(version) != installed:
+		version = common.NormalizeVersion(version)
+		root = common.VersionRoot(version)
+	}
+
+	fmt.Println(InfoStyle.Render(fmt.Sprintf("Verifying %s in %s...", version, root)))
+	diff, err := common.VerifyTree(root, version)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("no manifest recorded for %s, run 'go-install reinstall' to create one", version)
+	}
+	if err != nil {
+		return err
+	}
+	if diff.Clean() {
+		fmt.Println(SuccessStyle.Render(fmt.Sprintf("✓ %s matches the installed files", version)))
+		return nil
+	}
+
+	for _, group := range []struct {
+		label string
+		paths []string
+	}{
+		{"modified", diff.Modified},
+		{"missing", diff.Missing},
+		{"extra", diff.Extra},
+	} {
+		for _, p := range group.paths {
+			fmt.Printf("  %-8s %s\n", group.label, p)
+		}
+	}
+	return fmt.Errorf("%s differs from its manifest: %d modified, %d missing, %d extra files",
+		version, len(diff.Modified), len(diff.Missing), len(diff.Extra))
+}
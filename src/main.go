@@ -1,37 +1,812 @@
+//go:build !minimal
+
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"go-installer/common"
 	"go-installer/internal/cli"
 	"os"
+	"path/filepath"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
+// crashReports enables writing a support bundle on panics and fatal errors.
+var crashReports bool
+
+// preRemoveCmd is the hook run before uninstall removes the toolchain.
+var preRemoveCmd string
+
+// protect lists the versions prune keeps regardless of --keep.
+var protect string
+
+// config holds the merged settings, see common.LoadConfig.
+var config *common.Config
+
 func main() {
+	defer func() { releaseLock() }()
 	help := flag.Bool("h", false, "show help")
 	flag.BoolVar(help, "help", false, "show help")
 	version := flag.String("version", "", "Go version to install")
+	yes := flag.Bool("yes", false, "non-interactive mode, assume yes for all prompts")
+	flag.BoolVar(yes, "y", false, "non-interactive mode, assume yes for all prompts")
+	force := flag.Bool("force", false, "replace an existing Go installation without asking")
+	allowSystemChanges := flag.Bool("allow-system-changes", false, "allow installing system packages in --yes mode")
+	deps := flag.String("deps", cli.DepsAsk, "missing system packages: ask, install or report")
+	noCgoDeps := flag.Bool("no-cgo-deps", false, "do not check for or install gcc and make, for pure Go development")
+	dependencies := flag.String("dependencies", "", "comma separated dependencies to check ("+strings.Join(cli.DependencyIDs(), ", ")+"), none disables the check")
+	skipDeps := flag.Bool("skip-deps", false, "fetch releases right away and only print missing dependencies")
+	prefix := flag.String("prefix", common.DefaultPrefix, "install Go into PREFIX/go")
+	mirror := flag.String("mirror", "", "download release archives from this go.dev/dl/ mirror")
+	proxy := flag.String("proxy", "", "HTTP(S) proxy URL, overrides HTTPS_PROXY")
+	printScript := flag.Bool("print-script", false, "print a POSIX shell script that installs the version instead of installing it")
+	targetOS := flag.String("os", "", "with --print-script, write the script for this GOOS instead of the detected one")
+	arch := flag.String("arch", "", "install archives for this architecture instead of the detected one, e.g. amd64 under Rosetta")
+	keepArchive := flag.Bool("keep-archive", true, "keep the downloaded archive in the cache")
+	noEnv := flag.Bool("no-env", false, "do not touch shell configuration files, print the PATH line instead")
+	workspace := flag.String("workspace", cli.WorkspaceAsk, "set up ~/go with its bin on PATH: ask, path, export (also GOPATH and GOBIN) or skip")
+	pinToolchain := flag.String("pin-toolchain", cli.ToolchainAsk, "set GOTOOLCHAIN=local for Go 1.21+ in the configure step: ask, env (go env -w), shell or skip")
+	packagedGo := flag.String("packaged-go", cli.PackagedGoAsk, "another Go (package manager, snap, Homebrew, asdf) shadows the new one: ask, remove it, prefer the new one on PATH or ignore")
+	alternatives := flag.Bool("alternatives", false, "register go and gofmt with update-alternatives (Debian, Ubuntu), combine with --shell-config skip to use it instead of PATH edits")
+	shellConfig := flag.String("shell-config", "auto", "add Go to PATH in the shell configuration (auto), link go and gofmt into /usr/local/bin instead (symlink) or skip")
+	keepBackups := flag.Int("keep-backups", cli.DefaultKeepBackups, "number of replaced installations kept for rollback")
+	only := flag.String("only", "", "run only these comma-separated steps (download,verify,extract,replace,configure)")
+	skip := flag.String("skip", "", "skip these comma-separated steps")
+	postInstallCmd := flag.String("post-install-cmd", "", "shell command to run after a successful install with the new Go on PATH")
+	envProfile := flag.String("env-profile", "", "write the go env settings of this [env-profiles.NAME] config table after installing")
+	tools := flag.String("tools", "", "comma separated developer tools to go install afterwards ("+strings.Join(cli.DevToolNames(), ", ")+"), none skips the prompt")
+	labels := flag.String("label", "", "comma separated KEY=VALUE labels stored for this host on install, see 'label'")
+	flag.StringVar(&protect, "protect", "", "comma separated versions prune never removes")
+	flag.StringVar(&preRemoveCmd, "pre-remove-cmd", "", "shell command to run before uninstall removes the toolchain, which is still on PATH")
+	connections := flag.Int("connections", 1, "download the archive over this many parallel connections")
+	jsonOut := flag.Bool("json", false, "print newline-delimited JSON events instead of the TUI, implies --yes")
+	eventsFD := flag.Int("events-fd", 0, "also write the JSON events to this file descriptor while the TUI runs")
+	plainOut := flag.Bool("plain", false, "print plain status lines without colors, spinners or emoji, for screen readers and dumb terminals; implies --yes")
+	noAltScreen := flag.Bool("no-altscreen", false, "keep the version picker in the normal screen buffer")
+	allowEOL := flag.Bool("allow-eol", false, "install releases that no longer get security fixes without refusing in --yes mode")
+	prerelease := flag.Bool("prerelease", false, "list release candidates and betas in the picker (toggle with p)")
+	all := flag.Bool("all", false, "list the full release history in the picker instead of loading it on demand")
+	flag.BoolVar(&crashReports, "crash-report", false, "write a diagnostics bundle when go-install crashes")
+	verbose := flag.Bool("v", false, "log more details")
+	veryVerbose := flag.Bool("vv", false, "log everything, including each extracted file and HTTP headers")
+	heartbeat := flag.Duration("heartbeat", cli.DefaultHeartbeat, "interval of keep-alive progress lines on stderr in --json and --plain output (0 disables)")
 	flag.Parse()
+	config = common.LoadConfig(flag.CommandLine, "h", "help", "y", "v", "vv")
+	config.Problems = append(config.Problems, cli.ApplyTheme(config.Theme())...)
+	// Only an explicit --plain answers prompts, a dumb terminal asks first.
+	dumbTerminal := !*plainOut && cli.PlainRequested()
+	if *plainOut || dumbTerminal {
+		*plainOut = true
+		cli.SetPlain()
+	}
+	immutable, isImmutable := common.DetectImmutable()
+	if isImmutable && config.Origin("prefix") == common.OriginDefault {
+		// The image owns /usr/local, install for the invoking user instead.
+		if account, err := common.InvokingUser(); err == nil {
+			*prefix = filepath.Join(account.Home, ".local")
+		}
+	}
+	runtimeName, inContainer := common.DetectContainer()
+	if inContainer {
+		// Image builds read no shell configuration and answer no prompts.
+		if config.Origin("shell-config") == common.OriginDefault && config.Origin("no-env") == common.OriginDefault {
+			*shellConfig = "skip"
+		}
+		if config.Origin("yes") == common.OriginDefault {
+			*yes = true
+		}
+	}
+	termuxPrefix, inTermux := common.TermuxPrefix()
+	if inTermux {
+		common.SetStateRoot(termuxPrefix)
+		if config.Origin("prefix") == common.OriginDefault {
+			*prefix = termuxPrefix
+		}
+	}
+	if err := applySettings(*prefix, *mirror, *proxy); err != nil {
+		fatal(err)
+	}
+	if account, err := common.InvokingUser(); err == nil && !inTermux && account.UID != 0 && common.InHome(common.InstallPrefix, account) {
+		// A prefix in the invoking user's home is theirs, no root needed.
+		common.SetUserInstall(account)
+	}
+	if *arch != "" {
+		common.SetArch(*arch)
+	}
+	verbosity := 0
+	if *veryVerbose {
+		verbosity = 2
+	} else if *verbose {
+		verbosity = 1
+	}
+	if err := common.OpenLog(verbosity); err != nil && verbosity > 0 {
+		fmt.Fprintln(os.Stderr, cli.InfoStyle.Render("! "+err.Error()))
+	}
+	if crashReports {
+		defer reportPanic()
+	}
 
 	if *help {
-		fmt.Println("usage: go-install [--version VERSION]")
+		fmt.Println("usage: go-install [--version VERSION] [--yes [--allow-system-changes]]")
+		fmt.Println("       go-install exec VERSION -- COMMAND [ARGS...]")
+		fmt.Println("       go-install update [--major] [--yes]")
+		fmt.Println("       go-install reinstall [--yes]")
+		fmt.Println("       go-install check [--json|--metrics]")
+		fmt.Println("       go-install verify [VERSION]")
+		fmt.Println("       go-install doctor")
+		fmt.Println("       go-install config show [--origins] | doctor")
+		fmt.Println("       go-install completion bash|zsh|fish|install [SHELL]|uninstall")
+		fmt.Println("       go-install self-update [--yes]")
+		fmt.Println("       go-install changelog")
+		fmt.Println("       go-install audit")
+		fmt.Println("       go-install rollback [--yes]")
+		fmt.Println("       go-install uninstall [--yes] [--purge]")
+		fmt.Println("       go-install prune [--keep N] [--protect VERSION[,VERSION...]] [--yes]")
+		fmt.Println("       go-install du")
+		fmt.Println("       go-install use VERSION [--yes]")
+		fmt.Println("       go-install cache list|clean [--max-size SIZE] [--all]")
+		fmt.Println("       go-install manifest [--json]")
+		fmt.Println("       go-install label [KEY=VALUE...] [--remove KEY[,KEY...]]")
+		fmt.Println("       go-install ide vscode|goland")
+		fmt.Println("       go-install mirror-compare --mirror URL [VERSION]")
+		fmt.Println("       go-install platforms [VERSION]")
+		fmt.Println("       go-install env-setup [PROFILE]")
+		fmt.Println("       go-install batch MANIFEST.json|.toml|.yaml")
+		fmt.Println("       go-install fleet --hosts FILE [--version VERSION] [--keep-archive=false]")
+		fmt.Println("       go-install support-bundle")
 		fmt.Println("example: go-install --version 1.22.1")
-		fmt.Println("\nIf version is omitted, an interactive picker will be shown.")
-		fmt.Println("\nNote: This tool requires root privileges (use sudo).")
+		fmt.Println("example: go-install --version 1.22.1 --label role=ci,team=payments  # shown by check --json and --metrics")
+		fmt.Println("example: go-install exec 1.21.8 -- go test ./...")
+		fmt.Println("example: go-install --version 1.22.1 --print-script --os linux --arch arm64 > install-go.sh")
+		fmt.Println("example: go-install --prefix /opt fleet --hosts hosts.txt --version 1.22.1")
+		fmt.Println(`example: go-install batch farm.json  # {"versions":[{"version":"1.21.13"},{"version":"1.22.6","prefix":"/opt/go1.22"}]}`)
+		fmt.Println("\nIf version is omitted, an interactive picker will be shown. It lists the")
+		fmt.Println("supported releases and loads older ones on demand, or right away with --all.")
+		fmt.Println("Only the two newest minor releases get security fixes; older ones are marked [eol],")
+		fmt.Println("warned about before installing and refused with --yes unless --allow-eol or --force is given.")
+		fmt.Println("prune removes all but the newest --keep (2) kept toolchains and backups and the")
+		fmt.Println("cached archives of removed versions; the active toolchain and the versions in")
+		fmt.Println(`--protect (protect = ["go1.21.13"] in config.toml) are never removed.`)
+		fmt.Println("audit lists the advisories of the Go vulnerability database that affect the")
+		fmt.Println("installed toolchain and the oldest release fixing them; it exits 1 when any do.")
+		fmt.Println("The picker uses the alternate screen unless --no-altscreen is given or the")
+		fmt.Println("terminal (GNU screen, tmux with alternate-screen off) would not restore it.")
+		fmt.Println("Before installing, a summary of the version, archive, replaced toolchain and shell file")
+		fmt.Println("to edit is confirmed once; --force and --yes print it and go ahead without asking.")
+		fmt.Println("Workspace or toolchain lines chosen later are shown for confirmation again.")
+		fmt.Println("With --yes the latest stable release is installed without prompts;")
+		fmt.Println("missing system packages are only reported unless --allow-system-changes is given.")
+		fmt.Println("--json prints every step as a line of JSON for wrapper tools, ending with an")
+		fmt.Println(`event {"step":"install","status":"done"|"failed"}. It is the default when`)
+		fmt.Println("neither stdin nor stdout is a terminal. --events-fd N writes the same events")
+		fmt.Println("to an inherited file descriptor and keeps the TUI, e.g. --events-fd 3 3>ev.log.")
+		fmt.Println("Events carry schema, step, status, bytes, total, percent, version, tool and error;")
+		fmt.Println(`a {"step":"summary"} event lists what the install changes before it starts.`)
+		fmt.Println("On NixOS and image based systems (Silverblue, MicroOS) no packages are installed")
+		fmt.Println("and Go goes into ~/.local/go unless --prefix is set. A prefix in your home")
+		fmt.Println("needs no root and keeps its state in ~/.local/state/go-install.")
+		fmt.Println("In Termux no root is needed: Go goes into $PREFIX/go and packages come from pkg.")
+		fmt.Println("In Docker, Podman and LXC containers --yes and --shell-config skip are the defaults.")
+		fmt.Println("Under WSL a Windows Go on PATH is reported and the Linux toolchain is put first.")
+		fmt.Println("Under Rosetta the native darwin/arm64 toolchain is installed; --arch picks")
+		fmt.Println("another architecture, 'platforms' lists what a release publishes.")
+		fmt.Println("--deps install installs missing system packages without asking, --deps report")
+		fmt.Println("never installs them. Only CA certificates are required; gcc and make are")
+		fmt.Println("recommended for cgo and skipped entirely with --no-cgo-deps. --dependencies")
+		fmt.Println("picks what is checked, e.g. dependencies = [\"ca-certificates\"] or \"none\" in")
+		fmt.Println("config.toml on images where the package manager must not run. --skip-deps")
+		fmt.Println("fetches releases right away and only prints what is missing.")
+		fmt.Println("--prefix, --mirror, --proxy, --keep-archive=false and")
+		fmt.Println("--shell-config skip (or --no-env) are usually set once in config.toml, e.g. mirror = \"URL\".")
+		fmt.Println("\nEvery flag can also be set in config.toml (see 'config doctor' for the files),")
+		fmt.Println("in a GO_INSTALL_<FLAG> environment variable or, for --version, by a project's")
+		fmt.Println(".go-version file or go.mod toolchain line.")
+		fmt.Println("Colors adapt to light and dark terminals; a [theme] table in config.toml overrides")
+		fmt.Println("title, error, success, info, accent, warning or muted, e.g. title = \"#7d56f4\" or")
+		fmt.Println("info = { light = \"238\", dark = \"250\" }. NO_COLOR or CLICOLOR=0 turn colors off.")
+		fmt.Println("--plain (default with TERM=dumb) prints one line per step instead of the TUI,")
+		fmt.Println("without colors, spinners or emoji; like --json it installs --version or the")
+		fmt.Println("latest stable release and answers prompts with their defaults. With TERM=dumb")
+		fmt.Println("alone the install is confirmed with a [y/N] prompt first unless --yes is given.")
+		fmt.Println("\nA log of HTTP requests, commands run and files written is appended to")
+		fmt.Printf("%s, or go-install/go-install.log in $XDG_STATE_HOME when not\n", common.SystemLogPath)
+		fmt.Println("running as root; -v and -vv make it more detailed.")
+		fmt.Println("\nWith --crash-report (or GO_INSTALL_CRASH_REPORT=1) a diagnostics bundle is")
+		fmt.Println("written when go-install crashes, ready to attach to a bug report.")
+		fmt.Println("\nExit codes: 0 success, 1 other failure, 2 invalid arguments, 3 version not")
+		fmt.Println("found or not published for this platform, 4 checksum mismatch, 5 network")
+		fmt.Println("error, 6 permission denied, 7 aborted by the user, 8 another run in progress,")
+		fmt.Println("9 a newer stable release is available (check, e.g. in a nightly cron job).")
+		fmt.Println("A failing --post-install-cmd exits with the command's own code.")
+		fmt.Println("The configure step offers to create ~/go and put ~/go/bin on PATH; --workspace")
+		fmt.Println("path or export (also GOPATH and GOBIN) does it without asking, skip never does.")
+		fmt.Println("--shell-config symlink links go and gofmt into /usr/local/bin instead of editing")
+		fmt.Println("dotfiles, which also covers cron jobs, non-login shells and other users.")
+		fmt.Println("Go 1.21+ downloads the toolchain a go.mod asks for; the configure step offers")
+		fmt.Println("GOTOOLCHAIN=local, --pin-toolchain env or shell sets it without asking.")
+		fmt.Println("After installing, developer tools (gopls, dlv, ...) are offered for go install;")
+		fmt.Println("--tools gopls,dlv installs them without asking and --tools none skips the offer.")
+		fmt.Println("--env-profile NAME runs go env -w with the variables of the [env-profiles.NAME]")
+		fmt.Println("table of config.toml, e.g. GOPROXY and GONOSUMDB of a corporate profile;")
+		fmt.Println("env-setup applies a profile to an existing installation.")
+		fmt.Println("Hooks (--post-install-cmd, --pre-remove-cmd, e.g. post-install-cmd = \"go env -w GOPROXY=...\"")
+		fmt.Println("in config.toml) get GO_INSTALL_HOOK, GO_INSTALL_VERSION, GO_INSTALL_PREFIX and")
+		fmt.Println("GO_INSTALL_GOROOT; their output is copied into the log.")
+		fmt.Printf("\nNote: This tool requires root privileges (use %s).\n", common.Elevator())
 		return
 	}
 
-	if os.Geteuid() != 0 {
-		fmt.Println(cli.ErrorStyle.Render("\n✗ Error: This tool requires root privileges. Please run with sudo.\n"))
-		os.Exit(1)
+	if args := flag.Args(); len(args) > 0 {
+		if args[0] != "config" {
+			warnConfig()
+		}
+		runCommand(args[0], args[1:], *heartbeat)
+		return
+	}
+	warnConfig()
+	if *targetOS != "" && !*printScript {
+		fatal(fmt.Errorf("--os only applies to --print-script, installs run on this system"))
+	}
+	if *printScript {
+		if err := cli.RunPrintScript(os.Stdout, *version, *targetOS); err != nil {
+			fatal(err)
+		}
+		return
+	}
+	if isImmutable && config.Origin("prefix") == common.OriginDefault {
+		fmt.Fprintln(os.Stderr, cli.InfoStyle.Render(fmt.Sprintf("! %s is image based, installing into %s instead of %s/go; set --prefix to override.",
+			immutable.Name, common.GoRoot, common.DefaultPrefix)))
 	}
+	if inContainer {
+		fmt.Fprintln(os.Stderr, cli.InfoStyle.Render(fmt.Sprintf("! Running in a %s container: prompts are skipped and shell configuration is left alone; add %q to the image instead.",
+			runtimeName, common.ContainerEnvLine())))
+	}
+
+	requireRoot()
 
-	m := cli.NewPreInstallModel(*version)
-	p := tea.NewProgram(m)
-	if _, err := p.Run(); err != nil {
+	if *labels != "" {
+		if err := cli.ApplyLabels(strings.Split(*labels, ",")); err != nil {
+			fatal(err)
+		}
+	}
+	steps, err := cli.ParseSteps(*only, *skip)
+	if err != nil {
+		fatal(err)
+	}
+	if *noEnv {
+		*shellConfig = "skip"
+	}
+	switch *shellConfig {
+	case "auto", "symlink":
+	case "skip":
+		steps = slices.DeleteFunc(steps, func(s string) bool { return s == cli.StepConfigure })
+	default:
+		fatal(fmt.Errorf("invalid --shell-config %q (valid: auto, symlink, skip)", *shellConfig))
+	}
+	if !slices.Contains(cli.DepsPolicies, *deps) {
+		fatal(fmt.Errorf("invalid --deps %q (valid: %s)", *deps, strings.Join(cli.DepsPolicies, ", ")))
+	}
+	if !slices.Contains(cli.WorkspaceModes, *workspace) {
+		fatal(fmt.Errorf("invalid --workspace %q (valid: %s)", *workspace, strings.Join(cli.WorkspaceModes, ", ")))
+	}
+	if !slices.Contains(cli.PackagedGoModes, *packagedGo) {
+		fatal(fmt.Errorf("invalid --packaged-go %q (valid: %s)", *packagedGo, strings.Join(cli.PackagedGoModes, ", ")))
+	}
+	if *alternatives && !common.HasAlternatives() {
+		fatal(fmt.Errorf("--alternatives needs update-alternatives, found on Debian and Ubuntu"))
+	}
+	if !slices.Contains(cli.ToolchainPins, *pinToolchain) {
+		fatal(fmt.Errorf("invalid --pin-toolchain %q (valid: %s)", *pinToolchain, strings.Join(cli.ToolchainPins, ", ")))
+	}
+	var toolNames []string
+	for _, name := range strings.Split(*tools, ",") {
+		if name = strings.TrimSpace(name); name == "" || name == cli.ToolNone {
+			continue
+		}
+		if !slices.Contains(cli.DevToolNames(), name) {
+			fatal(fmt.Errorf("invalid --tools entry %q (valid: %s, %s)", name, strings.Join(cli.DevToolNames(), ", "), cli.ToolNone))
+		}
+		toolNames = append(toolNames, name)
+	}
+	var envVars map[string]string
+	if *envProfile != "" {
+		var ok bool
+		if envVars, ok = config.EnvProfile(*envProfile); !ok {
+			fatal(fmt.Errorf("invalid --env-profile %q (defined: %s)", *envProfile, strings.Join(config.EnvProfiles(), ", ")))
+		}
+	}
+	var depIDs []string
+	for _, id := range strings.Split(*dependencies, ",") {
+		if id = strings.TrimSpace(id); id == "" {
+			continue
+		}
+		if id != cli.DependencyNone && !slices.Contains(cli.DependencyIDs(), id) {
+			fatal(fmt.Errorf("invalid --dependencies entry %q (valid: %s, %s)", id, strings.Join(cli.DependencyIDs(), ", "), cli.DependencyNone))
+		}
+		depIDs = append(depIDs, id)
+	}
+
+	if !*jsonOut && !*plainOut && !cli.Interactive() {
+		*jsonOut = true
+	}
+	var sinks []cli.EventSink
+	var programOpts []tea.ProgramOption
+	if *eventsFD > 0 {
+		f := os.NewFile(uintptr(*eventsFD), "events")
+		if _, err := f.Stat(); err != nil {
+			fatal(fmt.Errorf("--events-fd %d: %w", *eventsFD, err))
+		}
+		defer f.Close()
+		sinks = append(sinks, cli.NewJSONSink(f))
+	}
+	if *jsonOut {
+		sinks = append(sinks, cli.NewJSONSink(os.Stdout))
+		programOpts = cli.Headless
+	} else if *plainOut {
+		sinks = append(sinks, cli.NewPlainSink(os.Stdout))
+		programOpts = cli.Headless
+	}
+	if *jsonOut || *plainOut {
+		// Keep-alive lines go to stderr, stdout may carry JSON.
+		sinks = append(sinks, cli.NewHeartbeatSink(os.Stderr, *heartbeat))
+	}
+
+	if dumbTerminal && !*yes && !*jsonOut && cli.Interactive() && !cli.ConfirmInstall(*version) {
+		fmt.Println("Aborted.")
+		exit(1)
+	}
+	m := cli.NewPreInstallModel(cli.Options{
+		Version:            *version,
+		Yes:                *yes || *jsonOut || *plainOut,
+		Force:              *force,
+		AllowSystemChanges: *allowSystemChanges,
+		Deps:               *deps,
+		NoCgoDeps:          *noCgoDeps,
+		Dependencies:       depIDs,
+		SkipDeps:           *skipDeps,
+		Workspace:          *workspace,
+		ToolchainPin:       *pinToolchain,
+		Alternatives:       *alternatives,
+		PackagedGo:         *packagedGo,
+		SymlinkBinaries:    *shellConfig == "symlink",
+		DiscardArchive:     !*keepArchive,
+		KeepBackups:        *keepBackups,
+		Steps:              steps,
+		PostInstallCmd:     *postInstallCmd,
+		Connections:        *connections,
+		Prerelease:         *prerelease,
+		AllowEOL:           *allowEOL,
+		AllReleases:        *all,
+		Sinks:              sinks,
+		JSON:               *jsonOut,
+		AltScreen:          !*noAltScreen && !*jsonOut && !*plainOut && cli.AltScreenSupported(),
+	})
+	p := cli.NewProgram(m, programOpts...)
+	final, err := p.Run()
+	cli.PublishResult(sinks, final, err)
+	if err != nil {
 		fmt.Println("Error:", err)
-		os.Exit(1)
+		printLogPath()
+		if crashReports {
+			cli.ReportCrash(err.Error())
+		}
+		exit(1)
+	}
+	if marked := cli.Marked(final); len(marked) > 0 {
+		if err := cli.RunMultiInstall(marked, *connections, *heartbeat); err != nil {
+			fmt.Println(cli.ErrorStyle.Render("✗ " + err.Error()))
+			printLogPath()
+			exit(common.ExitCodeFor(err))
+		}
+		exit(0)
+	}
+	code := cli.ExitCode(final)
+	if code != 0 {
+		printLogPath()
+	}
+	// Like the tools below the profile is applied to a working toolchain, a
+	// failure is reported without failing the install. It goes first so
+	// go install already uses its GOPROXY.
+	if code == 0 && cli.Installed(final) && envVars != nil {
+		if err := cli.RunEnvSetup(*envProfile, envVars); err != nil {
+			fmt.Println(cli.InfoStyle.Render("! " + err.Error()))
+			printLogPath()
+		}
+	}
+	// Tools are extras, failing ones are reported without failing the
+	// install.
+	askTools := *tools == "" && !*yes && !*jsonOut && !*plainOut
+	switch {
+	case code != 0 || !cli.Installed(final):
+	case *jsonOut || *plainOut:
+		// The TUI would corrupt the event stream, the sinks report the tools.
+		if err := cli.RunToolsHeadless(toolNames, sinks); err != nil && *plainOut {
+			fmt.Println("! " + err.Error())
+		}
+	case askTools || len(toolNames) > 0:
+		if err := cli.RunTools(toolNames, askTools); err != nil {
+			fmt.Println(cli.InfoStyle.Render("! " + err.Error()))
+			printLogPath()
+		}
+	}
+	exit(code)
+}
+
+func runCommand(name string, args []string, heartbeat time.Duration) {
+	switch name {
+	case "exec":
+		if len(args) > 1 && args[1] == "--" {
+			args = append(args[:1], args[2:]...)
+		}
+		if len(args) < 2 {
+			fmt.Println("usage: go-install exec VERSION -- COMMAND [ARGS...]")
+			exit(2)
+		}
+		code, err := cli.RunExec(args[0], args[1:], heartbeat)
+		if err != nil {
+			printError(err)
+		}
+		exit(code)
+	case "update":
+		fs := flag.NewFlagSet("update", flag.ExitOnError)
+		major := fs.Bool("major", false, "update to the newest stable release instead of the newest patch")
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		fs.Parse(args)
+		requireRoot()
+		if err := cli.RunUpdate(*major, *yes); err != nil {
+			fatal(err)
+		}
+	case "reinstall":
+		fs := flag.NewFlagSet("reinstall", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		fs.Parse(args)
+		requireRoot()
+		if err := cli.RunReinstall(*yes); err != nil {
+			fatal(err)
+		}
+	case "check":
+		fs := flag.NewFlagSet("check", flag.ExitOnError)
+		asJSON := fs.Bool("json", false, "print the result as JSON")
+		asMetrics := fs.Bool("metrics", false, "print Prometheus metrics for the node_exporter textfile collector")
+		fs.Parse(args)
+		outdated, err := cli.RunCheck(*asJSON, *asMetrics)
+		if err != nil {
+			fatal(err)
+		}
+		if outdated {
+			exit(common.ExitOutdated)
+		}
+	case "completion":
+		if len(args) > 0 && (args[0] == "install" || args[0] == "uninstall") {
+			requireRoot()
+		}
+		if err := cli.RunCompletion(args); err != nil {
+			fatal(err)
+		}
+	case "self-update":
+		fs := flag.NewFlagSet("self-update", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		fs.Parse(args)
+		if err := cli.RunSelfUpdate(*yes); err != nil {
+			fatal(err)
+		}
+	case "changelog":
+		if err := cli.RunChangelog(); err != nil {
+			fatal(err)
+		}
+	case "audit":
+		vulnerable, err := cli.RunAudit()
+		if err != nil {
+			fatal(err)
+		}
+		if vulnerable {
+			exit(common.ExitFailure)
+		}
+	case "uninstall":
+		fs := flag.NewFlagSet("uninstall", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		purge := fs.Bool("purge", false, "also remove kept toolchains, backups, cached archives and the state")
+		fs.Parse(args)
+		requireRoot()
+		if err := cli.RunUninstall(*yes, *purge, preRemoveCmd); err != nil {
+			fatal(err)
+		}
+	case "du":
+		if err := cli.RunDu(); err != nil {
+			fatal(err)
+		}
+	case "prune":
+		fs := flag.NewFlagSet("prune", flag.ExitOnError)
+		keep := fs.Int("keep", cli.DefaultPruneKeep, "number of newest toolchains and backups to keep")
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		protectFlag := fs.String("protect", protect, "comma separated versions prune never removes")
+		fs.Parse(args)
+		requireRoot()
+		var protected []string
+		if *protectFlag != "" {
+			protected = strings.Split(*protectFlag, ",")
+		}
+		if err := cli.RunPrune(*keep, protected, *yes); err != nil {
+			fatal(err)
+		}
+	case "rollback":
+		fs := flag.NewFlagSet("rollback", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		fs.Parse(args)
+		requireRoot()
+		if err := cli.RunRollback(*yes); err != nil {
+			fatal(err)
+		}
+	case "use":
+		fs := flag.NewFlagSet("use", flag.ExitOnError)
+		yes := fs.Bool("yes", false, "do not ask for confirmation")
+		rest := parseInterspersed(fs, args)
+		if len(rest) != 1 {
+			fmt.Println("usage: go-install use VERSION [--yes]")
+			exit(2)
+		}
+		requireRoot()
+		if err := cli.RunUse(rest[0], *yes, heartbeat); err != nil {
+			fatal(err)
+		}
+	case "cache":
+		fs := flag.NewFlagSet("cache", flag.ExitOnError)
+		maxSize := fs.String("max-size", "1GB", "shrink the cache to at most this size")
+		all := fs.Bool("all", false, "remove every cached archive")
+		rest := parseInterspersed(fs, args)
+		if len(rest) != 1 {
+			fmt.Println("usage: go-install cache list|clean [--max-size SIZE] [--all]")
+			exit(2)
+		}
+		size, err := common.ParseSize(*maxSize)
+		if err != nil {
+			fatal(err)
+		}
+		if err := cli.RunCache(rest[0], size, *all); err != nil {
+			fatal(err)
+		}
+	case "manifest":
+		fs := flag.NewFlagSet("manifest", flag.ExitOnError)
+		asJSON := fs.Bool("json", false, "print the manifest as JSON")
+		fs.Parse(args)
+		if err := cli.RunManifest(*asJSON); err != nil {
+			fatal(err)
+		}
+	case "config":
+		fs := flag.NewFlagSet("config", flag.ExitOnError)
+		origins := fs.Bool("origins", false, "show where each setting comes from")
+		rest := parseInterspersed(fs, args)
+		if len(rest) != 1 || (rest[0] != "show" && rest[0] != "doctor") {
+			fmt.Println("usage: go-install config show [--origins] | doctor")
+			exit(2)
+		}
+		if err := cli.RunConfig(config, *origins, rest[0] == "doctor"); err != nil {
+			fatal(err)
+		}
+	case "doctor":
+		if err := cli.RunDoctor(); err != nil {
+			fatal(err)
+		}
+	case "verify":
+		if len(args) > 1 {
+			fmt.Println("usage: go-install verify [VERSION]")
+			exit(2)
+		}
+		version := ""
+		if len(args) == 1 {
+			version = args[0]
+		}
+		if err := cli.RunVerify(version); err != nil {
+			fatal(err)
+		}
+	case "label":
+		fs := flag.NewFlagSet("label", flag.ExitOnError)
+		remove := fs.String("remove", "", "comma-separated label keys to remove")
+		set := parseInterspersed(fs, args)
+		var keys []string
+		if *remove != "" {
+			keys = strings.Split(*remove, ",")
+		}
+		if len(set) > 0 || len(keys) > 0 {
+			requireRoot()
+		}
+		if err := cli.RunLabel(set, keys); err != nil {
+			fatal(err)
+		}
+	case "ide":
+		if len(args) != 1 {
+			fmt.Println("usage: go-install ide vscode|goland")
+			exit(2)
+		}
+		if err := cli.RunIDE(args[0]); err != nil {
+			fatal(err)
+		}
+	case "mirror-compare":
+		fs := flag.NewFlagSet("mirror-compare", flag.ExitOnError)
+		mirror := fs.String("mirror", "", "base URL of the mirror serving release archives")
+		rest := parseInterspersed(fs, args)
+		if *mirror == "" || len(rest) > 1 {
+			fmt.Println("usage: go-install mirror-compare --mirror URL [VERSION]")
+			exit(2)
+		}
+		version := ""
+		if len(rest) == 1 {
+			version = rest[0]
+		}
+		if err := cli.RunMirrorCompare(*mirror, version); err != nil {
+			fatal(err)
+		}
+	case "fleet":
+		fs := flag.NewFlagSet("fleet", flag.ExitOnError)
+		hosts := fs.String("hosts", "", "file with one SSH destination (user@host) per line")
+		version := fs.String("version", "", "Go version to install, latest stable if empty")
+		keepArchive := fs.Bool("keep-archive", true, "keep the downloaded archives in the local cache")
+		rest := parseInterspersed(fs, args)
+		if *hosts == "" || len(rest) > 0 {
+			fmt.Println("usage: go-install fleet --hosts FILE [--version VERSION] [--keep-archive=false]")
+			exit(2)
+		}
+		if err := cli.RunFleet(*hosts, *version, *keepArchive); err != nil {
+			fatal(err)
+		}
+	case "batch":
+		if len(args) != 1 {
+			fmt.Println("usage: go-install batch MANIFEST")
+			exit(2)
+		}
+		requireRoot()
+		if err := cli.RunBatch(args[0], heartbeat); err != nil {
+			fatal(err)
+		}
+	case "platforms":
+		if len(args) > 1 {
+			fmt.Println("usage: go-install platforms [VERSION]")
+			exit(2)
+		}
+		version := ""
+		if len(args) == 1 {
+			version = args[0]
+		}
+		if err := cli.RunPlatforms(version); err != nil {
+			fatal(err)
+		}
+	case "env-setup":
+		if len(args) > 1 {
+			fmt.Println("usage: go-install env-setup [PROFILE]")
+			exit(2)
+		}
+		profile := flag.Lookup("env-profile").Value.String()
+		if len(args) == 1 {
+			profile = args[0]
+		}
+		if profile == "" {
+			fmt.Println("usage: go-install env-setup PROFILE (defined: " + strings.Join(config.EnvProfiles(), ", ") + ")")
+			exit(2)
+		}
+		vars, ok := config.EnvProfile(profile)
+		if !ok {
+			fatal(fmt.Errorf("unknown env profile %q (defined: %s)", profile, strings.Join(config.EnvProfiles(), ", ")))
+		}
+		if err := cli.RunEnvSetup(profile, vars); err != nil {
+			fatal(err)
+		}
+	case "support-bundle":
+		if err := cli.RunSupportBundle(); err != nil {
+			fatal(err)
+		}
+	default:
+		fmt.Println(cli.ErrorStyle.Render(fmt.Sprintf("✗ Error: unknown command %q", name)))
+		exit(2)
+	}
+}
+
+// parseInterspersed parses fs allowing flags after positional arguments and
+// returns the positional ones.
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		if args[0] == "--" {
+			return append(positional, args[1:]...)
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// requireRoot exits unless running as root, in Termux as the app user or
+// for a prefix in the invoking user's home, and takes the global lock, since
+// every command needing root modifies the system.
+func requireRoot() {
+	if os.Geteuid() != 0 && common.NeedsRoot() {
+		fmt.Println(cli.ErrorStyle.Render(fmt.Sprintf("\n✗ Error: This tool requires root privileges. Please run with %s.\n", common.Elevator())))
+		exit(common.ExitPermission)
+	}
+	release, err := common.AcquireLock()
+	if err != nil {
+		if errors.Is(err, common.ErrLocked) {
+			fmt.Println(cli.ErrorStyle.Render(fmt.Sprintf("\n✗ %v. Wait for it to finish and try again.\n", err)))
+		} else {
+			printError(fmt.Errorf("could not take lock %s: %w", common.LockPath, err))
+		}
+		exit(common.ExitCodeFor(err))
+	}
+	releaseLock = release
+}
+
+// releaseLock releases the lock taken by requireRoot. Holding the release
+// func also keeps the lock file open, an unreferenced file would be closed
+// by its finalizer and drop the lock halfway through.
+var releaseLock = func() {}
+
+// exit releases the lock and ends the process with code.
+func exit(code int) {
+	releaseLock()
+	os.Exit(code)
+}
+
+// applySettings points the common package at the configured locations.
+func applySettings(prefix, mirror, proxy string) error {
+	if err := common.SetPrefix(prefix); err != nil {
+		return err
+	}
+	if mirror != "" {
+		if err := common.SetMirror(mirror); err != nil {
+			return err
+		}
+	}
+	if proxy != "" {
+		return common.SetProxy(proxy)
+	}
+	return nil
+}
+
+// warnConfig reports configuration problems without failing, the affected
+// settings keep their defaults.
+func warnConfig() {
+	for _, p := range config.Problems {
+		fmt.Fprintln(os.Stderr, cli.InfoStyle.Render("! config: "+p))
+	}
+}
+
+func printError(err error) {
+	common.Log.Error("exit", "err", err)
+	fmt.Println(cli.ErrorStyle.Render(fmt.Sprintf("✗ Error: %v", err)))
+	printLogPath()
+}
+
+// printLogPath points at the log so it can be attached to a bug report.
+func printLogPath() {
+	if common.LogPath != "" {
+		fmt.Fprintln(os.Stderr, cli.InfoStyle.Render("Details are in the log: "+common.LogPath))
+	}
+}
+
+func fatal(err error) {
+	printError(err)
+	if crashReports {
+		cli.ReportCrash(err.Error())
+	}
+	exit(common.ExitCodeFor(err))
+}
+
+// reportPanic turns a panic into a support bundle instead of a bare stack
+// trace on the terminal.
+func reportPanic() {
+	if r := recover(); r != nil {
+		fmt.Fprintf(os.Stderr, "panic: %v\n", r)
+		cli.ReportCrash(fmt.Sprintf("panic: %v\n\n%s", r, debug.Stack()))
+		exit(2)
 	}
 }
@@ -0,0 +1,126 @@
+//go:build minimal
+
+// The minimal build installs Go without the interactive UI and without
+// touching system packages, for platforms where the full build is not
+// supported, such as illumos, Solaris and AIX:
+//
+//	go build -tags minimal
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-installer/common"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+func main() {
+	version := flag.String("version", "", "Go version to install, latest stable if empty")
+	keepBackups := flag.Int("keep-backups", 3, "number of replaced installations kept for rollback")
+	noEnv := flag.Bool("no-env", false, "do not touch shell configuration files, print the PATH line instead")
+	flag.Parse()
+
+	if termuxPrefix, ok := common.TermuxPrefix(); ok {
+		common.SetStateRoot(termuxPrefix)
+		if err := common.SetPrefix(termuxPrefix); err != nil {
+			fatal(err)
+		}
+	}
+	if os.Geteuid() != 0 && common.NeedsRoot() {
+		fatal(fmt.Errorf("%w, please run with %s", common.ErrNotRoot, common.Elevator()))
+	}
+	release, err := common.AcquireLock()
+	if err != nil {
+		fatal(err)
+	}
+	defer release()
+
+	if err := install(common.NormalizeVersion(*version), *keepBackups, *noEnv); err != nil {
+		fatal(err)
+	}
+}
+
+func install(version string, keepBackups int, noEnv bool) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Fetching Go releases metadata...")
+	releases, err := common.FetchReleases(version != "")
+	if err != nil {
+		return err
+	}
+	if version == "" {
+		latest, err := common.LatestStable(releases)
+		if err != nil {
+			return err
+		}
+		version = latest.Version
+	}
+	rel, file, sha, err := common.FindBuild(releases, version, common.GetOS(), common.GetArch())
+	if err != nil {
+		return err
+	}
+	f, _ := rel.File(file)
+	if err := common.Preflight(
+		common.Requirement{Dir: common.ArchiveCacheDir(), Bytes: f.Size},
+		common.Requirement{Dir: common.InstallPrefix, Bytes: f.Size * common.ExtractedSizeFactor, Exec: true},
+	); err != nil {
+		return err
+	}
+
+	fmt.Printf("Downloading, verifying and extracting %s...\n", file)
+	tmp, err := os.MkdirTemp(common.InstallPrefix, ".go-install-tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+	if _, err := common.StreamArchive(ctx, file, sha, tmp, nil); err != nil {
+		return err
+	}
+
+	fmt.Println("Replacing old installation...")
+	backup, err := common.BackupGoRoot()
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(tmp, "go"), common.GoRoot); err != nil {
+		if backup != "" {
+			os.Rename(filepath.Join(backup, "go"), common.GoRoot)
+			os.Remove(backup)
+		}
+		return err
+	}
+	common.RecordFile(common.GoRoot, common.KindGoRoot, "created")
+	common.WriteTreeManifest(common.GoRoot, version)
+	common.RecordFile(common.ArchiveCacheDir(), common.KindCache, "created")
+	common.PruneBackups(keepBackups)
+	common.GCArchiveCache(common.DefaultCacheMaxSize)
+
+	fmt.Printf("✓ Installed %s to %s\n", version, common.GoRoot)
+
+	if noEnv {
+		fmt.Printf("Shell configuration left untouched, put Go on PATH with:\n  %s\n", common.ExportLine())
+		return nil
+	}
+	// The toolchain is usable at this point, a shell configuration that
+	// cannot be updated is only reported.
+	bin := filepath.Join(common.GoRoot, "bin")
+	configured, _, err := common.SetupEnvironment()
+	switch {
+	case err != nil:
+		fmt.Printf("! Shell configuration skipped: %v. Add %s to PATH yourself.\n", err, bin)
+	case configured.File != "":
+		common.RecordShellChange(configured)
+		fmt.Printf("Added %s to PATH in %s, restart your shell to apply it.\n", bin, configured.File)
+	}
+	return nil
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "✗ Error: %v\n", err)
+	os.Exit(common.ExitCodeFor(err))
+}
@@ -0,0 +1,108 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"go-installer/common"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// minChunkSize keeps small files on a single connection.
+const minChunkSize = 1 << 20
+
+// downloadChunked fetches url into dst using n parallel ranged requests. It
+// falls back to a single connection when the server does not support ranges
+// or does not report a size. progress, when set, must be safe for
+// concurrent writes.
+func downloadChunked(ctx context.Context, url, dst string, n int, progress io.Writer) (err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	size := resp.ContentLength
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Accept-Ranges") != "bytes" || size < minChunkSize*2 {
+		return common.DownloadURL(ctx, url, dst, progress)
+	}
+	if max := int(size / minChunkSize); n > max {
+		n = max
+	}
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	if err := out.Truncate(size); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	chunk := size / int64(n)
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		start := int64(i) * chunk
+		end := start + chunk - 1
+		if i == n-1 {
+			end = size - 1
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := downloadRange(ctx, url, out, start, end, progress); err != nil {
+				cancel()
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	if err := <-errs; err != nil {
+		return err
+	}
+	return out.Sync()
+}
+
+func downloadRange(ctx context.Context, url string, out *os.File, start, end int64, progress io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return fmt.Errorf("range request %d-%d: %s", start, end, resp.Status)
+	}
+
+	var w io.Writer = io.NewOffsetWriter(out, start)
+	if progress != nil {
+		w = io.MultiWriter(w, progress)
+	}
+	n, err := io.Copy(w, resp.Body)
+	if err != nil {
+		return err
+	}
+	if n != end-start+1 {
+		return fmt.Errorf("range request %d-%d: short read of %d bytes", start, end, n)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+// Package installer downloads, verifies and installs official Go
+// toolchains without a user interface, for provisioners, IDE plugins and
+// other tools that embed go-install. The go-install TUI runs the same
+// steps.
+//
+// The install locations are process wide, see common.SetPrefix, so only
+// one Install should run at a time. Options.Prefix applies to a single
+// call. Install takes the go-install lock unless the process holds it
+// already.
+package installer
+
+import (
+	"context"
+	"fmt"
+	"go-installer/common"
+	"io"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// Status is the stage of a step an Event reports.
+type Status string
+
+const (
+	StatusStarted  Status = "started"
+	StatusProgress Status = "progress"
+	StatusDone     Status = "done"
+)
+
+// Event reports the progress of Install. Progress events are sent from the
+// goroutines doing the transfer, so callbacks must be safe for concurrent
+// use.
+type Event struct {
+	Step   string
+	Status Status
+	// Bytes and Total describe the progress of the download and extract
+	// steps, Total is zero when unknown.
+	Bytes int64
+	Total int64
+}
+
+// DefaultKeepBackups is how many replaced toolchains Install keeps when
+// Options.KeepBackups is zero.
+const DefaultKeepBackups = 3
+
+// NoBackups as Options.KeepBackups removes every backup after installing.
+const NoBackups = -1
+
+// Options configure Install. The zero value installs the latest stable
+// release for this platform into the configured prefix.
+type Options struct {
+	// Version to install, e.g. "1.22.1" or "go1.22.1". Empty installs the
+	// latest stable release.
+	Version string
+	// Prefix installs into Prefix/go instead of GoRoot for this call.
+	Prefix string
+	// Dir, when set, installs side by side into Dir/go, replacing only
+	// what is there. GoRoot, its backups and the shell configuration are
+	// left alone.
+	Dir string
+	// OS and Arch select the archive, empty for this host.
+	OS, Arch string
+	// Connections greater than one download the archive in parallel
+	// chunks instead of a single stream.
+	Connections int
+	// KeepBackups is how many replaced toolchains are kept for rollback,
+	// DefaultKeepBackups when zero and none with NoBackups.
+	KeepBackups int
+	// ConfigureShell puts GoRoot on the PATH of the invoking user.
+	ConfigureShell bool
+	// Progress, when set, receives an event for every step and transfer.
+	Progress func(Event)
+}
+
+// Result describes a finished installation.
+type Result struct {
+	Version string
+	GoRoot  string
+	// Backup is the directory the replaced toolchain was moved to, empty
+	// for a new installation.
+	Backup string
+	// ShellConfig is the file PATH was added to, empty when none was
+	// changed.
+	ShellConfig string
+}
+
+// progressWriter turns the bytes written to it into progress events.
+type progressWriter struct {
+	step     string
+	total    int64
+	bytes    atomic.Int64
+	progress func(Event)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n := w.bytes.Add(int64(len(p)))
+	w.progress(Event{Step: w.step, Status: StatusProgress, Bytes: n, Total: w.total})
+	return len(p), nil
+}
+
+// Install installs a Go toolchain into GoRoot: it downloads and verifies
+// the archive, extracts it, replaces the current toolchain, runs the new
+// go command and restores the previous toolchain when that fails. With
+// Options.Dir set it installs side by side instead.
+func Install(ctx context.Context, opts Options) (Result, error) {
+	report := func(step string, status Status) {
+		if opts.Progress != nil {
+			opts.Progress(Event{Step: step, Status: status})
+		}
+	}
+	writer := func(step string, total int64) io.Writer {
+		if opts.Progress == nil {
+			return nil
+		}
+		return &progressWriter{step: step, total: total, progress: opts.Progress}
+	}
+
+	if opts.Prefix != "" {
+		prev := common.InstallPrefix
+		if err := common.SetPrefix(opts.Prefix); err != nil {
+			return Result{}, err
+		}
+		defer common.SetPrefix(prev)
+	}
+	if opts.OS == "" {
+		opts.OS = common.GetOS()
+	}
+	if opts.Arch == "" {
+		opts.Arch = common.GetArch()
+	}
+	releases, err := common.FetchReleases(true)
+	if err != nil {
+		return Result{}, err
+	}
+	version := common.NormalizeVersion(opts.Version)
+	if version == "" {
+		latest, err := common.LatestStable(releases)
+		if err != nil {
+			return Result{}, err
+		}
+		version = latest.Version
+	}
+	release, file, sha, err := common.FindBuild(releases, version, opts.OS, opts.Arch)
+	if err != nil {
+		return Result{}, err
+	}
+	f, _ := release.File(file)
+	parent := common.InstallPrefix
+	if opts.Dir != "" {
+		parent = opts.Dir
+		if err := os.MkdirAll(parent, 0755); err != nil {
+			return Result{}, err
+		}
+	}
+	if err := common.Preflight(
+		common.Requirement{Dir: common.ArchiveCacheDir(), Bytes: f.Size},
+		common.Requirement{Dir: parent, Bytes: f.Size * common.ExtractedSizeFactor, Exec: true},
+	); err != nil {
+		return Result{}, err
+	}
+	// go-install itself holds the lock for the whole run already.
+	if !common.LockHeld() {
+		unlock, err := common.AcquireLock()
+		if err != nil {
+			return Result{}, err
+		}
+		defer unlock()
+	}
+
+	var dir string
+	report(StepDownload, StatusStarted)
+	if opts.Connections <= 1 {
+		if _, dir, err = Stream(ctx, file, sha, parent, writer(StepDownload, f.Size)); err != nil {
+			return Result{}, err
+		}
+		report(StepDownload, StatusDone)
+	} else {
+		archive, err := FetchArchive(ctx, file, sha, writer(StepDownload, f.Size), opts.Connections)
+		if err != nil {
+			return Result{}, err
+		}
+		report(StepDownload, StatusDone)
+		report(StepVerify, StatusStarted)
+		if err := Verify(archive, sha); err != nil {
+			return Result{}, err
+		}
+		report(StepVerify, StatusDone)
+		report(StepExtract, StatusStarted)
+		size, _ := common.UncompressedSize(archive)
+		if dir, err = Extract(ctx, archive, parent, writer(StepExtract, size)); err != nil {
+			return Result{}, err
+		}
+		report(StepExtract, StatusDone)
+	}
+	if opts.Dir != "" {
+		return installInto(ctx, dir, version, opts.Dir, report)
+	}
+
+	report(StepReplace, StatusStarted)
+	backup, err := Replace(dir, version)
+	if err != nil {
+		return Result{}, err
+	}
+	report(StepReplace, StatusDone)
+	report(StepSmokeTest, StatusStarted)
+	if err := SmokeTest(ctx, common.GoRoot, version); err != nil {
+		if rerr := Restore(backup); rerr != nil {
+			return Result{}, fmt.Errorf("%w; restoring the previous toolchain failed: %v", err, rerr)
+		}
+		return Result{}, err
+	}
+	report(StepSmokeTest, StatusDone)
+	common.RecordFile(common.GoRoot, common.KindGoRoot, "created")
+	common.RecordFile(common.ArchiveCacheDir(), common.KindCache, "created")
+	keep := opts.KeepBackups
+	switch {
+	case keep == 0:
+		keep = DefaultKeepBackups
+	case keep < 0:
+		keep, backup = 0, ""
+	}
+	if err := common.PruneBackups(keep); err != nil {
+		return Result{}, err
+	}
+	result := Result{Version: version, GoRoot: common.GoRoot, Backup: backup}
+
+	if opts.ConfigureShell {
+		report(StepConfigure, StatusStarted)
+		change, err := Configure()
+		if err != nil {
+			return result, err
+		}
+		result.ShellConfig = change.File
+		report(StepConfigure, StatusDone)
+	}
+	common.GCArchiveCache(common.DefaultCacheMaxSize)
+	return result, nil
+}
+
+// installInto moves the toolchain extracted into dir to target/go and
+// removes dir.
+func installInto(ctx context.Context, dir, version, target string, report func(string, Status)) (Result, error) {
+	defer os.RemoveAll(dir)
+	report(StepReplace, StatusStarted)
+	dst := filepath.Join(target, "go")
+	if err := os.RemoveAll(dst); err != nil {
+		return Result{}, err
+	}
+	if err := os.Rename(filepath.Join(dir, "go"), dst); err != nil {
+		return Result{}, err
+	}
+	report(StepReplace, StatusDone)
+	report(StepSmokeTest, StatusStarted)
+	if err := SmokeTest(ctx, dst, version); err != nil {
+		os.RemoveAll(dst)
+		return Result{}, err
+	}
+	report(StepSmokeTest, StatusDone)
+	common.RecordFile(dst, common.KindToolchain, "created")
+	common.WriteTreeManifest(dst, version)
+	common.GCArchiveCache(common.DefaultCacheMaxSize)
+	return Result{Version: version, GoRoot: dst}, nil
+}
@@ -0,0 +1,179 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-installer/common"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Names of the install steps, in execution order.
+const (
+	StepDownload  = "download"
+	StepVerify    = "verify"
+	StepExtract   = "extract"
+	StepReplace   = "replace"
+	StepSmokeTest = "smoke-test"
+	StepConfigure = "configure"
+)
+
+// smokeTestTimeout bounds the smoke test, a first run can be slow on
+// emulated or network storage.
+const smokeTestTimeout = 30 * time.Second
+
+// FetchArchive returns the path of a verified archive in the cache,
+// downloading it only when there is no valid cached copy. More than one
+// connection downloads the archive in parallel chunks.
+func FetchArchive(ctx context.Context, file, sha string, progress io.Writer, connections int) (string, error) {
+	path := common.CachedArchivePath(file, sha)
+	if _, err := os.Stat(path); err == nil {
+		if common.VerifyChecksum(path, sha) == nil {
+			// Refresh the mtime so garbage collection treats it as recent.
+			now := time.Now()
+			os.Chtimes(path, now, now)
+			return path, nil
+		}
+		os.Remove(path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	part := path + ".part"
+	var err error
+	if connections > 1 {
+		err = downloadChunked(ctx, common.DownloadBase+file, part, connections, progress)
+	} else {
+		err = common.DownloadFile(ctx, file, part, progress)
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(part, path); err != nil {
+		os.Remove(part)
+		return "", err
+	}
+	return path, nil
+}
+
+// Stream downloads, verifies and extracts the archive file as one step
+// into a new directory below parent, and returns the cached archive and
+// that directory. Nothing is extracted before the checksum matches.
+func Stream(ctx context.Context, file, sha, parent string, progress io.Writer) (archive, dir string, err error) {
+	dir, err = os.MkdirTemp(parent, ".go-install-tmp-")
+	if err != nil {
+		return "", "", err
+	}
+	archive, err = common.StreamArchive(ctx, file, sha, dir, progress)
+	if err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return archive, dir, nil
+}
+
+// Verify checks archive against its SHA-256 and removes it when it does
+// not match, so a corrupt archive never stays in the cache.
+func Verify(archive, sha string) error {
+	if err := common.VerifyChecksum(archive, sha); err != nil {
+		os.Remove(archive)
+		return err
+	}
+	return nil
+}
+
+// Extract unpacks archive into a new directory below parent and returns
+// it. Extracting next to the final location makes the swap in Replace a
+// rename on the same filesystem, and a failed extraction leaves the current
+// toolchain untouched.
+func Extract(ctx context.Context, archive, parent string, progress io.Writer) (string, error) {
+	dir, err := os.MkdirTemp(parent, ".go-install-tmp-")
+	if err != nil {
+		return "", err
+	}
+	if err := common.ExtractTarGz(ctx, archive, dir, progress); err != nil {
+		os.RemoveAll(dir)
+		return "", err
+	}
+	return dir, nil
+}
+
+// Replace moves the toolchain extracted into dir to GoRoot, keeping the
+// current one as a backup, and removes dir. It returns the backup, empty
+// when there was no toolchain to replace.
+func Replace(dir, version string) (string, error) {
+	defer os.RemoveAll(dir)
+
+	backup, err := common.BackupGoRoot()
+	if err != nil {
+		return "", err
+	}
+	if err := os.Rename(filepath.Join(dir, "go"), common.GoRoot); err != nil {
+		if backup != "" {
+			os.Rename(filepath.Join(backup, "go"), common.GoRoot)
+			os.Remove(backup)
+		}
+		return "", err
+	}
+	// Hash the fresh tree for 'go-install verify'. Without a manifest
+	// only verification is unavailable, so errors do not fail the step.
+	common.WriteTreeManifest(common.GoRoot, version)
+	return backup, nil
+}
+
+// Restore undoes Replace: it removes the new toolchain and moves backup,
+// if any, back to GoRoot.
+func Restore(backup string) error {
+	if err := os.RemoveAll(common.GoRoot); err != nil {
+		return err
+	}
+	if backup == "" {
+		return nil
+	}
+	if err := os.Rename(filepath.Join(backup, "go"), common.GoRoot); err != nil {
+		return err
+	}
+	return os.Remove(backup)
+}
+
+// SmokeTest runs the go command of the toolchain at goroot and checks that
+// it reports version, so a toolchain that does not work on this host is
+// caught before anything points at it.
+func SmokeTest(ctx context.Context, goroot, version string) error {
+	ctx, cancel := context.WithTimeout(ctx, smokeTestTimeout)
+	defer cancel()
+	cmd := common.CommandContext(ctx, filepath.Join(goroot, "bin", "go"), "version")
+	cmd.Env = append(os.Environ(), "GOROOT="+goroot, "GOTOOLCHAIN=local")
+	out, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("go version: %w", err)
+	}
+	// go version go1.22.1 linux/amd64
+	if fields := strings.Fields(string(out)); len(fields) < 3 || fields[2] != version {
+		return fmt.Errorf("go version reports %q, expected %s", strings.TrimSpace(string(out)), version)
+	}
+	return nil
+}
+
+// Configure puts GoRoot on the PATH of the invoking user by appending to
+// their shell configuration and returns the change made, empty when PATH
+// is set up already or the user has no home directory.
+func Configure() (common.ShellChange, error) {
+	change, err := common.PlanShellConfig()
+	if errors.Is(err, common.ErrNoHome) {
+		return common.ShellChange{}, nil
+	}
+	if err != nil || change.File == "" {
+		return common.ShellChange{}, err
+	}
+	if _, err := common.ApplyShellChange(change); err != nil {
+		return common.ShellChange{}, err
+	}
+	common.RecordShellChange(change)
+	return change, nil
+}